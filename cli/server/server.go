@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
@@ -62,12 +63,36 @@ func NewCommands() []cli.Command {
 			Usage: "Input file (stdin if not given)",
 		},
 	)
+	var cfgNodeFlags = make([]cli.Flag, len(cfgFlags))
+	copy(cfgNodeFlags, cfgFlags)
+	cfgNodeFlags = append(cfgNodeFlags,
+		cli.StringFlag{
+			Name:  "snapshot",
+			Usage: "chain state snapshot file to initialize empty DB with",
+		},
+	)
+	var cfgSnapshotFlags = make([]cli.Flag, len(cfgFlags))
+	copy(cfgSnapshotFlags, cfgFlags)
+	cfgSnapshotFlags = append(cfgSnapshotFlags,
+		cli.StringFlag{
+			Name:  "out, o",
+			Usage: "Output file (stdout if not given)",
+		},
+	)
+	var cfgRollbackFlags = make([]cli.Flag, len(cfgFlags))
+	copy(cfgRollbackFlags, cfgFlags)
+	cfgRollbackFlags = append(cfgRollbackFlags,
+		cli.UintFlag{
+			Name:  "height",
+			Usage: "height of the block to roll back to",
+		},
+	)
 	return []cli.Command{
 		{
 			Name:   "node",
 			Usage:  "start a NEO node",
 			Action: startServer,
-			Flags:  cfgFlags,
+			Flags:  cfgNodeFlags,
 		},
 		{
 			Name:  "db",
@@ -85,6 +110,18 @@ func NewCommands() []cli.Command {
 					Action: restoreDB,
 					Flags:  cfgCountInFlags,
 				},
+				{
+					Name:   "snapshot",
+					Usage:  "export chain state at the current height to the file",
+					Action: snapshotDB,
+					Flags:  cfgSnapshotFlags,
+				},
+				{
+					Name:   "rollback",
+					Usage:  "remove all blocks above the given height reverting the chain state",
+					Action: rollbackDB,
+					Flags:  cfgRollbackFlags,
+				},
 			},
 		},
 	}
@@ -148,8 +185,8 @@ func handleLoggingParams(ctx *cli.Context, cfg config.ApplicationConfiguration)
 	return cc.Build()
 }
 
-func initBCWithMetrics(cfg config.Config, log *zap.Logger) (*core.Blockchain, *metrics.Service, *metrics.Service, error) {
-	chain, err := initBlockChain(cfg, log)
+func initBCWithMetrics(cfg config.Config, log *zap.Logger, snapshot string) (*core.Blockchain, *metrics.Service, *metrics.Service, error) {
+	chain, err := initBlockChain(cfg, log, snapshot)
 	if err != nil {
 		return nil, nil, nil, cli.NewExitError(err, 1)
 	}
@@ -186,7 +223,7 @@ func dumpDB(ctx *cli.Context) error {
 	defer outStream.Close()
 	writer := io.NewBinWriterFromIO(outStream)
 
-	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
+	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log, "")
 	if err != nil {
 		return err
 	}
@@ -242,7 +279,7 @@ func restoreDB(ctx *cli.Context) error {
 	defer inStream.Close()
 	reader := io.NewBinReaderFromIO(inStream)
 
-	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
+	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log, "")
 	if err != nil {
 		return err
 	}
@@ -290,6 +327,75 @@ func restoreDB(ctx *cli.Context) error {
 	return nil
 }
 
+func snapshotDB(ctx *cli.Context) error {
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	log, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var outStream = os.Stdout
+	if out := ctx.String("out"); out != "" {
+		outStream, err = os.Create(out)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	defer outStream.Close()
+	writer := bufio.NewWriter(outStream)
+
+	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log, "")
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+	defer prometheus.ShutDown()
+	defer pprof.ShutDown()
+
+	hdr, err := chain.ExportSnapshot(writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to export snapshot: %s", err), 1)
+	}
+	log.Info("exported chain state snapshot",
+		zap.Uint32("height", hdr.Index),
+		zap.String("stateroot", hdr.StateRoot.StringLE()))
+	return nil
+}
+
+func rollbackDB(ctx *cli.Context) error {
+	if !ctx.IsSet("height") {
+		return cli.NewExitError("height is not specified", 1)
+	}
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	log, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	height := uint32(ctx.Uint("height"))
+
+	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log, "")
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+	defer prometheus.ShutDown()
+	defer pprof.ShutDown()
+
+	if err := chain.Rollback(height); err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to roll back to block %d: %s", height, err), 1)
+	}
+	return nil
+}
+
 // readBlock performs reading of block size and then bytes with the length equal to that size.
 func readBlock(reader *io.BinReader) ([]byte, error) {
 	var size = reader.ReadU32LE()
@@ -316,7 +422,7 @@ func startServer(ctx *cli.Context) error {
 
 	serverConfig := network.NewServerConfig(cfg)
 
-	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
+	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log, ctx.String("snapshot"))
 	if err != nil {
 		return err
 	}
@@ -380,12 +486,20 @@ func configureAddresses(cfg config.ApplicationConfiguration) {
 	}
 }
 
-// initBlockChain initializes BlockChain with preselected DB.
-func initBlockChain(cfg config.Config, log *zap.Logger) (*core.Blockchain, error) {
+// initBlockChain initializes BlockChain with preselected DB. If the snapshot
+// file is given and the DB is empty, the DB is initialized with the chain state
+// from this snapshot.
+func initBlockChain(cfg config.Config, log *zap.Logger, snapshot string) (*core.Blockchain, error) {
 	store, err := storage.NewStore(cfg.ApplicationConfiguration.DBConfiguration)
 	if err != nil {
 		return nil, cli.NewExitError(fmt.Errorf("could not initialize storage: %s", err), 1)
 	}
+	if snapshot != "" {
+		if err := importSnapshot(store, cfg.ProtocolConfiguration, snapshot, log); err != nil {
+			store.Close()
+			return nil, cli.NewExitError(fmt.Errorf("could not import snapshot: %s", err), 1)
+		}
+	}
 
 	chain, err := core.NewBlockchain(store, cfg.ProtocolConfiguration, log)
 	if err != nil {
@@ -397,6 +511,30 @@ func initBlockChain(cfg config.Config, log *zap.Logger) (*core.Blockchain, error
 	return chain, nil
 }
 
+// importSnapshot loads the chain state snapshot from the given file into the
+// store, it does nothing if the store already has some chain in it. Imported
+// data is removed from the store if the import fails.
+func importSnapshot(store storage.Store, cfg config.ProtocolConfiguration, snapshot string, log *zap.Logger) error {
+	f, err := os.Open(snapshot)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hdr, err := core.ImportSnapshot(store, cfg, bufio.NewReader(f))
+	if err == core.ErrStoreNotEmpty {
+		log.Info("DB is not empty, snapshot is ignored")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("imported chain state snapshot",
+		zap.Uint32("height", hdr.Index),
+		zap.String("stateroot", hdr.StateRoot.StringLE()))
+	return nil
+}
+
 func logo() string {
 	return `
     _   ____________        __________
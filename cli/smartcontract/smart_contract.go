@@ -44,6 +44,10 @@ var (
 		Name:  "gas, g",
 		Usage: "gas to pay for transaction",
 	}
+	traceFlag = cli.BoolFlag{
+		Name:  "trace, t",
+		Usage: "output execution trace of the script (for debugging)",
+	}
 )
 
 const (
@@ -151,6 +155,7 @@ func NewCommands() []cli.Command {
 				Action: testInvoke,
 				Flags: []cli.Flag{
 					endpointFlag,
+					traceFlag,
 				},
 			},
 			{
@@ -222,6 +227,7 @@ func NewCommands() []cli.Command {
 				Action: testInvokeFunction,
 				Flags: []cli.Flag{
 					endpointFlag,
+					traceFlag,
 				},
 			},
 			{
@@ -230,6 +236,7 @@ func NewCommands() []cli.Command {
 				Action: testInvokeScript,
 				Flags: []cli.Flag{
 					endpointFlag,
+					traceFlag,
 					cli.StringFlag{
 						Name:  "in, i",
 						Usage: "Input location of the avm file that needs to be invoked",
@@ -403,9 +410,15 @@ func invokeInternal(ctx *cli.Context, withMethod bool, signAndPush bool) error {
 		return cli.NewExitError(err, 1)
 	}
 
-	if withMethod {
+	trace := !signAndPush && ctx.Bool("trace")
+	switch {
+	case withMethod && trace:
+		resp, err = client.TraceInvokeFunction(script, operation, params)
+	case withMethod:
 		resp, err = client.InvokeFunction(script, operation, params)
-	} else {
+	case trace:
+		resp, err = client.TraceInvoke(script, params)
+	default:
 		resp, err = client.Invoke(script, params)
 	}
 	if err != nil {
@@ -457,7 +470,12 @@ func testInvokeScript(ctx *cli.Context) error {
 	}
 
 	scriptHex := hex.EncodeToString(b)
-	resp, err := client.InvokeScript(scriptHex)
+	var resp *rpc.InvokeScriptResponse
+	if ctx.Bool("trace") {
+		resp, err = client.TraceInvokeScript(scriptHex)
+	} else {
+		resp, err = client.InvokeScript(scriptHex)
+	}
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
@@ -57,6 +57,29 @@ type (
 		VerifyTransactions bool `yaml:"VerifyTransactions"`
 		// FreeGasLimit is an amount of GAS which can be spent for free.
 		FreeGasLimit util.Fixed8 `yaml:"FreeGasLimit"`
+		// Whether to keep historical state (accounts, unspent coins,
+		// contracts and storage items) for every block, it can only be
+		// enabled for a new DB.
+		ArchivalMode bool `yaml:"ArchivalMode"`
+		// Whether to remove bodies of old blocks along with transactions
+		// and coin states that are not needed anymore, it can't be used
+		// with ArchivalMode.
+		PruningMode bool `yaml:"PruningMode"`
+		// PruneDepth is the number of the latest blocks kept intact in
+		// PruningMode.
+		PruneDepth uint32 `yaml:"PruneDepth"`
+		// Whether to calculate state roots for every block. Trie nodes
+		// of all previous states are kept in the DB, so it noticeably
+		// increases its size.
+		StateRootEnabled bool `yaml:"StateRootEnabled"`
+		// MaxRollbackDepth is the number of the latest blocks undo data
+		// is kept for, so that they can be reverted. Undo data contains
+		// previous values of all the keys changed by the block, so it
+		// noticeably increases the DB size. It's not saved if zero. It
+		// can't be greater than PruneDepth in PruningMode. Transaction
+		// tracing requires it to be greater than zero and only works
+		// for transactions of this number of the latest blocks.
+		MaxRollbackDepth uint32 `yaml:"MaxRollbackDepth"`
 	}
 
 	// SystemFee fees related to system.
@@ -102,6 +125,68 @@ type (
 		// MaxGasInvoke is a maximum amount of gas which
 		// can be spent during RPC call.
 		MaxGasInvoke util.Fixed8 `yaml:"MaxGasInvoke"`
+		// MaxBatchSize is a maximum number of requests in a single
+		// JSON-RPC batch.
+		MaxBatchSize int           `yaml:"MaxBatchSize"`
+		TLSConfig    RPCTLSConfig  `yaml:"TLSConfig"`
+		Auth         RPCAuthConfig `yaml:"Auth"`
+		// MaxRequestBodySize is a maximum size (in bytes) of a single
+		// HTTP request body or websocket message.
+		MaxRequestBodySize int `yaml:"MaxRequestBodySize"`
+		// MaxConcurrentInvocations limits the number of VM-backed
+		// calls (invoke, invokefunction, invokescript, tracetransaction)
+		// processed simultaneously, 0 means no limit. Note that
+		// tracetransaction also requires non-zero MaxRollbackDepth in
+		// the protocol configuration.
+		MaxConcurrentInvocations int                `yaml:"MaxConcurrentInvocations"`
+		RateLimit                RPCRateLimitConfig `yaml:"RateLimit"`
+		// EnabledMethods is a list of methods served, all methods are
+		// enabled if it's empty.
+		EnabledMethods []string `yaml:"EnabledMethods"`
+		// DisabledMethods is a list of methods that are not served
+		// even if they're mentioned in EnabledMethods.
+		DisabledMethods []string `yaml:"DisabledMethods"`
+	}
+
+	// RPCRateLimitConfig describes per-IP rate limiting settings of the
+	// RPC server. Rate limiting is disabled if RequestsPerSecond is 0.
+	RPCRateLimitConfig struct {
+		RequestsPerSecond int `yaml:"RequestsPerSecond"`
+		// Burst is the number of requests that can be made at once,
+		// it's equal to RequestsPerSecond if not set.
+		Burst int `yaml:"Burst"`
+	}
+
+	// RPCTLSConfig describes TLS settings of the RPC server.
+	RPCTLSConfig struct {
+		Enabled  bool   `yaml:"Enabled"`
+		CertFile string `yaml:"CertFile"`
+		KeyFile  string `yaml:"KeyFile"`
+		// ClientCAFile is a PEM file with CA certificates used to
+		// verify client certificates. If it's set, clients are
+		// required to provide valid certificates (mutual TLS).
+		ClientCAFile string `yaml:"ClientCAFile"`
+	}
+
+	// RPCAuthConfig describes authentication settings of the RPC server.
+	// Authentication is enabled when there is at least one user specified.
+	RPCAuthConfig struct {
+		Users []RPCUser `yaml:"Users"`
+		// RestrictedMethods is a list of methods that require
+		// authentication, all methods require it if the list is empty.
+		RestrictedMethods []string `yaml:"RestrictedMethods"`
+	}
+
+	// RPCUser describes credentials of a single RPC user. User can be
+	// authenticated either with Login and Password (basic auth) or with
+	// Token (bearer auth).
+	RPCUser struct {
+		Login    string `yaml:"Login"`
+		Password string `yaml:"Password"`
+		Token    string `yaml:"Token"`
+		// Methods is a list of restricted methods allowed for this
+		// user, all methods are allowed if the list is empty.
+		Methods []string `yaml:"Methods"`
 	}
 
 	// NetMode describes the mode the blockchain will operate on.
@@ -6,6 +6,7 @@ require (
 	github.com/etcd-io/bbolt v1.3.3
 	github.com/go-redis/redis v6.10.2+incompatible
 	github.com/go-yaml/yaml v2.1.0+incompatible
+	github.com/gorilla/websocket v1.4.1
 	github.com/mr-tron/base58 v1.1.2
 	github.com/nspcc-dev/dbft v0.0.0-20200203121303-549ecf2daaa1
 	github.com/nspcc-dev/rfc6979 v0.2.0
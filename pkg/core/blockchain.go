@@ -13,6 +13,7 @@ import (
 	"github.com/CityOfZion/neo-go/config"
 	"github.com/CityOfZion/neo-go/pkg/core/block"
 	"github.com/CityOfZion/neo-go/pkg/core/mempool"
+	"github.com/CityOfZion/neo-go/pkg/core/mpt"
 	"github.com/CityOfZion/neo-go/pkg/core/state"
 	"github.com/CityOfZion/neo-go/pkg/core/storage"
 	"github.com/CityOfZion/neo-go/pkg/core/transaction"
@@ -30,7 +31,7 @@ import (
 // Tuning parameters.
 const (
 	headerBatchCount = 2000
-	version          = "0.0.3"
+	version          = "0.0.5"
 
 	// This one comes from C# code and it's different from the constant used
 	// when creating an asset with Neo.Asset.Create interop call. It looks
@@ -50,11 +51,24 @@ var (
 	// ErrOOM is returned when adding transaction to the memory pool because
 	// it reached its full capacity.
 	ErrOOM = errors.New("no space left in the memory pool")
+	// ErrNotArchival is returned when historical state is requested from
+	// the node not running in archival mode.
+	ErrNotArchival = errors.New("archival mode is disabled")
+	// ErrStateRootDisabled is returned when state root or proof is requested
+	// from the node that doesn't calculate state roots.
+	ErrStateRootDisabled = errors.New("state root calculation is disabled")
+	// ErrTracingDisabled is returned when transaction tracing is requested
+	// from the node that doesn't keep undo data.
+	ErrTracingDisabled = errors.New("tracing requires MaxRollbackDepth > 0")
 )
 var (
 	genAmount         = []int{8, 7, 6, 5, 4, 3, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
 	decrementInterval = 2000000
 	persistInterval   = 1 * time.Second
+
+	// stateRootPrefixes are prefixes of the Store keys committed to by the
+	// state root.
+	stateRootPrefixes = []storage.KeyPrefix{storage.STAccount, storage.STContract, storage.STStorage}
 )
 
 // Blockchain represents the blockchain.
@@ -109,6 +123,21 @@ type Blockchain struct {
 	keyCache map[util.Uint160]map[string]*keys.PublicKey
 
 	log *zap.Logger
+
+	// Notification subsystem.
+	events      chan bcEvent
+	subCh       chan interface{}
+	unsubCh     chan interface{}
+	subscribers int32
+}
+
+// bcEvent is an internal event generated by the Blockchain and then
+// broadcasted to other parties. It joins the new block and associated
+// invocation logs, all the other events visible from outside can be produced
+// from this combination.
+type bcEvent struct {
+	block          *block.Block
+	appExecResults []*state.AppExecResult
 }
 
 type headersOpFunc func(headerList *HeaderHashList)
@@ -124,6 +153,19 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 		cfg.MemPoolSize = defaultMemPoolSize
 		log.Info("mempool size is not set or wrong, setting default value", zap.Int("MemPoolSize", cfg.MemPoolSize))
 	}
+	if cfg.PruningMode {
+		if cfg.ArchivalMode {
+			return nil, errors.New("pruning mode can't be used with archival mode")
+		}
+		if cfg.PruneDepth == 0 {
+			cfg.PruneDepth = defaultPruneDepth
+			log.Info("prune depth is not set, setting default value", zap.Uint32("PruneDepth", cfg.PruneDepth))
+		}
+		// Pruned data is not restored by rollback.
+		if cfg.MaxRollbackDepth > cfg.PruneDepth {
+			return nil, errors.New("max rollback depth can't be greater than prune depth")
+		}
+	}
 	bc := &Blockchain{
 		config:        cfg,
 		dao:           newDao(s),
@@ -134,16 +176,25 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 		memPool:       mempool.NewMemPool(cfg.MemPoolSize),
 		keyCache:      make(map[util.Uint160]map[string]*keys.PublicKey),
 		log:           log,
+		events:        make(chan bcEvent),
+		subCh:         make(chan interface{}),
+		unsubCh:       make(chan interface{}),
 	}
 
 	if err := bc.init(); err != nil {
 		return nil, err
 	}
+	// The dispatcher is started here, so that subscriptions work before
+	// Run is called.
+	go bc.notificationDispatcher()
 
 	return bc, nil
 }
 
 func (bc *Blockchain) init() error {
+	if bc.dao.IsImportingSnapshot() {
+		return errors.New("snapshot import is not finished, it should be repeated or the DB removed")
+	}
 	// If we could not find the version in the Store, we know that there is nothing stored.
 	ver, err := bc.dao.GetVersion()
 	if err != nil {
@@ -151,6 +202,11 @@ func (bc *Blockchain) init() error {
 		if err = bc.dao.PutVersion(version); err != nil {
 			return err
 		}
+		if bc.config.ArchivalMode {
+			if err = bc.dao.PutArchival(); err != nil {
+				return err
+			}
+		}
 		genesisBlock, err := createGenesisBlock(bc.config)
 		if err != nil {
 			return err
@@ -165,6 +221,9 @@ func (bc *Blockchain) init() error {
 	if ver != version {
 		return fmt.Errorf("storage version mismatch betweeen %s and %s", version, ver)
 	}
+	if archival := bc.dao.IsArchival(); archival != bc.config.ArchivalMode {
+		return fmt.Errorf("archival mode mismatch: DB has %t, config has %t (it can only be changed for a new DB)", archival, bc.config.ArchivalMode)
+	}
 
 	// At this point there was no version found in the storage which
 	// implies a creating fresh storage with the version specified
@@ -264,6 +323,197 @@ func (bc *Blockchain) Run() {
 	}
 }
 
+// notificationDispatcher manages subscription to events and broadcasts new
+// events. Events are sent after the block is stored in the chain in the
+// following order: for each transaction of the block its execution result (for
+// invocation transactions), notifications generated by it (for successful
+// executions) and the transaction itself are sent, then the block is sent.
+// Subscribers of one type receive events in the same order, but there are no
+// guarantees between the channels.
+func (bc *Blockchain) notificationDispatcher() {
+	var (
+		// These are just sets of subscribers, though modelled as maps
+		// for ease of management (not a lot of subscriptions is really
+		// expected, but maps are convenient for adding/deleting elements).
+		blockFeed        = make(map[chan<- *block.Block]bool)
+		txFeed           = make(map[chan<- *transaction.Transaction]bool)
+		notificationFeed = make(map[chan<- *state.NotificationEvent]bool)
+		executionFeed    = make(map[chan<- *state.AppExecResult]bool)
+	)
+	for {
+		select {
+		case <-bc.stopCh:
+			return
+		case sub := <-bc.subCh:
+			// The counter is incremented by the subscriber, so it only
+			// needs to be fixed for repeated subscriptions.
+			var exists bool
+			switch ch := sub.(type) {
+			case chan<- *block.Block:
+				exists = blockFeed[ch]
+				blockFeed[ch] = true
+			case chan<- *transaction.Transaction:
+				exists = txFeed[ch]
+				txFeed[ch] = true
+			case chan<- *state.NotificationEvent:
+				exists = notificationFeed[ch]
+				notificationFeed[ch] = true
+			case chan<- *state.AppExecResult:
+				exists = executionFeed[ch]
+				executionFeed[ch] = true
+			default:
+				panic(fmt.Sprintf("bad subscription: %T", sub))
+			}
+			if exists {
+				atomic.AddInt32(&bc.subscribers, -1)
+			}
+		case unsub := <-bc.unsubCh:
+			var deleted bool
+			switch ch := unsub.(type) {
+			case chan<- *block.Block:
+				deleted = blockFeed[ch]
+				delete(blockFeed, ch)
+			case chan<- *transaction.Transaction:
+				deleted = txFeed[ch]
+				delete(txFeed, ch)
+			case chan<- *state.NotificationEvent:
+				deleted = notificationFeed[ch]
+				delete(notificationFeed, ch)
+			case chan<- *state.AppExecResult:
+				deleted = executionFeed[ch]
+				delete(executionFeed, ch)
+			default:
+				panic(fmt.Sprintf("bad unsubscription: %T", unsub))
+			}
+			if deleted {
+				atomic.AddInt32(&bc.subscribers, -1)
+			}
+		case event := <-bc.events:
+			// We don't want to waste time looping through transactions
+			// when there are no subscribers.
+			if len(txFeed) != 0 || len(notificationFeed) != 0 || len(executionFeed) != 0 {
+				var aerIdx int
+				for _, tx := range event.block.Transactions {
+					if tx.Type == transaction.InvocationType {
+						aer := event.appExecResults[aerIdx]
+						if !aer.TxHash.Equals(tx.Hash()) {
+							panic("inconsistent application execution results")
+						}
+						aerIdx++
+						for ch := range executionFeed {
+							ch <- aer
+						}
+						if aer.VMState == "HALT" {
+							for i := range aer.Events {
+								for ch := range notificationFeed {
+									ch <- &aer.Events[i]
+								}
+							}
+						}
+					}
+					for ch := range txFeed {
+						ch <- tx
+					}
+				}
+			}
+			for ch := range blockFeed {
+				ch <- event.block
+			}
+		}
+	}
+}
+
+// subscribe registers the given channel in the notification dispatcher. The
+// subscribers counter is incremented before that, so that the first event
+// after the subscription is never missed. It does nothing if the Blockchain
+// is closed.
+func (bc *Blockchain) subscribe(ch interface{}) {
+	atomic.AddInt32(&bc.subscribers, 1)
+	select {
+	case bc.subCh <- ch:
+	case <-bc.stopCh:
+		atomic.AddInt32(&bc.subscribers, -1)
+	}
+}
+
+// unsubscribe removes the given channel from the notification dispatcher. It
+// does nothing if the Blockchain is closed.
+func (bc *Blockchain) unsubscribe(ch interface{}) {
+	select {
+	case bc.unsubCh <- ch:
+	case <-bc.stopCh:
+	}
+}
+
+// SubscribeForBlocks adds given channel to new block event broadcasting, so
+// when there is a new block added to the chain you'll receive it via this
+// channel. Make sure it's read from regularly as not reading these events
+// might affect other Blockchain functions.
+func (bc *Blockchain) SubscribeForBlocks(ch chan<- *block.Block) {
+	bc.subscribe(ch)
+}
+
+// SubscribeForTransactions adds given channel to new transaction event
+// broadcasting, so when there is a new transaction added to the chain (in a
+// block) you'll receive it via this channel. Make sure it's read from
+// regularly as not reading these events might affect other Blockchain
+// functions.
+func (bc *Blockchain) SubscribeForTransactions(ch chan<- *transaction.Transaction) {
+	bc.subscribe(ch)
+}
+
+// SubscribeForNotifications adds given channel to new notifications event
+// broadcasting, so when an in-block transaction execution generates a
+// notification you'll receive it via this channel. Only notifications from
+// successful transactions are broadcasted, if you're interested in failed
+// transactions use SubscribeForExecutions instead. Make sure this channel is
+// read from regularly as not reading these events might affect other
+// Blockchain functions.
+func (bc *Blockchain) SubscribeForNotifications(ch chan<- *state.NotificationEvent) {
+	bc.subscribe(ch)
+}
+
+// SubscribeForExecutions adds given channel to new transaction execution
+// event broadcasting, so when an in-block transaction execution happens you'll
+// receive the result of it via this channel. Make sure it's read from
+// regularly as not reading these events might affect other Blockchain
+// functions.
+func (bc *Blockchain) SubscribeForExecutions(ch chan<- *state.AppExecResult) {
+	bc.subscribe(ch)
+}
+
+// UnsubscribeFromBlocks unsubscribes given channel from new block
+// notifications, you can close it afterwards. The channel must be read from
+// until this method returns as there might be pending events for it. Passing
+// non-subscribed channel is a no-op.
+func (bc *Blockchain) UnsubscribeFromBlocks(ch chan<- *block.Block) {
+	bc.unsubscribe(ch)
+}
+
+// UnsubscribeFromTransactions unsubscribes given channel from new transaction
+// notifications, you can close it afterwards. The channel must be read from
+// until this method returns as there might be pending events for it. Passing
+// non-subscribed channel is a no-op.
+func (bc *Blockchain) UnsubscribeFromTransactions(ch chan<- *transaction.Transaction) {
+	bc.unsubscribe(ch)
+}
+
+// UnsubscribeFromNotifications unsubscribes given channel from new
+// execution-generated notifications, you can close it afterwards. The channel
+// must be read from until this method returns as there might be pending events
+// for it. Passing non-subscribed channel is a no-op.
+func (bc *Blockchain) UnsubscribeFromNotifications(ch chan<- *state.NotificationEvent) {
+	bc.unsubscribe(ch)
+}
+
+// UnsubscribeFromExecutions unsubscribes given channel from new execution
+// notifications, you can close it afterwards. The channel must be read from
+// until this method returns as there might be pending events for it. Passing
+// non-subscribed channel is a no-op.
+func (bc *Blockchain) UnsubscribeFromExecutions(ch chan<- *state.AppExecResult) {
+	bc.unsubscribe(ch)
+}
+
 // Close stops Blockchain's internal loop, syncs changes to persistent storage
 // and closes it. The Blockchain is no longer functional after the call to Close.
 func (bc *Blockchain) Close() {
@@ -274,6 +524,18 @@ func (bc *Blockchain) Close() {
 // AddBlock accepts successive block for the Blockchain, verifies it and
 // stores internally. Eventually it will be persisted to the backing storage.
 func (bc *Blockchain) AddBlock(block *block.Block) error {
+	return bc.addBlock(block, bc.config.VerifyBlocks, bc.config.VerifyTransactions)
+}
+
+// AddBlockVerified is the same as AddBlock, but it always performs full
+// verification of the block and all of its transactions irrespective of
+// the VerifyBlocks and VerifyTransactions configuration settings. It's
+// intended to be used for blocks coming from untrusted sources.
+func (bc *Blockchain) AddBlockVerified(block *block.Block) error {
+	return bc.addBlock(block, true, true)
+}
+
+func (bc *Blockchain) addBlock(block *block.Block, verifyBlock, verifyTxs bool) error {
 	bc.addLock.Lock()
 	defer bc.addLock.Unlock()
 
@@ -281,7 +543,7 @@ func (bc *Blockchain) AddBlock(block *block.Block) error {
 	if expectedHeight != block.Index {
 		return fmt.Errorf("expected block %d, but passed block %d", expectedHeight, block.Index)
 	}
-	if bc.config.VerifyBlocks {
+	if verifyBlock {
 		err := block.Verify()
 		if err == nil {
 			err = bc.VerifyBlock(block)
@@ -289,7 +551,7 @@ func (bc *Blockchain) AddBlock(block *block.Block) error {
 		if err != nil {
 			return fmt.Errorf("block %s is invalid: %s", block.Hash().StringLE(), err)
 		}
-		if bc.config.VerifyTransactions {
+		if verifyTxs {
 			for _, tx := range block.Transactions {
 				err := bc.VerifyTx(tx, block)
 				if err != nil {
@@ -370,6 +632,7 @@ func (bc *Blockchain) processHeader(h *block.Header, batch storage.Batch, header
 	}
 
 	buf.Reset()
+	buf.WriteU32LE(0) // sys fee is yet to be calculated
 	h.EncodeBinary(buf.BinWriter)
 	if buf.Err != nil {
 		return buf.Err
@@ -387,8 +650,15 @@ func (bc *Blockchain) processHeader(h *block.Header, batch storage.Batch, header
 // is happening here, quite allot as you can see :). If things are wired together
 // and all tests are in place, we can make a more optimized and cleaner implementation.
 func (bc *Blockchain) storeBlock(block *block.Block) error {
-	cache := newCachedDao(bc.dao.store)
-	if err := cache.StoreAsBlock(block, 0); err != nil {
+	var (
+		cache          = newCachedDao(bc.dao.store)
+		appExecResults = make([]*state.AppExecResult, 0, len(block.Transactions))
+	)
+	fee := bc.getSystemFeeAmount(block.PrevHash)
+	for _, tx := range block.Transactions {
+		fee += uint32(bc.SystemFee(tx).Int64Value())
+	}
+	if err := cache.StoreAsBlock(block, fee); err != nil {
 		return err
 	}
 
@@ -397,224 +667,500 @@ func (bc *Blockchain) storeBlock(block *block.Block) error {
 	}
 
 	for _, tx := range block.Transactions {
-		if err := cache.StoreAsTransaction(tx, block.Index); err != nil {
+		aer, err := bc.processTransaction(cache, block, tx)
+		if err != nil {
 			return err
 		}
-
-		if err := cache.PutUnspentCoinState(tx.Hash(), NewUnspentCoinState(len(tx.Outputs))); err != nil {
-			return err
+		if aer != nil {
+			appExecResults = append(appExecResults, aer)
 		}
+	}
+	if err := cache.flushAccounts(); err != nil {
+		return err
+	}
+	if err := bc.updateStateRoot(cache, block.Index); err != nil {
+		return errors.Wrap(err, "failed to update state root")
+	}
+	if bc.config.ArchivalMode {
+		if err := updateStateHistory(cache, block.Index); err != nil {
+			return errors.Wrap(err, "failed to update state history")
+		}
+	}
+	if bc.config.PruningMode {
+		if err := markPrunable(cache, block); err != nil {
+			return errors.Wrap(err, "failed to save prunable transactions")
+		}
+	}
+	if depth := bc.config.MaxRollbackDepth; depth > 0 {
+		if err := bc.saveUndoData(cache, block.Index); err != nil {
+			return errors.Wrap(err, "failed to save undo data")
+		}
+		if block.Index >= depth {
+			if err := cache.store.Delete(undoKey(block.Index - depth)); err != nil {
+				return err
+			}
+		}
+	}
+	// Pruning is done after saving undo data, so that pruned data is removed
+	// from the DB right away.
+	if bc.config.PruningMode && block.Index > bc.config.PruneDepth {
+		if err := bc.pruneBlock(cache, block.Index-bc.config.PruneDepth); err != nil {
+			return errors.Wrap(err, "failed to prune old block")
+		}
+	}
+	bc.lock.Lock()
+	_, err := cache.Persist()
+	if err != nil {
+		bc.lock.Unlock()
+		return err
+	}
+	bc.topBlock.Store(block)
+	atomic.StoreUint32(&bc.blockHeight, block.Index)
+	updateBlockHeightMetric(block.Index)
+	bc.memPool.RemoveStale(bc.isTxStillRelevant)
+	bc.lock.Unlock()
 
-		// Process TX outputs.
-		if err := processOutputs(tx, cache); err != nil {
-			return err
+	// Events are sent after releasing the lock (so that subscribers can
+	// query the chain), but still under addLock to keep them ordered.
+	if atomic.LoadInt32(&bc.subscribers) != 0 {
+		select {
+		case bc.events <- bcEvent{block, appExecResults}:
+		case <-bc.stopCh:
 		}
+	}
+	return nil
+}
+
+// processTransaction applies the changes made by the given transaction of the
+// block being stored to the cache. The execution result is returned for
+// InvocationTX only.
+func (bc *Blockchain) processTransaction(cache *cachedDao, block *block.Block, tx *transaction.Transaction) (*state.AppExecResult, error) {
+	if err := cache.StoreAsTransaction(tx, block.Index); err != nil {
+		return nil, err
+	}
+
+	if err := cache.PutUnspentCoinState(tx.Hash(), NewUnspentCoinState(len(tx.Outputs))); err != nil {
+		return nil, err
+	}
+
+	// Process TX outputs.
+	if err := processOutputs(tx, cache); err != nil {
+		return nil, err
+	}
 
-		// Process TX inputs that are grouped by previous hash.
-		for prevHash, inputs := range tx.GroupInputsByPrevHash() {
-			prevTX, prevTXHeight, err := bc.GetTransaction(prevHash)
+	// Process TX inputs that are grouped by previous hash.
+	for prevHash, inputs := range tx.GroupInputsByPrevHash() {
+		prevTX, prevTXHeight, err := bc.GetTransaction(prevHash)
+		if err != nil {
+			return nil, fmt.Errorf("could not find previous TX: %s", prevHash)
+		}
+		for _, input := range inputs {
+			unspent, err := cache.GetUnspentCoinStateOrNew(input.PrevHash)
+			if err != nil {
+				return nil, err
+			}
+			unspent.states[input.PrevIndex] = state.CoinSpent
+			if err = cache.PutUnspentCoinState(input.PrevHash, unspent); err != nil {
+				return nil, err
+			}
+			prevTXOutput := prevTX.Outputs[input.PrevIndex]
+			account, err := cache.GetAccountStateOrNew(prevTXOutput.ScriptHash)
 			if err != nil {
-				return fmt.Errorf("could not find previous TX: %s", prevHash)
+				return nil, err
 			}
-			for _, input := range inputs {
-				unspent, err := cache.GetUnspentCoinStateOrNew(input.PrevHash)
+
+			if prevTXOutput.AssetID.Equals(governingTokenTX().Hash()) {
+				spentCoin, err := cache.GetSpentCoinsOrNew(input.PrevHash)
 				if err != nil {
-					return err
-				}
-				unspent.states[input.PrevIndex] = state.CoinSpent
-				if err = cache.PutUnspentCoinState(input.PrevHash, unspent); err != nil {
-					return err
+					return nil, err
 				}
-				prevTXOutput := prevTX.Outputs[input.PrevIndex]
-				account, err := cache.GetAccountStateOrNew(prevTXOutput.ScriptHash)
-				if err != nil {
-					return err
+				spentCoin.txHash = input.PrevHash
+				spentCoin.txHeight = prevTXHeight
+				spentCoin.items[input.PrevIndex] = block.Index
+				if err = cache.PutSpentCoinState(input.PrevHash, spentCoin); err != nil {
+					return nil, err
 				}
-
-				if prevTXOutput.AssetID.Equals(governingTokenTX().Hash()) {
-					spentCoin := NewSpentCoinState(input.PrevHash, prevTXHeight)
-					spentCoin.items[input.PrevIndex] = block.Index
-					if err = cache.PutSpentCoinState(input.PrevHash, spentCoin); err != nil {
-						return err
-					}
-					if err = processTXWithValidatorsSubtract(account, cache, prevTXOutput.Amount); err != nil {
-						return err
-					}
+				account.Unclaimed = append(account.Unclaimed, state.UnclaimedBalance{
+					Tx:    input.PrevHash,
+					Index: input.PrevIndex,
+					Start: prevTXHeight,
+					End:   block.Index,
+					Value: prevTXOutput.Amount,
+				})
+				if err = processTXWithValidatorsSubtract(account, cache, prevTXOutput.Amount); err != nil {
+					return nil, err
 				}
+			}
 
-				balancesLen := len(account.Balances[prevTXOutput.AssetID])
-				if balancesLen <= 1 {
-					delete(account.Balances, prevTXOutput.AssetID)
-				} else {
-					var index = -1
-					for i, balance := range account.Balances[prevTXOutput.AssetID] {
-						if balance.Tx.Equals(input.PrevHash) && balance.Index == input.PrevIndex {
-							index = i
-							break
-						}
-					}
-					if index >= 0 {
-						copy(account.Balances[prevTXOutput.AssetID][index:], account.Balances[prevTXOutput.AssetID][index+1:])
-						account.Balances[prevTXOutput.AssetID] = account.Balances[prevTXOutput.AssetID][:balancesLen-1]
+			balancesLen := len(account.Balances[prevTXOutput.AssetID])
+			if balancesLen <= 1 {
+				delete(account.Balances, prevTXOutput.AssetID)
+			} else {
+				var index = -1
+				for i, balance := range account.Balances[prevTXOutput.AssetID] {
+					if balance.Tx.Equals(input.PrevHash) && balance.Index == input.PrevIndex {
+						index = i
+						break
 					}
 				}
-				if err = cache.PutAccountState(account); err != nil {
-					return err
+				if index >= 0 {
+					copy(account.Balances[prevTXOutput.AssetID][index:], account.Balances[prevTXOutput.AssetID][index+1:])
+					account.Balances[prevTXOutput.AssetID] = account.Balances[prevTXOutput.AssetID][:balancesLen-1]
 				}
 			}
+			if err = cache.PutAccountState(account); err != nil {
+				return nil, err
+			}
 		}
+	}
 
-		// Process the underlying type of the TX.
-		switch t := tx.Data.(type) {
-		case *transaction.RegisterTX:
-			err := cache.PutAssetState(&state.Asset{
-				ID:         tx.Hash(),
-				AssetType:  t.AssetType,
-				Name:       t.Name,
-				Amount:     t.Amount,
-				Precision:  t.Precision,
-				Owner:      t.Owner,
-				Admin:      t.Admin,
-				Expiration: bc.BlockHeight() + registeredAssetLifetime,
-			})
-			if err != nil {
-				return err
-			}
-		case *transaction.IssueTX:
-			for _, res := range bc.GetTransactionResults(tx) {
-				if res.Amount < 0 {
-					asset, err := cache.GetAssetState(res.AssetID)
-					if asset == nil || err != nil {
-						return fmt.Errorf("issue failed: no asset %s or error %s", res.AssetID, err)
-					}
-					asset.Available -= res.Amount
-					if err := cache.PutAssetState(asset); err != nil {
-						return err
-					}
-				}
-			}
-		case *transaction.ClaimTX:
-			// Remove claimed NEO from spent coins making it unavalaible for
-			// additional claims.
-			for _, input := range t.Claims {
-				scs, err := cache.GetSpentCoinsOrNew(input.PrevHash)
-				if err != nil {
-					return err
+	// Process the underlying type of the TX.
+	switch t := tx.Data.(type) {
+	case *transaction.RegisterTX:
+		err := cache.PutAssetState(&state.Asset{
+			ID:         tx.Hash(),
+			AssetType:  t.AssetType,
+			Name:       t.Name,
+			Amount:     t.Amount,
+			Precision:  t.Precision,
+			Owner:      t.Owner,
+			Admin:      t.Admin,
+			Expiration: bc.BlockHeight() + registeredAssetLifetime,
+		})
+		if err != nil {
+			return nil, err
+		}
+	case *transaction.IssueTX:
+		for _, res := range bc.GetTransactionResults(tx) {
+			if res.Amount < 0 {
+				asset, err := cache.GetAssetState(res.AssetID)
+				if asset == nil || err != nil {
+					return nil, fmt.Errorf("issue failed: no asset %s or error %s", res.AssetID, err)
 				}
-				if scs.txHash == input.PrevHash {
-					// Existing scs.
-					delete(scs.items, input.PrevIndex)
-					if err = cache.PutSpentCoinState(input.PrevHash, scs); err != nil {
-						return err
-					}
-				} else {
-					// Uninitialized, new, forget about it.
-					if err = cache.DeleteSpentCoinState(input.PrevHash); err != nil {
-						return err
-					}
+				asset.Available -= res.Amount
+				if err := cache.PutAssetState(asset); err != nil {
+					return nil, err
 				}
 			}
-		case *transaction.EnrollmentTX:
-			if err := processEnrollmentTX(cache, t); err != nil {
-				return err
-			}
-		case *transaction.StateTX:
-			if err := processStateTX(cache, t); err != nil {
-				return err
-			}
-		case *transaction.PublishTX:
-			var properties smartcontract.PropertyState
-			if t.NeedStorage {
-				properties |= smartcontract.HasStorage
-			}
-			contract := &state.Contract{
-				Script:      t.Script,
-				ParamList:   t.ParamList,
-				ReturnType:  t.ReturnType,
-				Properties:  properties,
-				Name:        t.Name,
-				CodeVersion: t.CodeVersion,
-				Author:      t.Author,
-				Email:       t.Email,
-				Description: t.Description,
+		}
+	case *transaction.ClaimTX:
+		// Remove claimed NEO from spent coins making it unavalaible for
+		// additional claims.
+		for _, input := range t.Claims {
+			if err := bc.removeUnclaimed(cache, input); err != nil {
+				return nil, err
 			}
-			if err := cache.PutContractState(contract); err != nil {
-				return err
+			scs, err := cache.GetSpentCoinsOrNew(input.PrevHash)
+			if err != nil {
+				return nil, err
 			}
-		case *transaction.InvocationTX:
-			systemInterop := bc.newInteropContext(trigger.Application, cache.store, block, tx)
-			v := bc.spawnVMWithInterops(systemInterop)
-			v.SetCheckedHash(tx.VerificationHash().BytesBE())
-			v.LoadScript(t.Script)
-			v.SetPriceGetter(getPrice)
-			if bc.config.FreeGasLimit > 0 {
-				v.SetGasLimit(bc.config.FreeGasLimit + t.Gas)
+			if scs.txHash == input.PrevHash {
+				// Existing scs.
+				delete(scs.items, input.PrevIndex)
+				if err = cache.PutSpentCoinState(input.PrevHash, scs); err != nil {
+					return nil, err
+				}
+			} else {
+				// Uninitialized, new, forget about it.
+				if err = cache.DeleteSpentCoinState(input.PrevHash); err != nil {
+					return nil, err
+				}
 			}
+		}
+	case *transaction.EnrollmentTX:
+		if err := processEnrollmentTX(cache, t); err != nil {
+			return nil, err
+		}
+	case *transaction.StateTX:
+		if err := processStateTX(cache, t); err != nil {
+			return nil, err
+		}
+	case *transaction.PublishTX:
+		var properties smartcontract.PropertyState
+		if t.NeedStorage {
+			properties |= smartcontract.HasStorage
+		}
+		contract := &state.Contract{
+			Script:      t.Script,
+			ParamList:   t.ParamList,
+			ReturnType:  t.ReturnType,
+			Properties:  properties,
+			Name:        t.Name,
+			CodeVersion: t.CodeVersion,
+			Author:      t.Author,
+			Email:       t.Email,
+			Description: t.Description,
+		}
+		if err := cache.PutContractState(contract); err != nil {
+			return nil, err
+		}
+	case *transaction.InvocationTX:
+		systemInterop := bc.newInteropContext(trigger.Application, cache.store, block, tx)
+		v := bc.spawnVMWithInterops(systemInterop)
+		v.SetCheckedHash(tx.VerificationHash().BytesBE())
+		v.LoadScript(t.Script)
+		v.SetPriceGetter(getPrice)
+		if bc.config.FreeGasLimit > 0 {
+			v.SetGasLimit(bc.config.FreeGasLimit + t.Gas)
+		}
 
-			err := v.Run()
-			if !v.HasFailed() {
-				_, err := systemInterop.dao.Persist()
+		err := v.Run()
+		if !v.HasFailed() {
+			_, err := systemInterop.dao.Persist()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to persist invocation results")
+			}
+			for i := range systemInterop.notifications {
+				err := bc.handleNotification(&systemInterop.notifications[i], cache, block, tx.Hash())
 				if err != nil {
-					return errors.Wrap(err, "failed to persist invocation results")
+					return nil, errors.Wrap(err, "failed to process NEP5 transfer")
 				}
-				for _, note := range systemInterop.notifications {
-					arr, ok := note.Item.Value().([]vm.StackItem)
-					if !ok || len(arr) != 4 {
-						continue
-					}
-					op, ok := arr[0].Value().([]byte)
-					if !ok || string(op) != "transfer" {
-						continue
-					}
-					from, ok := arr[1].Value().([]byte)
-					if !ok {
-						continue
-					}
-					to, ok := arr[2].Value().([]byte)
-					if !ok {
-						continue
-					}
-					amount, ok := arr[3].Value().(*big.Int)
-					if !ok {
-						continue
-					}
-					// TODO: #498
-					_, _, _, _ = op, from, to, amount
-				}
-			} else {
-				bc.log.Warn("contract invocation failed",
-					zap.String("tx", tx.Hash().StringLE()),
-					zap.Uint32("block", block.Index),
-					zap.Error(err))
-			}
-			aer := &state.AppExecResult{
-				TxHash:      tx.Hash(),
-				Trigger:     trigger.Application,
-				VMState:     v.State(),
-				GasConsumed: v.GasConsumed(),
-				Stack:       v.Stack("estack"),
-				Events:      systemInterop.notifications,
 			}
-			err = cache.PutAppExecResult(aer)
+		} else {
+			bc.log.Warn("contract invocation failed",
+				zap.String("tx", tx.Hash().StringLE()),
+				zap.Uint32("block", block.Index),
+				zap.Error(err))
+		}
+		aer := &state.AppExecResult{
+			TxHash:      tx.Hash(),
+			Trigger:     trigger.Application,
+			VMState:     v.State(),
+			GasConsumed: v.GasConsumed(),
+			Stack:       v.Stack("estack"),
+			Events:      systemInterop.notifications,
+		}
+		err = cache.PutAppExecResult(aer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to store notifications")
+		}
+		return aer, nil
+	}
+	return nil, nil
+}
+
+// updateStateRoot applies changes made to the state by the block being stored
+// to the MPT and saves the new state root if state roots are enabled. If
+// there is no state root for the previous block (because state roots were
+// disabled before) the MPT is built from scratch. Cached accounts must be
+// flushed before calling it.
+func (bc *Blockchain) updateStateRoot(cache *cachedDao, index uint32) error {
+	if !bc.config.StateRootEnabled {
+		return nil
+	}
+	var prev util.Uint256
+	if index > 0 {
+		var err error
+		prev, err = cache.GetStateRoot(index - 1)
+		if err == storage.ErrKeyNotFound {
+			bc.log.Info("no state root for the previous block, rebuilding state trie",
+				zap.Uint32("block", index))
+			root, err := rebuildStateTrie(cache.store)
 			if err != nil {
-				return errors.Wrap(err, "failed to store notifications")
+				return errors.Wrap(err, "failed to rebuild state trie")
 			}
+			return cache.PutStateRoot(index, root)
+		} else if err != nil {
+			return errors.Wrapf(err, "no state root for block %d", index-1)
 		}
 	}
-	bc.lock.Lock()
-	defer bc.lock.Unlock()
+	changes := make(map[string][]byte)
+	for _, p := range stateRootPrefixes {
+		cache.store.SeekChanges(p.Bytes(), func(k, v []byte) {
+			changes[string(k)] = v
+		})
+	}
+	tr := mpt.NewTrie(prev, cache.store)
+	for k, v := range changes {
+		var err error
+		if v == nil {
+			err = tr.Delete([]byte(k))
+		} else {
+			err = tr.Put([]byte(k), v)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to update key %x", k)
+		}
+	}
+	if err := tr.Flush(); err != nil {
+		return err
+	}
+	return cache.PutStateRoot(index, tr.StateRoot())
+}
 
-	_, err := cache.Persist()
+// updateStateHistory saves the values of all keys changed by the block being
+// stored as their versions for the block. Deleted keys are saved with empty
+// values. Cached accounts must be flushed before calling it.
+func updateStateHistory(cache *cachedDao, index uint32) error {
+	changes := make(map[string][]byte)
+	for _, p := range historyPrefixes {
+		cache.store.SeekChanges(p.Bytes(), func(k, v []byte) {
+			changes[string(k)] = v
+		})
+	}
+	for k, v := range changes {
+		if err := putVersion(cache.store, []byte(k), index, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeUnclaimed removes the output referenced by the given claim input from
+// the unclaimed outputs list of its owner's account.
+func (bc *Blockchain) removeUnclaimed(cache *cachedDao, input *transaction.Input) error {
+	prevTX, _, err := cache.GetTransaction(input.PrevHash)
 	if err != nil {
 		return err
 	}
-	bc.topBlock.Store(block)
-	atomic.StoreUint32(&bc.blockHeight, block.Index)
-	updateBlockHeightMetric(block.Index)
-	bc.memPool.RemoveStale(bc.isTxStillRelevant)
+	if int(input.PrevIndex) >= len(prevTX.Outputs) {
+		return fmt.Errorf("claimed output %s:%d doesn't exist", input.PrevHash, input.PrevIndex)
+	}
+	acc, err := cache.GetAccountStateOrNew(prevTX.Outputs[input.PrevIndex].ScriptHash)
+	if err != nil {
+		return err
+	}
+	for i, ub := range acc.Unclaimed {
+		if ub.Tx.Equals(input.PrevHash) && ub.Index == input.PrevIndex {
+			acc.Unclaimed = append(acc.Unclaimed[:i], acc.Unclaimed[i+1:]...)
+			return cache.PutAccountState(acc)
+		}
+	}
 	return nil
 }
 
+// parseUint160 converts the address from the NEP5 `transfer` event into
+// Uint160, empty address is converted to zero hash. It returns false if the
+// address is neither empty nor 20 bytes long.
+func parseUint160(addr []byte) (util.Uint160, bool) {
+	if len(addr) == 0 {
+		return util.Uint160{}, true
+	}
+	u, err := util.Uint160DecodeBytesBE(addr)
+	return u, err == nil
+}
+
+// handleNotification checks whether the given notification is a NEP5 `transfer`
+// event and processes it if so. Malformed events (with bad addresses or
+// negative amounts) are skipped.
+func (bc *Blockchain) handleNotification(note *state.NotificationEvent, d *cachedDao, b *block.Block, h util.Uint256) error {
+	arr, ok := note.Item.Value().([]vm.StackItem)
+	if !ok || len(arr) != 4 {
+		return nil
+	}
+	op, ok := arr[0].Value().([]byte)
+	if !ok || string(op) != "transfer" {
+		return nil
+	}
+	from, ok := arr[1].Value().([]byte)
+	if !ok {
+		return nil
+	}
+	to, ok := arr[2].Value().([]byte)
+	if !ok {
+		return nil
+	}
+	fromAddr, okFrom := parseUint160(from)
+	toAddr, okTo := parseUint160(to)
+	if !okFrom || !okTo {
+		return nil
+	}
+	amount, ok := arr[3].Value().(*big.Int)
+	if !ok {
+		bs, ok := arr[3].Value().([]byte)
+		if !ok {
+			return nil
+		}
+		amount = emit.BytesToInt(bs)
+	}
+	if amount.Sign() < 0 {
+		return nil
+	}
+	return processNEP5Transfer(d, h, b, note.ScriptHash, fromAddr, toAddr, amount)
+}
+
+// processNEP5Transfer updates NEP5 balances and transfer logs of both the
+// sender and the receiver of the transfer. Zero sender (minting) or receiver
+// (burning) addresses are not tracked.
+func processNEP5Transfer(cache *cachedDao, h util.Uint256, b *block.Block, sc util.Uint160, fromAddr, toAddr util.Uint160, amount *big.Int) error {
+	transfer := &state.NEP5Transfer{
+		Asset:     sc,
+		From:      fromAddr,
+		To:        toAddr,
+		Block:     b.Index,
+		Timestamp: b.Timestamp,
+		Tx:        h,
+	}
+	if !fromAddr.Equals(util.Uint160{}) {
+		transfer.Amount = new(big.Int).Neg(amount)
+		if err := updateNEP5Balance(cache, fromAddr, transfer); err != nil {
+			return err
+		}
+	}
+	if !toAddr.Equals(util.Uint160{}) {
+		transfer.Amount = amount
+		if err := updateNEP5Balance(cache, toAddr, transfer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateNEP5Balance changes the balance of the account by the transfer amount
+// and appends the transfer to the account's log.
+func updateNEP5Balance(cache *cachedDao, acc util.Uint160, transfer *state.NEP5Transfer) error {
+	balances, err := cache.GetNEP5Balances(acc)
+	if err != nil {
+		return err
+	}
+	bs := balances.Trackers[transfer.Asset]
+	balance := new(big.Int).Set(transfer.Amount)
+	if bs.Balance != nil {
+		balance.Add(balance, bs.Balance)
+	}
+	bs.Balance = balance
+	bs.LastUpdatedBlock = transfer.Block
+	balances.Trackers[transfer.Asset] = bs
+
+	full, err := cache.AppendNEP5Transfer(acc, balances.NextTransferBatch, transfer)
+	if err != nil {
+		return err
+	}
+	if full {
+		balances.NextTransferBatch++
+	}
+	return cache.PutNEP5Balances(acc, balances)
+}
+
+// ForEachNEP5Transfer calls f for every NEP5 transfer of the specified account
+// from the oldest to the newest one, returning on the first error.
+func (bc *Blockchain) ForEachNEP5Transfer(acc util.Uint160, f func(*state.NEP5Transfer) error) error {
+	balances, err := bc.dao.GetNEP5Balances(acc)
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i <= balances.NextTransferBatch; i++ {
+		lg, err := bc.dao.GetNEP5TransferLog(acc, i)
+		if err != nil {
+			return err
+		}
+		if err := lg.ForEach(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNEP5Balances returns NEP5 balances for the specified account.
+func (bc *Blockchain) GetNEP5Balances(acc util.Uint160) *state.NEP5Balances {
+	bs, err := bc.dao.GetNEP5Balances(acc)
+	if err != nil {
+		bc.log.Warn("failed to get NEP5 balances", zap.Error(err))
+		return nil
+	}
+	return bs
+}
+
 // processOutputs processes transaction outputs.
 func processOutputs(tx *transaction.Transaction, dao *cachedDao) error {
 	for index, output := range tx.Outputs {
@@ -785,6 +1331,12 @@ func (bc *Blockchain) GetTransaction(hash util.Uint256) (*transaction.Transactio
 	return bc.dao.GetTransaction(hash)
 }
 
+// GetAppExecResult returns application execution result by the given
+// tx hash.
+func (bc *Blockchain) GetAppExecResult(hash util.Uint256) (*state.AppExecResult, error) {
+	return bc.dao.GetAppExecResult(hash)
+}
+
 // GetStorageItem returns an item from storage.
 func (bc *Blockchain) GetStorageItem(scripthash util.Uint160, key []byte) *state.StorageItem {
 	return bc.dao.GetStorageItem(scripthash, key)
@@ -795,6 +1347,12 @@ func (bc *Blockchain) GetStorageItems(hash util.Uint160) (map[string]*state.Stor
 	return bc.dao.GetStorageItems(hash)
 }
 
+// GetStorageItemsWithPrefix returns all storage items with the given key
+// prefix for a given scripthash.
+func (bc *Blockchain) GetStorageItemsWithPrefix(hash util.Uint160, prefix []byte) (map[string]*state.StorageItem, error) {
+	return bc.dao.GetStorageItemsWithPrefix(hash, prefix)
+}
+
 // GetBlock returns a Block by the given hash.
 func (bc *Blockchain) GetBlock(hash util.Uint256) (*block.Block, error) {
 	topBlock := bc.topBlock.Load()
@@ -804,7 +1362,7 @@ func (bc *Blockchain) GetBlock(hash util.Uint256) (*block.Block, error) {
 		}
 	}
 
-	block, err := bc.dao.GetBlock(hash)
+	block, _, err := bc.dao.GetBlock(hash)
 	if err != nil {
 		return nil, err
 	}
@@ -829,13 +1387,70 @@ func (bc *Blockchain) GetHeader(hash util.Uint256) (*block.Header, error) {
 			return tb.Header(), nil
 		}
 	}
-	block, err := bc.dao.GetBlock(hash)
+	block, _, err := bc.dao.GetBlock(hash)
 	if err != nil {
 		return nil, err
 	}
 	return block.Header(), nil
 }
 
+// GetSystemFeeAmount returns the cumulative system fee amount (in GAS) of
+// all blocks up to (and including) the block with the given hash.
+func (bc *Blockchain) GetSystemFeeAmount(h util.Uint256) uint32 {
+	return bc.getSystemFeeAmount(h)
+}
+
+// CalculateClaimable calculates the amount of GAS generated by holding the
+// given amount of NEO between startHeight and endHeight blocks. It returns
+// the GAS generated according to the generation schedule and the share of
+// system fees paid in this period separately.
+func (bc *Blockchain) CalculateClaimable(value util.Fixed8, startHeight, endHeight uint32) (util.Fixed8, util.Fixed8) {
+	var amount util.Fixed8
+	di := uint32(decrementInterval)
+
+	ustart := startHeight / di
+	if genSize := uint32(len(genAmount)); ustart < genSize {
+		istart := startHeight % di
+		uend := endHeight / di
+		iend := endHeight % di
+		if uend >= genSize {
+			uend = genSize
+			iend = 0
+		}
+		if iend == 0 {
+			uend--
+			iend = di
+		}
+		for ustart < uend {
+			amount += util.Fixed8(di-istart) * util.Fixed8(genAmount[ustart])
+			ustart++
+			istart = 0
+		}
+		amount += util.Fixed8(iend-istart) * util.Fixed8(genAmount[ustart])
+	}
+
+	var feeStart, feeEnd uint32
+	if endHeight > 0 {
+		feeEnd = bc.getSystemFeeAmount(bc.GetHeaderHash(int(endHeight) - 1))
+	}
+	if startHeight > 0 {
+		feeStart = bc.getSystemFeeAmount(bc.GetHeaderHash(int(startHeight) - 1))
+	}
+	sysFee := util.Fixed8(feeEnd - feeStart)
+
+	// Generation amounts and system fees are per whole NEO supply of 100
+	// millions, so each NEO gets its 1e-8 share.
+	ratio := value / util.Fixed8FromInt64(1)
+	return amount * ratio, sysFee * ratio
+}
+
+// getSystemFeeAmount returns the cumulative system fee amount for the block
+// with the given hash or zero if there is no such block.
+func (bc *Blockchain) getSystemFeeAmount(h util.Uint256) uint32 {
+	_, sf, _ := bc.dao.GetBlock(h)
+	return sf
+}
+
 // HasTransaction returns true if the blockchain contains he given
 // transaction hash.
 func (bc *Blockchain) HasTransaction(hash util.Uint256) bool {
@@ -1276,6 +1891,41 @@ func (bc *Blockchain) GetStandByValidators() (keys.PublicKeys, error) {
 	return getValidators(bc.config)
 }
 
+// GetEnrollments returns all registered validator candidates and standby
+// validators (even if they're not registered).
+func (bc *Blockchain) GetEnrollments() ([]*state.Validator, error) {
+	validators := bc.dao.GetValidators()
+	standByValidators, err := bc.GetStandByValidators()
+	if err != nil {
+		return nil, err
+	}
+	uniqueSBValidators := standByValidators.Unique()
+
+	var result []*state.Validator
+	for _, validator := range validators {
+		if validator.Registered || uniqueSBValidators.Contains(validator.PublicKey) {
+			result = append(result, validator)
+		}
+	}
+	for _, sbValidator := range uniqueSBValidators {
+		isAdded := false
+		for _, v := range result {
+			if v.PublicKey.Equal(sbValidator) {
+				isAdded = true
+				break
+			}
+		}
+		if !isAdded {
+			result = append(result, &state.Validator{
+				PublicKey:  sbValidator,
+				Registered: false,
+				Votes:      0,
+			})
+		}
+	}
+	return result, nil
+}
+
 // GetValidators returns validators.
 // Golang implementation of GetValidators method in C# (https://github.com/neo-project/neo/blob/c64748ecbac3baeb8045b16af0d518398a6ced24/neo/Persistence/Snapshot.cs#L182)
 func (bc *Blockchain) GetValidators(txes ...*transaction.Transaction) ([]*keys.PublicKey, error) {
@@ -1471,14 +2121,183 @@ func (bc *Blockchain) spawnVMWithInterops(interopCtx *interopContext) *vm.VM {
 }
 
 // GetTestVM returns a VM and a Store setup for a test run of some sort of code.
-func (bc *Blockchain) GetTestVM() (*vm.VM, storage.Store) {
+// The VM is run with the given trigger and transaction as a script container
+// (it can be nil), so that runtime interops like CheckWitness and GetTrigger
+// behave the same way they do for real transactions.
+func (bc *Blockchain) GetTestVM(trig byte, tx *transaction.Transaction) (*vm.VM, storage.Store) {
 	tmpStore := storage.NewMemCachedStore(bc.dao.store)
-	systemInterop := bc.newInteropContext(trigger.Application, tmpStore, nil, nil)
+	systemInterop := bc.newInteropContext(trig, tmpStore, nil, tx)
 	vm := bc.spawnVMWithInterops(systemInterop)
 	vm.SetPriceGetter(getPrice)
 	return vm, tmpStore
 }
 
+// historicDao returns a read-only dao for the state after processing the
+// block with the given index, it's only available in archival mode.
+func (bc *Blockchain) historicDao(index uint32) (*dao, error) {
+	if !bc.config.ArchivalMode {
+		return nil, ErrNotArchival
+	}
+	if index > bc.BlockHeight() {
+		return nil, fmt.Errorf("block %d is not yet processed", index)
+	}
+	return newDao(&historicStore{store: bc.dao.store, index: index}), nil
+}
+
+// GetAccountStateAt returns the account state after processing the block with
+// the given index or nil if there was no such account.
+func (bc *Blockchain) GetAccountStateAt(scriptHash util.Uint160, index uint32) (*state.Account, error) {
+	d, err := bc.historicDao(index)
+	if err != nil {
+		return nil, err
+	}
+	as, err := d.GetAccountState(scriptHash)
+	if err == storage.ErrKeyNotFound {
+		return nil, nil
+	}
+	return as, err
+}
+
+// GetContractStateAt returns the contract state after processing the block
+// with the given index or nil if there was no such contract.
+func (bc *Blockchain) GetContractStateAt(hash util.Uint160, index uint32) (*state.Contract, error) {
+	d, err := bc.historicDao(index)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := d.GetContractState(hash)
+	if err == storage.ErrKeyNotFound {
+		return nil, nil
+	}
+	return cs, err
+}
+
+// GetStorageItemAt returns the storage item after processing the block with
+// the given index or nil if there was no such item.
+func (bc *Blockchain) GetStorageItemAt(scripthash util.Uint160, key []byte, index uint32) (*state.StorageItem, error) {
+	d, err := bc.historicDao(index)
+	if err != nil {
+		return nil, err
+	}
+	return d.GetStorageItem(scripthash, key), nil
+}
+
+// GetUnspentCoinStateAt returns the unspent coin state for the given tx hash
+// after processing the block with the given index or nil if there was no such
+// state.
+func (bc *Blockchain) GetUnspentCoinStateAt(hash util.Uint256, index uint32) (*UnspentCoinState, error) {
+	d, err := bc.historicDao(index)
+	if err != nil {
+		return nil, err
+	}
+	ucs, err := d.GetUnspentCoinState(hash)
+	if err == storage.ErrKeyNotFound {
+		return nil, nil
+	}
+	return ucs, err
+}
+
+// GetStateRoot returns the state root after processing the block with the
+// given index.
+func (bc *Blockchain) GetStateRoot(index uint32) (util.Uint256, error) {
+	if !bc.config.StateRootEnabled {
+		return util.Uint256{}, ErrStateRootDisabled
+	}
+	return bc.dao.GetStateRoot(index)
+}
+
+// GetStateProof returns a proof of the value stored under the given key in
+// the state with the given root. Proofs can be verified with
+// mpt.VerifyProof.
+func (bc *Blockchain) GetStateProof(root util.Uint256, key []byte) ([][]byte, error) {
+	if !bc.config.StateRootEnabled {
+		return nil, ErrStateRootDisabled
+	}
+	return mpt.NewTrie(root, bc.dao.store).GetProof(key)
+}
+
+// StorageItemKey returns the key of the given contract's storage item in the
+// state, it's used for state proofs.
+func StorageItemKey(scripthash util.Uint160, key []byte) []byte {
+	return makeStorageItemKey(scripthash, key)
+}
+
+// TraceTransaction re-executes persisted InvocationTX with the given hash in
+// the context of its block and returns the execution result along with the
+// trace of all instructions executed. The execution is performed against a
+// throw-away copy of the state the chain had before this block with all the
+// preceding transactions of the block applied, so nothing is changed in the
+// chain. This state is restored using undo data, so only transactions of the
+// latest MaxRollbackDepth blocks can be traced and ErrTracingDisabled is
+// returned if it's zero. The chain can't store new blocks during the
+// execution, so it's limited by the amount of GAS the transaction was allowed
+// to spend (or the amount it has spent if there is no FreeGasLimit).
+func (bc *Blockchain) TraceTransaction(hash util.Uint256) (*state.AppExecResult, []vm.TraceEntry, error) {
+	if bc.config.MaxRollbackDepth == 0 {
+		return nil, nil, ErrTracingDisabled
+	}
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	tx, height, err := bc.dao.GetTransaction(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, ok := tx.Data.(*transaction.InvocationTX)
+	if !ok {
+		return nil, nil, fmt.Errorf("transaction %s is not an invocation transaction", hash.StringLE())
+	}
+	block, err := bc.GetBlock(bc.GetHeaderHash(int(height)))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get block %d", height)
+	}
+	gasLimit := bc.config.FreeGasLimit + t.Gas
+	if bc.config.FreeGasLimit == 0 {
+		// The execution is deterministic, so it can't take more than
+		// the original one did.
+		orig, err := bc.dao.GetAppExecResult(hash)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to get execution result")
+		}
+		gasLimit = orig.GasConsumed
+	}
+
+	tmpStore := storage.NewMemCachedStore(bc.dao.store)
+	for i := bc.BlockHeight() + 1; i > height; i-- {
+		if err := revertBlock(tmpStore, i-1); err != nil {
+			return nil, nil, errors.Wrapf(err, "state before block %d is not available", height)
+		}
+	}
+	cache := newCachedDao(tmpStore)
+	for _, prev := range block.Transactions {
+		if prev.Hash().Equals(hash) {
+			break
+		}
+		if _, err := bc.processTransaction(cache, block, prev); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to apply transaction %s", prev.Hash().StringLE())
+		}
+	}
+
+	systemInterop := bc.newInteropContext(trigger.Application, cache.store, block, tx)
+	v := bc.spawnVMWithInterops(systemInterop)
+	v.SetCheckedHash(tx.VerificationHash().BytesBE())
+	v.LoadScript(t.Script)
+	v.SetPriceGetter(getPrice)
+	v.SetGasLimit(gasLimit)
+	v.EnableTracing(0)
+	_ = v.Run()
+
+	aer := &state.AppExecResult{
+		TxHash:      hash,
+		Trigger:     trigger.Application,
+		VMState:     v.State(),
+		GasConsumed: v.GasConsumed(),
+		Stack:       v.Stack("estack"),
+		Events:      systemInterop.notifications,
+	}
+	return aer, v.Trace(), nil
+}
+
 // ScriptFromWitness returns verification script for provided witness.
 // If hash is not equal to the witness script hash, error is returned.
 func ScriptFromWitness(hash util.Uint160, witness *transaction.Witness) ([]byte, error) {
@@ -1,16 +1,25 @@
 package core
 
 import (
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/CityOfZion/neo-go/config"
 	"github.com/CityOfZion/neo-go/pkg/core/block"
+	"github.com/CityOfZion/neo-go/pkg/core/mpt"
+	"github.com/CityOfZion/neo-go/pkg/core/state"
 	"github.com/CityOfZion/neo-go/pkg/core/storage"
 	"github.com/CityOfZion/neo-go/pkg/core/transaction"
 	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
 	"github.com/CityOfZion/neo-go/pkg/io"
 	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm"
+	"github.com/CityOfZion/neo-go/pkg/vm/emit"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestAddHeaders(t *testing.T) {
@@ -69,6 +78,65 @@ func TestAddBlock(t *testing.T) {
 	assert.Equal(t, lastBlock.Hash(), bc.CurrentHeaderHash())
 }
 
+func TestAddBlockVerified(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	b := newBlock(1, newMinerTX())
+	b.Script.InvocationScript = []byte{byte(opcode.PUSHT)}
+	require.Error(t, bc.AddBlockVerified(b))
+
+	b = newBlock(2, newMinerTX())
+	require.Error(t, bc.AddBlockVerified(b))
+
+	newBlockPrevHash = bc.GetHeaderHash(0)
+	b = newBlock(1, newMinerTX())
+	require.NoError(t, bc.AddBlockVerified(b))
+	assert.Equal(t, uint32(1), bc.BlockHeight())
+	require.Error(t, bc.AddBlockVerified(b))
+}
+
+func TestHandleNEP5Transfer(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	sc := util.Uint160{1, 2, 3}
+	from := util.Uint160{4, 5, 6}
+	to := util.Uint160{7, 8, 9}
+	b := newBlock(1)
+	newTransfer := func(from, to []byte, amount vm.StackItem) *state.NotificationEvent {
+		return &state.NotificationEvent{
+			ScriptHash: sc,
+			Item: vm.NewArrayItem([]vm.StackItem{
+				vm.NewByteArrayItem([]byte("transfer")),
+				vm.NewByteArrayItem(from),
+				vm.NewByteArrayItem(to),
+				amount,
+			}),
+		}
+	}
+	cache := newCachedDao(bc.dao.store)
+	require.NoError(t, bc.handleNotification(newTransfer(nil, to.BytesBE(), vm.NewBigIntegerItem(10)), cache, b, util.Uint256{}))
+	require.NoError(t, bc.handleNotification(newTransfer(to.BytesBE(), from.BytesBE(), vm.NewBigIntegerItem(3)), cache, b, util.Uint256{}))
+	// Amounts not fitting into int64 are fine.
+	large := new(big.Int).Lsh(big.NewInt(1), 64)
+	require.NoError(t, bc.handleNotification(newTransfer(nil, to.BytesBE(), vm.NewByteArrayItem(emit.IntToBytes(large))), cache, b, util.Uint256{}))
+	// Malformed addresses and negative amounts are ignored.
+	require.NoError(t, bc.handleNotification(newTransfer([]byte{1, 2, 3}, to.BytesBE(), vm.NewBigIntegerItem(5)), cache, b, util.Uint256{}))
+	require.NoError(t, bc.handleNotification(newTransfer(from.BytesBE(), []byte{1}, vm.NewBigIntegerItem(5)), cache, b, util.Uint256{}))
+	require.NoError(t, bc.handleNotification(newTransfer(from.BytesBE(), to.BytesBE(), vm.NewBigIntegerItem(-5)), cache, b, util.Uint256{}))
+
+	bs, err := cache.GetNEP5Balances(to)
+	require.NoError(t, err)
+	require.Equal(t, "18446744073709551623", bs.Trackers[sc].Balance.String())
+	bs, err = cache.GetNEP5Balances(from)
+	require.NoError(t, err)
+	require.Equal(t, "3", bs.Trackers[sc].Balance.String())
+	bs, err = cache.GetNEP5Balances(util.Uint160{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(bs.Trackers))
+}
+
 func TestScriptFromWitness(t *testing.T) {
 	witness := &transaction.Witness{}
 	h := util.Uint160{1, 2, 3}
@@ -181,6 +249,33 @@ func TestGetTransaction(t *testing.T) {
 	}
 }
 
+func TestCalculateClaimable(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+
+	t.Run("within one interval", func(t *testing.T) {
+		gen, sys := bc.CalculateClaimable(util.Fixed8FromInt64(1), 10, 18)
+		require.Equal(t, util.Fixed8(8*8), gen)
+		require.Equal(t, util.Fixed8(0), sys)
+	})
+	t.Run("crossing decrement interval", func(t *testing.T) {
+		di := uint32(decrementInterval)
+		gen, _ := bc.CalculateClaimable(util.Fixed8FromInt64(2), di-2, di+3)
+		require.Equal(t, util.Fixed8(2*(2*8+3*7)), gen)
+	})
+	t.Run("after generation end", func(t *testing.T) {
+		start := uint32(len(genAmount) * decrementInterval)
+		gen, _ := bc.CalculateClaimable(util.Fixed8FromInt64(1), start, start+100)
+		require.Equal(t, util.Fixed8(0), gen)
+	})
+	t.Run("system fees", func(t *testing.T) {
+		gen, sys := bc.CalculateClaimable(util.Fixed8FromInt64(3), 0, 1)
+		require.Equal(t, util.Fixed8(3*8), gen)
+		fee := bc.GetSystemFeeAmount(bc.GetHeaderHash(0))
+		require.Equal(t, util.Fixed8(3*fee), sys)
+	})
+}
+
 func TestClose(t *testing.T) {
 	defer func() {
 		r := recover()
@@ -200,3 +295,334 @@ func TestClose(t *testing.T) {
 	// This should never be executed.
 	assert.Nil(t, t)
 }
+
+func TestSubscriptions(t *testing.T) {
+	// We use buffering here as a substitute for reader goroutines, events
+	// get queued up and we read them one by one here.
+	const chBufSize = 16
+	blockCh := make(chan *block.Block, chBufSize)
+	txCh := make(chan *transaction.Transaction, chBufSize)
+	notificationCh := make(chan *state.NotificationEvent, chBufSize)
+	executionCh := make(chan *state.AppExecResult, chBufSize)
+
+	bc := newTestChain(t)
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	bc.SubscribeForBlocks(blockCh)
+	bc.SubscribeForTransactions(txCh)
+	bc.SubscribeForNotifications(notificationCh)
+	bc.SubscribeForExecutions(executionCh)
+
+	blocks := makeBlocks(1)
+	require.NoError(t, bc.AddBlock(blocks[0]))
+	// Block is always the last event sent.
+	b := <-blockCh
+	assert.Empty(t, notificationCh)
+	assert.Empty(t, executionCh)
+
+	tx := <-txCh
+	assert.Equal(t, blocks[0], b)
+	assert.Equal(t, blocks[0].Transactions[0], tx)
+	assert.Empty(t, blockCh)
+	assert.Empty(t, txCh)
+
+	buf := io.NewBufBinWriter()
+	emit.String(buf.BinWriter, "yay!")
+	emit.Syscall(buf.BinWriter, "Neo.Runtime.Notify")
+	require.NoError(t, buf.Err)
+	okScript := buf.Bytes()
+
+	buf = io.NewBufBinWriter()
+	emit.String(buf.BinWriter, "nay!")
+	emit.Syscall(buf.BinWriter, "Neo.Runtime.Notify")
+	emit.Opcode(buf.BinWriter, opcode.THROW)
+	require.NoError(t, buf.Err)
+	failScript := buf.Bytes()
+
+	txGood1 := transaction.NewInvocationTX(okScript, 0)
+	txBad := transaction.NewInvocationTX(failScript, 0)
+	txGood2 := transaction.NewInvocationTX(okScript, 1)
+	invBlock := newBlock(bc.BlockHeight()+1, newMinerTX(), txGood1, txBad, txGood2)
+	require.NoError(t, bc.AddBlock(invBlock))
+
+	assert.Equal(t, invBlock, <-blockCh)
+	assert.Empty(t, blockCh)
+
+	for _, txExpected := range invBlock.Transactions {
+		tx := <-txCh
+		require.Equal(t, txExpected, tx)
+		if txExpected.Type != transaction.InvocationType {
+			continue
+		}
+		exec := <-executionCh
+		require.Equal(t, tx.Hash(), exec.TxHash)
+		if exec.VMState == "HALT" {
+			notif := <-notificationCh
+			inv := tx.Data.(*transaction.InvocationTX)
+			require.Equal(t, hash.Hash160(inv.Script), notif.ScriptHash)
+		}
+	}
+	assert.Empty(t, txCh)
+	assert.Empty(t, notificationCh)
+	assert.Empty(t, executionCh)
+
+	bc.UnsubscribeFromBlocks(blockCh)
+	bc.UnsubscribeFromTransactions(txCh)
+	bc.UnsubscribeFromNotifications(notificationCh)
+	bc.UnsubscribeFromExecutions(executionCh)
+
+	// Ensure that new blocks are processed correctly after unsubscription.
+	require.NoError(t, bc.AddBlock(newBlock(bc.BlockHeight()+1, newMinerTX())))
+	assert.Empty(t, blockCh)
+	assert.Empty(t, txCh)
+}
+
+func TestSubscriptionsClosedChain(t *testing.T) {
+	bc := newTestChain(t)
+	bc.Close()
+
+	blockCh := make(chan *block.Block)
+	done := make(chan struct{})
+	go func() {
+		bc.SubscribeForBlocks(blockCh)
+		bc.UnsubscribeFromBlocks(blockCh)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("subscription to a closed chain blocks")
+	}
+}
+
+func TestTraceTransaction(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	buf := io.NewBufBinWriter()
+	emit.String(buf.BinWriter, "yay!")
+	emit.Syscall(buf.BinWriter, "Neo.Runtime.Notify")
+	require.NoError(t, buf.Err)
+	okScript := buf.Bytes()
+
+	buf = io.NewBufBinWriter()
+	emit.String(buf.BinWriter, "nay!")
+	emit.Syscall(buf.BinWriter, "Neo.Runtime.Notify")
+	emit.Opcode(buf.BinWriter, opcode.THROW)
+	require.NoError(t, buf.Err)
+	failScript := buf.Bytes()
+
+	txGood := transaction.NewInvocationTX(okScript, 0)
+	txBad := transaction.NewInvocationTX(failScript, 0)
+	minerTx := newMinerTX()
+	require.NoError(t, bc.AddBlock(newBlock(bc.BlockHeight()+1, minerTx, txGood, txBad)))
+
+	for _, tx := range []*transaction.Transaction{txGood, txBad} {
+		expected, err := bc.GetAppExecResult(tx.Hash())
+		require.NoError(t, err)
+		aer, trace, err := bc.TraceTransaction(tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, expected.TxHash, aer.TxHash)
+		require.Equal(t, expected.VMState, aer.VMState)
+		require.Equal(t, expected.GasConsumed, aer.GasConsumed)
+		require.Equal(t, 1, len(aer.Events))
+		require.Equal(t, hash.Hash160(tx.Data.(*transaction.InvocationTX).Script), aer.Events[0].ScriptHash)
+
+		require.Equal(t, 3, len(trace))
+		require.Equal(t, "Neo.Runtime.Notify", trace[1].Syscall)
+	}
+	_, trace, err := bc.TraceTransaction(txBad.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "THROW", trace[len(trace)-1].Opcode)
+
+	_, _, err = bc.TraceTransaction(minerTx.Hash())
+	require.Error(t, err)
+	_, _, err = bc.TraceTransaction(util.Uint256{1, 2, 3})
+	require.Error(t, err)
+
+	// The transaction is traced against the state of its block.
+	script := []byte{byte(opcode.RET)}
+	key := []byte("key")
+	require.NoError(t, bc.AddBlock(newBlock(bc.BlockHeight()+1, newMinerTX(), newStoragePutTX(t, script, key, []byte("v1")))))
+	txRead := newStorageNotifyTX(t, script, key)
+	require.NoError(t, bc.AddBlock(newBlock(bc.BlockHeight()+1, newMinerTX(), newStoragePutTX(t, script, key, []byte("v2")), txRead)))
+	require.NoError(t, bc.AddBlock(newBlock(bc.BlockHeight()+1, newMinerTX(), newStoragePutTX(t, script, key, []byte("v3")))))
+	aer, _, err := bc.TraceTransaction(txRead.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "HALT", aer.VMState)
+	require.Equal(t, 1, len(aer.Events))
+	require.Equal(t, []byte("v2"), aer.Events[0].Item.Value())
+
+	// Undo data is required to restore the state.
+	require.NoError(t, bc.dao.store.Delete(undoKey(bc.BlockHeight())))
+	_, _, err = bc.TraceTransaction(txRead.Hash())
+	require.Error(t, err)
+
+	bc.config.MaxRollbackDepth = 0
+	_, _, err = bc.TraceTransaction(txGood.Hash())
+	require.Equal(t, ErrTracingDisabled, err)
+}
+
+func TestStateRoot(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	buf := io.NewBufBinWriter()
+	emit.String(buf.BinWriter, "yay!")
+	emit.Syscall(buf.BinWriter, "Neo.Runtime.Notify")
+	require.NoError(t, buf.Err)
+	tx := transaction.NewInvocationTX(buf.Bytes(), 0)
+	require.NoError(t, bc.AddBlock(newBlock(bc.BlockHeight()+1, newMinerTX(), tx)))
+
+	root, err := bc.GetStateRoot(bc.BlockHeight())
+	require.NoError(t, err)
+	_, err = bc.GetStateRoot(bc.BlockHeight() + 1)
+	require.Error(t, err)
+
+	// The root matches the one calculated from scratch for the current state.
+	tr := mpt.NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	kvs := make(map[string][]byte)
+	for _, p := range stateRootPrefixes {
+		bc.dao.store.Seek(p.Bytes(), func(k, v []byte) {
+			kvs[string(k)] = v
+		})
+	}
+	require.NotEqual(t, 0, len(kvs))
+	for k, v := range kvs {
+		require.NoError(t, tr.Put([]byte(k), v))
+	}
+	require.Equal(t, tr.StateRoot(), root)
+
+	for k, v := range kvs {
+		proof, err := bc.GetStateProof(root, []byte(k))
+		require.NoError(t, err)
+		val, ok := mpt.VerifyProof(root, []byte(k), proof)
+		require.True(t, ok)
+		require.Equal(t, v, val)
+	}
+	_, err = bc.GetStateProof(root, []byte{0xff})
+	require.Error(t, err)
+}
+
+func TestStateRootDisabled(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(cfg *config.ProtocolConfiguration) {
+		cfg.StateRootEnabled = false
+	})
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	script := []byte{byte(opcode.RET)}
+	key := []byte("key")
+	require.NoError(t, bc.AddBlock(newBlock(1, newMinerTX(), newStoragePutTX(t, script, key, []byte("v1")))))
+	_, err := bc.GetStateRoot(1)
+	require.Equal(t, ErrStateRootDisabled, err)
+	_, err = bc.GetStateProof(util.Uint256{}, key)
+	require.Equal(t, ErrStateRootDisabled, err)
+	bc.dao.store.Seek(storage.DataMPT.Bytes(), func(k, v []byte) {
+		t.Fatalf("unexpected trie node %x", k)
+	})
+
+	// The trie is built from scratch for the first block after state roots
+	// are enabled and then updated as usual.
+	bc.config.StateRootEnabled = true
+	_, err = bc.GetStateRoot(1)
+	require.Error(t, err)
+	for i := uint32(2); i <= 3; i++ {
+		value := []byte{byte(i)}
+		require.NoError(t, bc.AddBlock(newBlock(i, newMinerTX(), newStoragePutTX(t, script, key, value))))
+		root, err := bc.GetStateRoot(i)
+		require.NoError(t, err)
+		expected, err := rebuildStateTrie(storage.NewMemCachedStore(bc.dao.store))
+		require.NoError(t, err)
+		require.Equal(t, expected, root)
+	}
+}
+
+func TestArchivalMode(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(cfg *config.ProtocolConfiguration) {
+		cfg.ArchivalMode = true
+	})
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	script := []byte{byte(opcode.RET)}
+	h := hash.Hash160(script)
+	key := []byte("key")
+	require.NoError(t, bc.AddBlock(newBlock(1, newMinerTX(), newStoragePutTX(t, script, key, []byte("v1")))))
+	require.NoError(t, bc.AddBlock(newBlock(2, newMinerTX(), newStoragePutTX(t, script, key, []byte("v2")))))
+	require.NoError(t, bc.AddBlock(newBlock(3, newMinerTX())))
+
+	expected := [][]byte{nil, []byte("v1"), []byte("v2"), []byte("v2")}
+	for i, v := range expected {
+		si, err := bc.GetStorageItemAt(h, key, uint32(i))
+		require.NoError(t, err)
+		if v == nil {
+			require.Nil(t, si)
+			continue
+		}
+		require.NotNil(t, si)
+		require.Equal(t, v, si.Value)
+	}
+	require.Equal(t, []byte("v2"), bc.GetStorageItem(h, key).Value)
+
+	cs, err := bc.GetContractStateAt(h, 0)
+	require.NoError(t, err)
+	require.Nil(t, cs)
+	cs, err = bc.GetContractStateAt(h, 1)
+	require.NoError(t, err)
+	require.NotNil(t, cs)
+	require.Equal(t, script, cs.Script)
+
+	genesis, err := bc.GetBlock(bc.GetHeaderHash(0))
+	require.NoError(t, err)
+	var issue *transaction.Transaction
+	for _, tx := range genesis.Transactions {
+		if len(tx.Outputs) != 0 {
+			issue = tx
+			break
+		}
+	}
+	require.NotNil(t, issue)
+	as, err := bc.GetAccountStateAt(issue.Outputs[0].ScriptHash, 0)
+	require.NoError(t, err)
+	require.Equal(t, bc.GetAccountState(issue.Outputs[0].ScriptHash), as)
+	ucs, err := bc.GetUnspentCoinStateAt(issue.Hash(), 0)
+	require.NoError(t, err)
+	require.Equal(t, bc.GetUnspentCoinState(issue.Hash()), ucs)
+
+	_, err = bc.GetStorageItemAt(h, key, bc.BlockHeight()+1)
+	require.Error(t, err)
+
+	t.Run("disabled", func(t *testing.T) {
+		bc := newTestChain(t)
+		defer bc.Close()
+		_, err := bc.GetStorageItemAt(h, key, 0)
+		require.Equal(t, ErrNotArchival, err)
+	})
+
+	t.Run("mode mismatch", func(t *testing.T) {
+		cfg := unitTestNetCfg.ProtocolConfiguration
+		cfg.ArchivalMode = true
+		store := storage.NewMemoryStore()
+		chain, err := NewBlockchain(store, cfg, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		_, err = chain.dao.Persist()
+		require.NoError(t, err)
+		cfg.ArchivalMode = false
+		_, err = NewBlockchain(store, cfg, zaptest.NewLogger(t))
+		require.Error(t, err)
+	})
+}
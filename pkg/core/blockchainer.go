@@ -18,6 +18,7 @@ type Blockchainer interface {
 	GetConfig() config.ProtocolConfiguration
 	AddHeaders(...*block.Header) error
 	AddBlock(*block.Block) error
+	AddBlockVerified(*block.Block) error
 	BlockHeight() uint32
 	Close()
 	HeaderHeight() uint32
@@ -25,22 +26,44 @@ type Blockchainer interface {
 	GetContractState(hash util.Uint160) *state.Contract
 	GetHeaderHash(int) util.Uint256
 	GetHeader(hash util.Uint256) (*block.Header, error)
+	GetSystemFeeAmount(h util.Uint256) uint32
+	CalculateClaimable(value util.Fixed8, startHeight, endHeight uint32) (util.Fixed8, util.Fixed8)
 	CurrentHeaderHash() util.Uint256
 	CurrentBlockHash() util.Uint256
 	HasBlock(util.Uint256) bool
 	HasTransaction(util.Uint256) bool
 	GetAssetState(util.Uint256) *state.Asset
 	GetAccountState(util.Uint160) *state.Account
+	GetAppExecResult(util.Uint256) (*state.AppExecResult, error)
+	GetNEP5Balances(util.Uint160) *state.NEP5Balances
+	ForEachNEP5Transfer(util.Uint160, func(*state.NEP5Transfer) error) error
 	GetValidators(txes ...*transaction.Transaction) ([]*keys.PublicKey, error)
+	GetEnrollments() ([]*state.Validator, error)
+	GetAccountStateAt(util.Uint160, uint32) (*state.Account, error)
+	GetContractStateAt(util.Uint160, uint32) (*state.Contract, error)
+	GetStorageItemAt(util.Uint160, []byte, uint32) (*state.StorageItem, error)
+	GetUnspentCoinStateAt(util.Uint256, uint32) (*UnspentCoinState, error)
 	GetScriptHashesForVerifying(*transaction.Transaction) ([]util.Uint160, error)
+	GetStateProof(root util.Uint256, key []byte) ([][]byte, error)
+	GetStateRoot(index uint32) (util.Uint256, error)
 	GetStorageItem(scripthash util.Uint160, key []byte) *state.StorageItem
 	GetStorageItems(hash util.Uint160) (map[string]*state.StorageItem, error)
-	GetTestVM() (*vm.VM, storage.Store)
+	GetStorageItemsWithPrefix(hash util.Uint160, prefix []byte) (map[string]*state.StorageItem, error)
+	GetTestVM(trig byte, tx *transaction.Transaction) (*vm.VM, storage.Store)
 	GetTransaction(util.Uint256) (*transaction.Transaction, uint32, error)
 	GetUnspentCoinState(util.Uint256) *UnspentCoinState
 	References(t *transaction.Transaction) map[transaction.Input]*transaction.Output
+	TraceTransaction(util.Uint256) (*state.AppExecResult, []vm.TraceEntry, error)
 	mempool.Feer // fee interface
 	PoolTx(*transaction.Transaction) error
 	VerifyTx(*transaction.Transaction, *block.Block) error
 	GetMemPool() *mempool.Pool
+	SubscribeForBlocks(ch chan<- *block.Block)
+	SubscribeForExecutions(ch chan<- *state.AppExecResult)
+	SubscribeForNotifications(ch chan<- *state.NotificationEvent)
+	SubscribeForTransactions(ch chan<- *transaction.Transaction)
+	UnsubscribeFromBlocks(ch chan<- *block.Block)
+	UnsubscribeFromExecutions(ch chan<- *state.AppExecResult)
+	UnsubscribeFromNotifications(ch chan<- *state.NotificationEvent)
+	UnsubscribeFromTransactions(ch chan<- *transaction.Transaction)
 }
@@ -72,11 +72,20 @@ func (cd *cachedDao) DeleteContractState(hash util.Uint160) error {
 // Persist flushes all the changes made into the (supposedly) persistent
 // underlying store.
 func (cd *cachedDao) Persist() (int, error) {
+	if err := cd.flushAccounts(); err != nil {
+		return 0, err
+	}
+	return cd.dao.Persist()
+}
+
+// flushAccounts writes all the cached accounts into the underlying store
+// without persisting it.
+func (cd *cachedDao) flushAccounts() error {
 	for sc := range cd.accounts {
 		err := cd.dao.PutAccountState(cd.accounts[sc])
 		if err != nil {
-			return 0, err
+			return err
 		}
 	}
-	return cd.dao.Persist()
+	return nil
 }
@@ -79,6 +79,77 @@ func (dao *dao) PutAccountState(as *state.Account) error {
 
 // -- end accounts.
 
+// -- start NEP5 balances.
+
+// GetNEP5Balances retrieves NEP5 balances of the given account from the store
+// or returns an empty set of balances if there are none.
+func (dao *dao) GetNEP5Balances(acc util.Uint160) (*state.NEP5Balances, error) {
+	key := storage.AppendPrefix(storage.STNEP5Balances, acc.BytesBE())
+	bs := state.NewNEP5Balances()
+	err := dao.GetAndDecode(bs, key)
+	if err != nil && err != storage.ErrKeyNotFound {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// PutNEP5Balances puts given NEP5 balances into the store.
+func (dao *dao) PutNEP5Balances(acc util.Uint160, bs *state.NEP5Balances) error {
+	key := storage.AppendPrefix(storage.STNEP5Balances, acc.BytesBE())
+	return dao.Put(bs, key)
+}
+
+// -- end NEP5 balances.
+
+// -- start NEP5 transfer log.
+
+// nep5TransferLogKey returns the Store key of the transfer log batch of the
+// given account with the given number.
+func nep5TransferLogKey(acc util.Uint160, index uint32) []byte {
+	key := make([]byte, 1+util.Uint160Size+4)
+	key[0] = byte(storage.STNEP5Transfers)
+	copy(key[1:], acc.BytesBE())
+	binary.BigEndian.PutUint32(key[1+util.Uint160Size:], index)
+	return key
+}
+
+// GetNEP5TransferLog retrieves the NEP5 transfer log batch of the given
+// account with the given number from the store or returns an empty log if
+// there is none.
+func (dao *dao) GetNEP5TransferLog(acc util.Uint160, index uint32) (*state.NEP5TransferLog, error) {
+	value, err := dao.store.Get(nep5TransferLogKey(acc, index))
+	if err != nil {
+		if err == storage.ErrKeyNotFound {
+			return new(state.NEP5TransferLog), nil
+		}
+		return nil, err
+	}
+	return &state.NEP5TransferLog{Raw: value}, nil
+}
+
+// PutNEP5TransferLog puts given NEP5 transfer log batch into the store.
+func (dao *dao) PutNEP5TransferLog(acc util.Uint160, index uint32, lg *state.NEP5TransferLog) error {
+	return dao.store.Put(nep5TransferLogKey(acc, index), lg.Raw)
+}
+
+// AppendNEP5Transfer appends a single NEP5 transfer to the account log batch
+// with the given number. It returns true if the batch is full after that.
+func (dao *dao) AppendNEP5Transfer(acc util.Uint160, index uint32, tr *state.NEP5Transfer) (bool, error) {
+	lg, err := dao.GetNEP5TransferLog(acc, index)
+	if err != nil {
+		return false, err
+	}
+	if err := lg.Append(tr); err != nil {
+		return false, err
+	}
+	if err := dao.PutNEP5TransferLog(acc, index, lg); err != nil {
+		return false, err
+	}
+	return lg.Size() >= state.NEP5TransferBatchSize, nil
+}
+
+// -- end NEP5 transfer log.
+
 // -- start assets.
 
 // GetAssetState returns given asset state as recorded in the given store.
@@ -205,6 +276,12 @@ func (dao *dao) PutSpentCoinState(hash util.Uint256, scs *SpentCoinState) error
 	return dao.Put(scs, key)
 }
 
+// DeleteUnspentCoinState deletes given UnspentCoinState from the given store.
+func (dao *dao) DeleteUnspentCoinState(hash util.Uint256) error {
+	key := storage.AppendPrefix(storage.STCoin, hash.BytesLE())
+	return dao.store.Delete(key)
+}
+
 // DeleteSpentCoinState deletes given SpentCoinState from the given store.
 func (dao *dao) DeleteSpentCoinState(hash util.Uint256) error {
 	key := storage.AppendPrefix(storage.STSpentCoin, hash.BytesLE())
@@ -324,6 +401,12 @@ func (dao *dao) DeleteStorageItem(scripthash util.Uint160, key []byte) error {
 
 // GetStorageItems returns all storage items for a given scripthash.
 func (dao *dao) GetStorageItems(hash util.Uint160) (map[string]*state.StorageItem, error) {
+	return dao.GetStorageItemsWithPrefix(hash, nil)
+}
+
+// GetStorageItemsWithPrefix returns all storage items with given prefix for a
+// given scripthash.
+func (dao *dao) GetStorageItemsWithPrefix(hash util.Uint160, prefix []byte) (map[string]*state.StorageItem, error) {
 	var siMap = make(map[string]*state.StorageItem)
 	var err error
 
@@ -342,7 +425,7 @@ func (dao *dao) GetStorageItems(hash util.Uint160) (map[string]*state.StorageIte
 		// Cut prefix and hash.
 		siMap[string(k[21:])] = si
 	}
-	dao.store.Seek(storage.AppendPrefix(storage.STStorage, hash.BytesLE()), saveToMap)
+	dao.store.Seek(makeStorageItemKey(hash, prefix), saveToMap)
 	if err != nil {
 		return nil, err
 	}
@@ -356,20 +439,44 @@ func makeStorageItemKey(scripthash util.Uint160, key []byte) []byte {
 
 // -- end storage item.
 
+// -- start state root.
+
+// GetStateRoot returns the state root of the block with the given index.
+func (dao *dao) GetStateRoot(index uint32) (util.Uint256, error) {
+	var root util.Uint256
+	b, err := dao.store.Get(storage.AppendPrefixInt(storage.IXStateRoot, int(index)))
+	if err != nil {
+		return root, err
+	}
+	return util.Uint256DecodeBytesBE(b)
+}
+
+// PutStateRoot stores the state root of the block with the given index.
+func (dao *dao) PutStateRoot(index uint32, root util.Uint256) error {
+	return dao.store.Put(storage.AppendPrefixInt(storage.IXStateRoot, int(index)), root.BytesBE())
+}
+
+// -- end state root.
+
 // -- other.
 
-// GetBlock returns Block by the given hash if it exists in the store.
-func (dao *dao) GetBlock(hash util.Uint256) (*block.Block, error) {
+// GetBlock returns Block by the given hash if it exists in the store along
+// with the cumulative system fee amount of all blocks up to this one.
+func (dao *dao) GetBlock(hash util.Uint256) (*block.Block, uint32, error) {
 	key := storage.AppendPrefix(storage.DataBlock, hash.BytesLE())
 	b, err := dao.store.Get(key)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	if len(b) < 4 {
+		return nil, 0, fmt.Errorf("bad block data")
 	}
-	block, err := block.NewBlockFromTrimmedBytes(b)
+	sysFee := binary.LittleEndian.Uint32(b[:4])
+	block, err := block.NewBlockFromTrimmedBytes(b[4:])
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return block, err
+	return block, sysFee, err
 }
 
 // GetVersion attempts to get the current version stored in the
@@ -440,6 +547,10 @@ func (dao *dao) GetTransaction(hash util.Uint256) (*transaction.Transaction, uin
 	if err != nil {
 		return nil, 0, err
 	}
+	// Pruned transactions are stored as their heights only.
+	if len(b) == 4 {
+		return nil, 0, storage.ErrKeyNotFound
+	}
 	r := io.NewBinReaderFromBuf(b)
 
 	var height = r.ReadU32LE()
@@ -458,6 +569,24 @@ func (dao *dao) PutVersion(v string) error {
 	return dao.store.Put(storage.SYSVersion.Bytes(), []byte(v))
 }
 
+// IsArchival returns true if the underlying Store keeps historical state.
+func (dao *dao) IsArchival() bool {
+	_, err := dao.store.Get(storage.SYSArchivalMode.Bytes())
+	return err == nil
+}
+
+// PutArchival marks the underlying Store as keeping historical state.
+func (dao *dao) PutArchival() error {
+	return dao.store.Put(storage.SYSArchivalMode.Bytes(), []byte{1})
+}
+
+// IsImportingSnapshot returns true if the snapshot import into the underlying
+// Store is not finished.
+func (dao *dao) IsImportingSnapshot() bool {
+	_, err := dao.store.Get(storage.SYSSnapshotImport.Bytes())
+	return err == nil
+}
+
 // PutCurrentHeader stores current header.
 func (dao *dao) PutCurrentHeader(hashAndIndex []byte) error {
 	return dao.store.Put(storage.SYSCurrentHeader.Bytes(), hashAndIndex)
@@ -486,14 +615,14 @@ func (dao *dao) HasTransaction(hash util.Uint256) bool {
 	return false
 }
 
-// StoreAsBlock stores the given block as DataBlock.
+// StoreAsBlock stores the given block as DataBlock along with the cumulative
+// system fee amount of all blocks up to (and including) this one.
 func (dao *dao) StoreAsBlock(block *block.Block, sysFee uint32) error {
 	var (
 		key = storage.AppendPrefix(storage.DataBlock, block.Hash().BytesLE())
 		buf = io.NewBufBinWriter()
 	)
-	// sysFee needs to be handled somehow
-	//	buf.WriteU32LE(sysFee)
+	buf.WriteU32LE(sysFee)
 	b, err := block.Trim()
 	if err != nil {
 		return err
@@ -526,6 +655,16 @@ func (dao *dao) StoreAsTransaction(tx *transaction.Transaction, index uint32) er
 	return dao.store.Put(key, buf.Bytes())
 }
 
+// StoreAsPrunedTransaction replaces the transaction with the given hash by
+// the height it was stored at, so that HasTransaction still returns true for
+// it, but it can't be retrieved.
+func (dao *dao) StoreAsPrunedTransaction(hash util.Uint256, index uint32) error {
+	key := storage.AppendPrefix(storage.DataTransaction, hash.BytesLE())
+	buf := io.NewBufBinWriter()
+	buf.WriteU32LE(index)
+	return dao.store.Put(key, buf.Bytes())
+}
+
 // IsDoubleSpend verifies that the input transactions are not double spent.
 func (dao *dao) IsDoubleSpend(tx *transaction.Transaction) bool {
 	if len(tx.Inputs) == 0 {
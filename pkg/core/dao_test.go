@@ -1,6 +1,7 @@
 package core
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/CityOfZion/neo-go/pkg/core/block"
@@ -70,6 +71,45 @@ func TestPutAndGetAssetState(t *testing.T) {
 	require.Equal(t, assetState, gotAssetState)
 }
 
+func TestPutGetNEP5Balances(t *testing.T) {
+	dao := newDao(storage.NewMemoryStore())
+	acc := random.Uint160()
+	bs, err := dao.GetNEP5Balances(acc)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(bs.Trackers))
+
+	bs.Trackers[random.Uint160()] = state.NEP5Tracker{Balance: big.NewInt(42), LastUpdatedBlock: 7}
+	require.NoError(t, dao.PutNEP5Balances(acc, bs))
+	gotBalances, err := dao.GetNEP5Balances(acc)
+	require.NoError(t, err)
+	require.Equal(t, bs, gotBalances)
+}
+
+func TestAppendGetNEP5TransferLog(t *testing.T) {
+	dao := newDao(storage.NewMemoryStore())
+	acc := random.Uint160()
+	lg, err := dao.GetNEP5TransferLog(acc, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, lg.Size())
+
+	tr := &state.NEP5Transfer{Asset: random.Uint160(), To: acc, Amount: big.NewInt(10), Block: 3, Tx: random.Uint256()}
+	for i := 1; i <= state.NEP5TransferBatchSize; i++ {
+		full, err := dao.AppendNEP5Transfer(acc, 0, tr)
+		require.NoError(t, err)
+		require.Equal(t, i == state.NEP5TransferBatchSize, full)
+	}
+	full, err := dao.AppendNEP5Transfer(acc, 1, tr)
+	require.NoError(t, err)
+	require.False(t, full)
+
+	lg, err = dao.GetNEP5TransferLog(acc, 0)
+	require.NoError(t, err)
+	require.Equal(t, state.NEP5TransferBatchSize, lg.Size())
+	lg, err = dao.GetNEP5TransferLog(acc, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, lg.Size())
+}
+
 func TestPutAndGetContractState(t *testing.T) {
 	dao := newDao(storage.NewMemoryStore())
 	contractState := &state.Contract{Script: []byte{}, ParamList: []smartcontract.ParamType{}}
@@ -238,6 +278,31 @@ func TestPutGetStorageItem(t *testing.T) {
 	require.Equal(t, storageItem, gotStorageItem)
 }
 
+func TestGetStorageItemsWithPrefix(t *testing.T) {
+	dao := newDao(storage.NewMemoryStore())
+	hash := random.Uint160()
+	items := map[string]*state.StorageItem{
+		"\x01\x01": {Value: []byte{1}},
+		"\x01\x02": {Value: []byte{2}},
+		"\x02\x01": {Value: []byte{3}},
+	}
+	for k, si := range items {
+		require.NoError(t, dao.PutStorageItem(hash, []byte(k), si))
+	}
+	require.NoError(t, dao.PutStorageItem(random.Uint160(), []byte{1, 3}, &state.StorageItem{Value: []byte{4}}))
+
+	all, err := dao.GetStorageItems(hash)
+	require.NoError(t, err)
+	require.Equal(t, items, all)
+
+	found, err := dao.GetStorageItemsWithPrefix(hash, []byte{1})
+	require.NoError(t, err)
+	require.Equal(t, map[string]*state.StorageItem{
+		"\x01\x01": items["\x01\x01"],
+		"\x01\x02": items["\x01\x02"],
+	}, found)
+}
+
 func TestDeleteStorageItem(t *testing.T) {
 	dao := newDao(storage.NewMemoryStore())
 	hash := random.Uint160()
@@ -254,7 +319,7 @@ func TestDeleteStorageItem(t *testing.T) {
 func TestGetBlock_NotExists(t *testing.T) {
 	dao := newDao(storage.NewMemoryStore())
 	hash := random.Uint256()
-	block, err := dao.GetBlock(hash)
+	block, _, err := dao.GetBlock(hash)
 	require.Error(t, err)
 	require.Nil(t, block)
 }
@@ -270,11 +335,12 @@ func TestPutGetBlock(t *testing.T) {
 		},
 	}
 	hash := b.Hash()
-	err := dao.StoreAsBlock(b, 0)
+	err := dao.StoreAsBlock(b, 42)
 	require.NoError(t, err)
-	gotBlock, err := dao.GetBlock(hash)
+	gotBlock, sysFee, err := dao.GetBlock(hash)
 	require.NoError(t, err)
 	require.NotNil(t, gotBlock)
+	require.EqualValues(t, 42, sysFee)
 }
 
 func TestGetVersion_NoVersion(t *testing.T) {
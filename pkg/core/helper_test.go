@@ -17,6 +17,7 @@ import (
 	"github.com/CityOfZion/neo-go/pkg/io"
 	"github.com/CityOfZion/neo-go/pkg/smartcontract"
 	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm/emit"
 	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -35,11 +36,20 @@ var privNetKeys = []string{
 // newTestChain should be called before newBlock invocation to properly setup
 // global state.
 func newTestChain(t *testing.T) *Blockchain {
+	return newTestChainWithCustomCfg(t, nil)
+}
+
+// newTestChainWithCustomCfg is the same as newTestChain, but allows to modify
+// the protocol configuration used.
+func newTestChainWithCustomCfg(t *testing.T, f func(*config.ProtocolConfiguration)) *Blockchain {
 	var err error
 	unitTestNetCfg, err = config.Load("../../config", config.ModeUnitTestNet)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if f != nil {
+		f(&unitTestNetCfg.ProtocolConfiguration)
+	}
 	chain, err := NewBlockchain(storage.NewMemoryStore(), unitTestNetCfg.ProtocolConfiguration, zaptest.NewLogger(t))
 	if err != nil {
 		t.Fatal(err)
@@ -109,6 +119,48 @@ func newMinerTX() *transaction.Transaction {
 	}
 }
 
+// newStoragePutTX returns an invocation transaction that creates a contract
+// with the given script (if it doesn't exist) and puts the key-value pair
+// into its storage. It's not signed.
+func newStoragePutTX(t *testing.T, script, key, value []byte) *transaction.Transaction {
+	buf := io.NewBufBinWriter()
+	emit.Bytes(buf.BinWriter, value)
+	emit.Bytes(buf.BinWriter, key)
+	emitStorageContext(buf.BinWriter, script)
+	emit.Syscall(buf.BinWriter, "Neo.Storage.Put")
+	require.NoError(t, buf.Err)
+	return transaction.NewInvocationTX(buf.Bytes(), 0)
+}
+
+// newStorageNotifyTX returns an invocation transaction that creates a contract
+// with the given script (if it doesn't exist) and sends a notification with the
+// value of the given key from its storage. It's not signed.
+func newStorageNotifyTX(t *testing.T, script, key []byte) *transaction.Transaction {
+	buf := io.NewBufBinWriter()
+	emit.Bytes(buf.BinWriter, key)
+	emitStorageContext(buf.BinWriter, script)
+	emit.Syscall(buf.BinWriter, "Neo.Storage.Get")
+	emit.Syscall(buf.BinWriter, "Neo.Runtime.Notify")
+	require.NoError(t, buf.Err)
+	return transaction.NewInvocationTX(buf.Bytes(), 0)
+}
+
+// emitStorageContext emits the code that creates the contract with the given
+// script (if it doesn't exist) and pushes its storage context.
+func emitStorageContext(w *io.BinWriter, script []byte) {
+	emit.String(w, "desc")
+	emit.String(w, "email")
+	emit.String(w, "author")
+	emit.String(w, "v1.0")
+	emit.String(w, "name")
+	emit.Int(w, int64(smartcontract.HasStorage))
+	emit.Int(w, int64(smartcontract.BoolType))
+	emit.Bytes(w, []byte{})
+	emit.Bytes(w, script)
+	emit.Syscall(w, "Neo.Contract.Create")
+	emit.Syscall(w, "Neo.Contract.GetStorageContext")
+}
+
 func getDecodedBlock(t *testing.T, i int) *block.Block {
 	data, err := getBlockData(i)
 	if err != nil {
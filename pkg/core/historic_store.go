@@ -0,0 +1,179 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+)
+
+// historyPrefixes are prefixes of the Store keys versioned in archival mode.
+var historyPrefixes = []storage.KeyPrefix{storage.STAccount, storage.STCoin, storage.STContract, storage.STStorage}
+
+// errHistoricStoreReadOnly is returned on attempts to change historic state.
+var errHistoricStoreReadOnly = errors.New("historic state is read-only")
+
+// errBadVersion is returned for malformed key versions.
+var errBadVersion = errors.New("bad key version")
+
+// historicStore is a read-only Store that returns the state as it was after
+// processing the block with the given index. It only works for the keys with
+// historyPrefixes and requires the underlying Store to be archival.
+//
+// Versions of every key are numbered in the order they were made and stored
+// along with the index of the block that made them, so that the version for
+// the given block can be found with binary search by a few Get calls.
+type historicStore struct {
+	store storage.Store
+	index uint32
+}
+
+// historicBatch is a Batch of historicStore, it can't be applied.
+type historicBatch struct{}
+
+// versionCountKey returns the Store key of the number of versions the key has.
+func versionCountKey(key []byte) []byte {
+	return storage.AppendPrefix(storage.IXStateHistory, key)
+}
+
+// versionKey returns the Store key of the key's version with the given number.
+func versionKey(key []byte, n uint32) []byte {
+	k := make([]byte, 1+len(key)+4)
+	k[0] = byte(storage.IXStateVersion)
+	copy(k[1:], key)
+	binary.BigEndian.PutUint32(k[1+len(key):], n)
+	return k
+}
+
+// getVersionCount returns the number of versions the key has.
+func getVersionCount(s storage.Store, key []byte) (uint32, error) {
+	b, err := s.Get(versionCountKey(key))
+	if err == storage.ErrKeyNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if len(b) != 4 {
+		return 0, errBadVersion
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// getVersion returns the index of the block that made the key's version with
+// the given number and the value of this version.
+func getVersion(s storage.Store, key []byte, n uint32) (uint32, []byte, error) {
+	b, err := s.Get(versionKey(key, n))
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(b) < 4 {
+		return 0, nil, errBadVersion
+	}
+	return binary.BigEndian.Uint32(b), b[4:], nil
+}
+
+// putVersion saves the value the key has after processing the block with the
+// given index as its latest version, empty value means the key is deleted.
+func putVersion(s storage.Store, key []byte, index uint32, value []byte) error {
+	n, err := getVersionCount(s, key)
+	if err != nil {
+		return err
+	}
+	v := make([]byte, 4+len(value))
+	binary.BigEndian.PutUint32(v, index)
+	copy(v[4:], value)
+	if err := s.Put(versionKey(key, n), v); err != nil {
+		return err
+	}
+	count := make([]byte, 4)
+	binary.BigEndian.PutUint32(count, n+1)
+	return s.Put(versionCountKey(key), count)
+}
+
+// get returns the value the key had after processing s.index block, empty
+// value means there was no such key.
+func (s *historicStore) get(key []byte) ([]byte, error) {
+	count, err := getVersionCount(s.store, key)
+	if err != nil {
+		return nil, err
+	}
+	var value []byte
+	// Look for the first version made after s.index block, the one before it
+	// is the one needed.
+	lo, hi := uint32(0), count
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		index, v, err := getVersion(s.store, key, mid)
+		if err != nil {
+			return nil, err
+		}
+		if index <= s.index {
+			lo = mid + 1
+			value = v
+		} else {
+			hi = mid
+		}
+	}
+	return value, nil
+}
+
+// Get implements the Store interface.
+func (s *historicStore) Get(key []byte) ([]byte, error) {
+	val, err := s.get(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, storage.ErrKeyNotFound
+	}
+	return val, nil
+}
+
+// Seek implements the Store interface.
+func (s *historicStore) Seek(key []byte, f func(k, v []byte)) {
+	// Keys are collected first, because the Store can't be read while
+	// seeking over it.
+	var keys [][]byte
+	s.store.Seek(versionCountKey(key), func(k, _ []byte) {
+		key := make([]byte, len(k)-1)
+		copy(key, k[1:])
+		keys = append(keys, key)
+	})
+	for _, k := range keys {
+		v, err := s.get(k)
+		if err == nil && len(v) != 0 {
+			f(k, v)
+		}
+	}
+}
+
+// Batch implements the Store interface, the Batch returned can't be applied.
+func (s *historicStore) Batch() storage.Batch {
+	return historicBatch{}
+}
+
+// Put implements the Store interface, it always returns an error.
+func (s *historicStore) Put(k, v []byte) error {
+	return errHistoricStoreReadOnly
+}
+
+// PutBatch implements the Store interface, it always returns an error.
+func (s *historicStore) PutBatch(storage.Batch) error {
+	return errHistoricStoreReadOnly
+}
+
+// Delete implements the Store interface, it always returns an error.
+func (s *historicStore) Delete(k []byte) error {
+	return errHistoricStoreReadOnly
+}
+
+// Close implements the Store interface, the underlying Store is not closed.
+func (s *historicStore) Close() error {
+	return nil
+}
+
+// Put implements the Batch interface, the change is ignored.
+func (historicBatch) Put(k, v []byte) {}
+
+// Delete implements the Batch interface, the change is ignored.
+func (historicBatch) Delete(k []byte) {}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoricStore(t *testing.T) {
+	ms := storage.NewMemoryStore()
+	put := func(key string, index uint32, value string) {
+		require.NoError(t, putVersion(ms, []byte(key), index, []byte(value)))
+	}
+	// "ab" is a prefix of "abc" and "ab\x00\x00\x00\x01" could be confused
+	// with a version of "ab" if versions were stored with the same prefix as
+	// keys.
+	put("ab", 1, "1")
+	put("ab", 3, "3")
+	put("ab", 5, "")
+	put("ab", 7, "7")
+	put("abc", 2, "2")
+	put("ab\x00\x00\x00\x01", 4, "4")
+	count, err := getVersionCount(ms, []byte("ab"))
+	require.NoError(t, err)
+	require.Equal(t, uint32(4), count)
+
+	get := func(index uint32, key string) string {
+		v, err := (&historicStore{store: ms, index: index}).Get([]byte(key))
+		if err != nil {
+			require.Equal(t, storage.ErrKeyNotFound, err)
+			return "<nil>"
+		}
+		return string(v)
+	}
+	expected := []string{"<nil>", "1", "1", "3", "3", "<nil>", "<nil>", "7", "7"}
+	for i, v := range expected {
+		require.Equal(t, v, get(uint32(i), "ab"), "block %d", i)
+	}
+	require.Equal(t, "<nil>", get(1, "abc"))
+	require.Equal(t, "2", get(5, "abc"))
+	require.Equal(t, "<nil>", get(3, "ab\x00\x00\x00\x01"))
+	require.Equal(t, "4", get(4, "ab\x00\x00\x00\x01"))
+	require.Equal(t, "<nil>", get(4, "a"))
+
+	found := make(map[string]string)
+	(&historicStore{store: ms, index: 4}).Seek([]byte("ab"), func(k, v []byte) {
+		found[string(k)] = string(v)
+	})
+	require.Equal(t, map[string]string{"ab": "3", "abc": "2", "ab\x00\x00\x00\x01": "4"}, found)
+
+	hs := &historicStore{store: ms, index: 4}
+	b := hs.Batch()
+	b.Put([]byte("ab"), []byte("x"))
+	require.Error(t, hs.PutBatch(b))
+	require.Error(t, hs.Put([]byte("ab"), []byte("x")))
+	require.Error(t, hs.Delete([]byte("ab")))
+	require.Equal(t, "3", get(4, "ab"))
+	count, err = getVersionCount(ms, []byte("ab"))
+	require.NoError(t, err)
+	require.Equal(t, uint32(4), count)
+}
@@ -0,0 +1,338 @@
+package mpt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+// nodeType is a type of the trie node, it's the first byte of serialized
+// node.
+type nodeType byte
+
+const (
+	branchT    nodeType = 0x00
+	extensionT nodeType = 0x01
+	leafT      nodeType = 0x02
+	hashT      nodeType = 0x03
+	emptyT     nodeType = 0x04
+)
+
+const (
+	// childrenCount is the number of children of a branch node, the last
+	// one is used for the value of a key ending in this node.
+	childrenCount = 17
+	// lastChild is the index of the value child of a branch node.
+	lastChild = childrenCount - 1
+	// MaxKeyLength is the maximum length of a key (in bytes).
+	MaxKeyLength = 1024 + 32
+	// MaxValueLength is the maximum length of a value.
+	MaxValueLength = 4 * 1024 * 1024
+)
+
+type (
+	// node is a trie node. Nodes are referenced by their hashes, so that
+	// every node except the empty one is stored separately.
+	node interface {
+		io.Serializable
+		// Hash returns a hash of the serialized node.
+		Hash() util.Uint256
+		// Bytes returns serialized node.
+		Bytes() []byte
+		nodeType() nodeType
+	}
+
+	// cache holds the serialized form of the node and its hash, it's
+	// invalidated on every node modification.
+	cache struct {
+		valid bool
+		bytes []byte
+		hash  util.Uint256
+	}
+
+	// branchNode is a node with 16 children (one for each nibble) and the
+	// value child.
+	branchNode struct {
+		cache
+		children [childrenCount]node
+	}
+
+	// extensionNode is a node with a common path part for all of the keys
+	// in its subtrie.
+	extensionNode struct {
+		cache
+		key  []byte // nibbles
+		next node
+	}
+
+	// leafNode is a node holding a value.
+	leafNode struct {
+		cache
+		value []byte
+	}
+
+	// hashNode is a reference to the node stored in the Store that is not
+	// yet loaded.
+	hashNode struct {
+		hash util.Uint256
+	}
+
+	// emptyNode is an absent node.
+	emptyNode struct{}
+)
+
+var (
+	_ node = (*branchNode)(nil)
+	_ node = (*extensionNode)(nil)
+	_ node = (*leafNode)(nil)
+	_ node = (*hashNode)(nil)
+	_ node = emptyNode{}
+)
+
+// errEmptyNode is returned when trying to serialize or hash the empty node.
+var errEmptyNode = errors.New("empty node")
+
+func newBranchNode() *branchNode {
+	b := new(branchNode)
+	for i := range b.children {
+		b.children[i] = emptyNode{}
+	}
+	return b
+}
+
+func newExtensionNode(key []byte, next node) *extensionNode {
+	return &extensionNode{key: key, next: next}
+}
+
+func newLeafNode(value []byte) *leafNode {
+	return &leafNode{value: value}
+}
+
+func newHashNode(h util.Uint256) *hashNode {
+	return &hashNode{hash: h}
+}
+
+// invalidate drops cached serialized node and hash.
+func (c *cache) invalidate() {
+	c.valid = false
+}
+
+// get returns cached serialized node and hash updating them if needed.
+func (c *cache) get(n io.Serializable) ([]byte, util.Uint256) {
+	if !c.valid {
+		w := io.NewBufBinWriter()
+		n.EncodeBinary(w.BinWriter)
+		if w.Err != nil {
+			panic(w.Err)
+		}
+		c.bytes = w.Bytes()
+		c.hash = hash.DoubleSha256(c.bytes)
+		c.valid = true
+	}
+	return c.bytes, c.hash
+}
+
+// Hash implements node interface.
+func (b *branchNode) Hash() util.Uint256 {
+	_, h := b.get(b)
+	return h
+}
+
+// Bytes implements node interface.
+func (b *branchNode) Bytes() []byte {
+	bs, _ := b.get(b)
+	return bs
+}
+
+func (b *branchNode) nodeType() nodeType { return branchT }
+
+// EncodeBinary implements io.Serializable interface.
+func (b *branchNode) EncodeBinary(w *io.BinWriter) {
+	w.WriteB(byte(branchT))
+	for i := range b.children {
+		encodeRef(w, b.children[i])
+	}
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (b *branchNode) DecodeBinary(r *io.BinReader) {
+	for i := range b.children {
+		b.children[i] = decodeRef(r)
+	}
+}
+
+// Hash implements node interface.
+func (e *extensionNode) Hash() util.Uint256 {
+	_, h := e.get(e)
+	return h
+}
+
+// Bytes implements node interface.
+func (e *extensionNode) Bytes() []byte {
+	bs, _ := e.get(e)
+	return bs
+}
+
+func (e *extensionNode) nodeType() nodeType { return extensionT }
+
+// EncodeBinary implements io.Serializable interface.
+func (e *extensionNode) EncodeBinary(w *io.BinWriter) {
+	w.WriteB(byte(extensionT))
+	w.WriteVarBytes(e.key)
+	encodeRef(w, e.next)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (e *extensionNode) DecodeBinary(r *io.BinReader) {
+	e.key = readVarBytes(r, 2*MaxKeyLength)
+	if r.Err == nil && len(e.key) == 0 {
+		r.Err = errors.New("empty extension key")
+	}
+	for _, n := range e.key {
+		if n > 0xf {
+			r.Err = fmt.Errorf("invalid nibble %d", n)
+		}
+	}
+	e.next = decodeRef(r)
+}
+
+// Hash implements node interface.
+func (l *leafNode) Hash() util.Uint256 {
+	_, h := l.get(l)
+	return h
+}
+
+// Bytes implements node interface.
+func (l *leafNode) Bytes() []byte {
+	bs, _ := l.get(l)
+	return bs
+}
+
+func (l *leafNode) nodeType() nodeType { return leafT }
+
+// EncodeBinary implements io.Serializable interface.
+func (l *leafNode) EncodeBinary(w *io.BinWriter) {
+	w.WriteB(byte(leafT))
+	w.WriteVarBytes(l.value)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (l *leafNode) DecodeBinary(r *io.BinReader) {
+	l.value = readVarBytes(r, MaxValueLength)
+}
+
+// Hash implements node interface.
+func (h *hashNode) Hash() util.Uint256 {
+	return h.hash
+}
+
+// Bytes implements node interface, hash nodes are never serialized on their
+// own.
+func (h *hashNode) Bytes() []byte {
+	panic("hash node can't be serialized")
+}
+
+func (h *hashNode) nodeType() nodeType { return hashT }
+
+// EncodeBinary implements io.Serializable interface.
+func (h *hashNode) EncodeBinary(w *io.BinWriter) {
+	w.Err = errors.New("hash node can't be serialized")
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (h *hashNode) DecodeBinary(r *io.BinReader) {
+	r.Err = errors.New("hash node can't be deserialized")
+}
+
+// Hash implements node interface.
+func (emptyNode) Hash() util.Uint256 {
+	panic(errEmptyNode)
+}
+
+// Bytes implements node interface.
+func (emptyNode) Bytes() []byte {
+	panic(errEmptyNode)
+}
+
+func (emptyNode) nodeType() nodeType { return emptyT }
+
+// EncodeBinary implements io.Serializable interface.
+func (emptyNode) EncodeBinary(w *io.BinWriter) {
+	w.Err = errEmptyNode
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (emptyNode) DecodeBinary(r *io.BinReader) {
+	r.Err = errEmptyNode
+}
+
+// encodeRef writes a reference to the node (its hash) used in parent nodes.
+func encodeRef(w *io.BinWriter, n node) {
+	if n.nodeType() == emptyT {
+		w.WriteB(byte(emptyT))
+		return
+	}
+	w.WriteB(byte(hashT))
+	h := n.Hash()
+	w.WriteBytes(h[:])
+}
+
+// decodeRef reads a reference to the node written by encodeRef.
+func decodeRef(r *io.BinReader) node {
+	switch t := nodeType(r.ReadB()); t {
+	case emptyT:
+		return emptyNode{}
+	case hashT:
+		var h util.Uint256
+		r.ReadBytes(h[:])
+		return newHashNode(h)
+	default:
+		if r.Err == nil {
+			r.Err = fmt.Errorf("invalid node reference type %d", t)
+		}
+		return emptyNode{}
+	}
+}
+
+// readVarBytes reads variable-length byte slice checking its length against
+// the given limit.
+func readVarBytes(r *io.BinReader, max int) []byte {
+	n := r.ReadVarUint()
+	if r.Err != nil {
+		return nil
+	}
+	if n > uint64(max) {
+		r.Err = fmt.Errorf("byte slice is too long: %d", n)
+		return nil
+	}
+	b := make([]byte, n)
+	r.ReadBytes(b)
+	return b
+}
+
+// decodeNode decodes a node from its serialized form.
+func decodeNode(data []byte) (node, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty node data")
+	}
+	var n node
+	switch t := nodeType(data[0]); t {
+	case branchT:
+		n = newBranchNode()
+	case extensionT:
+		n = new(extensionNode)
+	case leafT:
+		n = new(leafNode)
+	default:
+		return nil, fmt.Errorf("invalid node type %d", t)
+	}
+	r := io.NewBinReaderFromBuf(data[1:])
+	n.DecodeBinary(r)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return n, nil
+}
@@ -0,0 +1,76 @@
+package mpt
+
+import (
+	"bytes"
+
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+// GetProof returns a proof of the value stored under the given key, it
+// consists of serialized nodes on the path from the root to the value.
+func (t *Trie) GetProof(key []byte) ([][]byte, error) {
+	if len(key) > MaxKeyLength {
+		return nil, ErrNotFound
+	}
+	var proof [][]byte
+	r, err := t.getProof(t.root, toNibbles(key), &proof)
+	if err != nil {
+		return nil, err
+	}
+	t.root = r
+	return proof, nil
+}
+
+func (t *Trie) getProof(curr node, path []byte, proof *[][]byte) (node, error) {
+	switch n := curr.(type) {
+	case *leafNode:
+		if len(path) == 0 {
+			*proof = append(*proof, copySlice(n.Bytes()))
+			return n, nil
+		}
+	case *branchNode:
+		i, p := splitPath(path)
+		r, err := t.getProof(n.children[i], p, proof)
+		if err != nil {
+			return nil, err
+		}
+		n.children[i] = r
+		*proof = append([][]byte{copySlice(n.Bytes())}, *proof...)
+		return n, nil
+	case *extensionNode:
+		if bytes.HasPrefix(path, n.key) {
+			r, err := t.getProof(n.next, path[len(n.key):], proof)
+			if err != nil {
+				return nil, err
+			}
+			n.next = r
+			*proof = append([][]byte{copySlice(n.Bytes())}, *proof...)
+			return n, nil
+		}
+	case *hashNode:
+		r, err := t.getFromStore(n.hash)
+		if err != nil {
+			return nil, err
+		}
+		return t.getProof(r, path, proof)
+	}
+	return nil, ErrNotFound
+}
+
+// VerifyProof checks the proof of the value stored under the given key in
+// the trie with the given root and returns the value if the proof is valid.
+func VerifyProof(root util.Uint256, key []byte, proof [][]byte) ([]byte, bool) {
+	store := storage.NewMemoryStore()
+	for i := range proof {
+		h := hash.DoubleSha256(proof[i])
+		// It's an in-memory store, Put never fails.
+		_ = store.Put(makeStorageKey(h), proof[i])
+	}
+	v, err := NewTrie(root, store).Get(key)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
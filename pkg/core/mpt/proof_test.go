@@ -0,0 +1,49 @@
+package mpt
+
+import (
+	"testing"
+
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProof(t *testing.T) {
+	tr := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	fillTrie(t, tr, []int{0, 1, 2, 3, 4, 5, 6})
+	require.NoError(t, tr.Flush())
+	root := tr.StateRoot()
+
+	for _, p := range testPairs {
+		proof, err := tr.GetProof(p.key)
+		require.NoError(t, err)
+		v, ok := VerifyProof(root, p.key, proof)
+		require.True(t, ok)
+		assert.Equal(t, p.value, v)
+
+		_, ok = VerifyProof(util.Uint256{1, 2, 3}, p.key, proof)
+		assert.False(t, ok)
+	}
+
+	_, err := tr.GetProof([]byte{0xab})
+	assert.Equal(t, ErrNotFound, err)
+
+	t.Run("tampered", func(t *testing.T) {
+		key := testPairs[2].key
+		proof, err := tr.GetProof(key)
+		require.NoError(t, err)
+		leaf := proof[len(proof)-1]
+		leaf[len(leaf)-1]++
+		_, ok := VerifyProof(root, key, proof)
+		assert.False(t, ok)
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		key := testPairs[5].key
+		proof, err := tr.GetProof(key)
+		require.NoError(t, err)
+		_, ok := VerifyProof(root, key, proof[:len(proof)-1])
+		assert.False(t, ok)
+	})
+}
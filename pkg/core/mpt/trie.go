@@ -0,0 +1,381 @@
+package mpt
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+// Trie is a Merkle Patricia Trie. Its nodes are stored in the Store with
+// storage.DataMPT prefix and are loaded on demand, changes are kept in
+// memory until Flush is called. Nodes are never removed from the Store, so
+// any previous state of the trie can be accessed using its root hash. It's
+// not safe for concurrent use.
+type Trie struct {
+	store storage.Store
+	root  node
+}
+
+// ErrNotFound is returned when the requested key is missing in the trie.
+var ErrNotFound = errors.New("item not found")
+
+// NewTrie returns a trie with the given root hash using the Store given,
+// zero hash means an empty trie.
+func NewTrie(root util.Uint256, store storage.Store) *Trie {
+	var r node = emptyNode{}
+	if !root.Equals(util.Uint256{}) {
+		r = newHashNode(root)
+	}
+	return &Trie{
+		store: store,
+		root:  r,
+	}
+}
+
+// StateRoot returns the root hash of the trie, it's a zero hash for an
+// empty trie.
+func (t *Trie) StateRoot() util.Uint256 {
+	if t.root.nodeType() == emptyT {
+		return util.Uint256{}
+	}
+	return t.root.Hash()
+}
+
+// Get returns the value stored under the given key.
+func (t *Trie) Get(key []byte) ([]byte, error) {
+	if len(key) > MaxKeyLength {
+		return nil, errors.New("key is too big")
+	}
+	path := toNibbles(key)
+	r, v, err := t.getWithPath(t.root, path)
+	if err != nil {
+		return nil, err
+	}
+	t.root = r
+	return v, nil
+}
+
+// getWithPath returns the value stored under the given path in the subtrie
+// along with the subtrie (with hash nodes on the path resolved).
+func (t *Trie) getWithPath(curr node, path []byte) (node, []byte, error) {
+	switch n := curr.(type) {
+	case *leafNode:
+		if len(path) == 0 {
+			return curr, copySlice(n.value), nil
+		}
+	case *branchNode:
+		i, p := splitPath(path)
+		r, v, err := t.getWithPath(n.children[i], p)
+		if err != nil {
+			return nil, nil, err
+		}
+		n.children[i] = r
+		return n, v, nil
+	case *extensionNode:
+		if bytes.HasPrefix(path, n.key) {
+			r, v, err := t.getWithPath(n.next, path[len(n.key):])
+			if err != nil {
+				return nil, nil, err
+			}
+			n.next = r
+			return n, v, nil
+		}
+	case *hashNode:
+		r, err := t.getFromStore(n.hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		return t.getWithPath(r, path)
+	}
+	return nil, nil, ErrNotFound
+}
+
+// Put puts the value under the given key into the trie. Values can't be
+// empty.
+func (t *Trie) Put(key, value []byte) error {
+	if len(key) > MaxKeyLength {
+		return errors.New("key is too big")
+	}
+	if len(value) == 0 {
+		return errors.New("value is empty")
+	}
+	if len(value) > MaxValueLength {
+		return errors.New("value is too big")
+	}
+	r, err := t.putIntoNode(t.root, toNibbles(key), newLeafNode(copySlice(value)))
+	if err != nil {
+		return err
+	}
+	t.root = r
+	return nil
+}
+
+// putIntoNode puts the value under the given path into the subtrie and
+// returns the new subtrie.
+func (t *Trie) putIntoNode(curr node, path []byte, val node) (node, error) {
+	switch n := curr.(type) {
+	case *leafNode:
+		if len(path) == 0 {
+			return val, nil
+		}
+		b := newBranchNode()
+		b.children[lastChild] = n
+		return t.putIntoBranch(b, path, val)
+	case *branchNode:
+		return t.putIntoBranch(n, path, val)
+	case *extensionNode:
+		return t.putIntoExtension(n, path, val)
+	case *hashNode:
+		r, err := t.getFromStore(n.hash)
+		if err != nil {
+			return nil, err
+		}
+		return t.putIntoNode(r, path, val)
+	case emptyNode:
+		return newSubTrie(path, val), nil
+	default:
+		return nil, errors.New("invalid node type")
+	}
+}
+
+// putIntoBranch puts the value into the branch node.
+func (t *Trie) putIntoBranch(b *branchNode, path []byte, val node) (node, error) {
+	i, p := splitPath(path)
+	r, err := t.putIntoNode(b.children[i], p, val)
+	if err != nil {
+		return nil, err
+	}
+	b.children[i] = r
+	b.invalidate()
+	return b, nil
+}
+
+// putIntoExtension puts the value into the extension node splitting it if
+// the path doesn't include the whole extension key.
+func (t *Trie) putIntoExtension(e *extensionNode, path []byte, val node) (node, error) {
+	if bytes.HasPrefix(path, e.key) {
+		r, err := t.putIntoNode(e.next, path[len(e.key):], val)
+		if err != nil {
+			return nil, err
+		}
+		e.next = r
+		e.invalidate()
+		return e, nil
+	}
+
+	pref := commonPrefix(e.key, path)
+	lp := len(pref)
+	keyTail := e.key[lp:]
+	pathTail := path[lp:]
+
+	b := newBranchNode()
+	b.children[keyTail[0]] = newSubTrie(keyTail[1:], e.next)
+	i, p := splitPath(pathTail)
+	b.children[i] = newSubTrie(p, val)
+
+	if lp > 0 {
+		return newExtensionNode(copySlice(pref), b), nil
+	}
+	return b, nil
+}
+
+// Delete removes the value stored under the given key from the trie, it's
+// not an error if there is no such key.
+func (t *Trie) Delete(key []byte) error {
+	if len(key) > MaxKeyLength {
+		return errors.New("key is too big")
+	}
+	r, err := t.deleteFromNode(t.root, toNibbles(key))
+	if err != nil {
+		return err
+	}
+	t.root = r
+	return nil
+}
+
+// deleteFromNode removes the value stored under the given path from the
+// subtrie and returns the new subtrie.
+func (t *Trie) deleteFromNode(curr node, path []byte) (node, error) {
+	switch n := curr.(type) {
+	case *leafNode:
+		if len(path) == 0 {
+			return emptyNode{}, nil
+		}
+		return n, nil
+	case *branchNode:
+		return t.deleteFromBranch(n, path)
+	case *extensionNode:
+		return t.deleteFromExtension(n, path)
+	case *hashNode:
+		r, err := t.getFromStore(n.hash)
+		if err != nil {
+			return nil, err
+		}
+		return t.deleteFromNode(r, path)
+	case emptyNode:
+		return n, nil
+	default:
+		return nil, errors.New("invalid node type")
+	}
+}
+
+// deleteFromBranch removes the value from the branch node collapsing it if
+// there is only one child left.
+func (t *Trie) deleteFromBranch(b *branchNode, path []byte) (node, error) {
+	i, p := splitPath(path)
+	r, err := t.deleteFromNode(b.children[i], p)
+	if err != nil {
+		return nil, err
+	}
+	b.children[i] = r
+	b.invalidate()
+
+	var count, index int
+	for j := range b.children {
+		if b.children[j].nodeType() != emptyT {
+			count++
+			index = j
+		}
+	}
+	switch {
+	case count > 1:
+		return b, nil
+	case count == 0:
+		return emptyNode{}, nil
+	case index == lastChild:
+		return b.children[lastChild], nil
+	}
+
+	c := b.children[index]
+	if h, ok := c.(*hashNode); ok {
+		c, err = t.getFromStore(h.hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if e, ok := c.(*extensionNode); ok {
+		return newExtensionNode(append([]byte{byte(index)}, e.key...), e.next), nil
+	}
+	return newExtensionNode([]byte{byte(index)}, c), nil
+}
+
+// deleteFromExtension removes the value from the extension node merging it
+// with the next node if needed.
+func (t *Trie) deleteFromExtension(e *extensionNode, path []byte) (node, error) {
+	if !bytes.HasPrefix(path, e.key) {
+		return e, nil
+	}
+	r, err := t.deleteFromNode(e.next, path[len(e.key):])
+	if err != nil {
+		return nil, err
+	}
+	switch nxt := r.(type) {
+	case *extensionNode:
+		key := make([]byte, 0, len(e.key)+len(nxt.key))
+		key = append(key, e.key...)
+		return newExtensionNode(append(key, nxt.key...), nxt.next), nil
+	case emptyNode:
+		return nxt, nil
+	default:
+		e.next = r
+		e.invalidate()
+		return e, nil
+	}
+}
+
+// Flush writes all the nodes changed since the last flush into the Store and
+// replaces them with hash nodes in memory.
+func (t *Trie) Flush() error {
+	if t.root.nodeType() == emptyT {
+		return nil
+	}
+	if err := t.flush(t.root); err != nil {
+		return err
+	}
+	t.root = newHashNode(t.root.Hash())
+	return nil
+}
+
+func (t *Trie) flush(curr node) error {
+	switch n := curr.(type) {
+	case *branchNode:
+		for i := range n.children {
+			if err := t.flush(n.children[i]); err != nil {
+				return err
+			}
+			if n.children[i].nodeType() != emptyT {
+				n.children[i] = newHashNode(n.children[i].Hash())
+			}
+		}
+	case *extensionNode:
+		if err := t.flush(n.next); err != nil {
+			return err
+		}
+		n.next = newHashNode(n.next.Hash())
+	case *leafNode:
+	default:
+		// Hash nodes are already stored and empty nodes are not stored
+		// at all.
+		return nil
+	}
+	h := curr.Hash()
+	return t.store.Put(makeStorageKey(h), curr.Bytes())
+}
+
+// getFromStore loads the node with the given hash from the Store.
+func (t *Trie) getFromStore(h util.Uint256) (node, error) {
+	data, err := t.store.Get(makeStorageKey(h))
+	if err != nil {
+		return nil, err
+	}
+	return decodeNode(data)
+}
+
+// makeStorageKey returns the Store key of the node with the given hash.
+func makeStorageKey(h util.Uint256) []byte {
+	return storage.AppendPrefix(storage.DataMPT, h.BytesBE())
+}
+
+// newSubTrie returns a subtrie holding the value under the given path.
+func newSubTrie(path []byte, val node) node {
+	if len(path) == 0 {
+		return val
+	}
+	return newExtensionNode(copySlice(path), val)
+}
+
+// splitPath returns the index of the branch node child corresponding to the
+// path and the rest of the path.
+func splitPath(path []byte) (byte, []byte) {
+	if len(path) != 0 {
+		return path[0], path[1:]
+	}
+	return lastChild, path
+}
+
+// toNibbles splits every byte of the key into two nibbles.
+func toNibbles(key []byte) []byte {
+	path := make([]byte, len(key)*2)
+	for i, b := range key {
+		path[i*2] = b >> 4
+		path[i*2+1] = b & 0x0f
+	}
+	return path
+}
+
+// commonPrefix returns the longest common prefix of a and b.
+func commonPrefix(a, b []byte) []byte {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return a[:i]
+}
+
+func copySlice(src []byte) []byte {
+	dst := make([]byte, len(src))
+	copy(dst, src)
+	return dst
+}
@@ -0,0 +1,132 @@
+package mpt
+
+import (
+	"testing"
+
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPairs = []struct {
+	key, value []byte
+}{
+	{[]byte{0x01}, []byte("a")},
+	{[]byte{0x01, 0x02}, []byte("b")},
+	{[]byte{0x01, 0x02, 0x03}, []byte("c")},
+	{[]byte{0x01, 0x12}, []byte("d")},
+	{[]byte{0x10}, []byte("e")},
+	{[]byte{0xab, 0xcd, 0xef}, []byte("f")},
+	{[]byte{0xab, 0xcd}, []byte("g")},
+}
+
+func fillTrie(t *testing.T, tr *Trie, order []int) {
+	for _, i := range order {
+		require.NoError(t, tr.Put(testPairs[i].key, testPairs[i].value))
+	}
+}
+
+func TestTrieEmpty(t *testing.T) {
+	tr := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	assert.Equal(t, util.Uint256{}, tr.StateRoot())
+	_, err := tr.Get([]byte{0x01})
+	assert.Equal(t, ErrNotFound, err)
+	require.NoError(t, tr.Delete([]byte{0x01}))
+	require.NoError(t, tr.Flush())
+	assert.Equal(t, util.Uint256{}, tr.StateRoot())
+}
+
+func TestTriePutGet(t *testing.T) {
+	tr := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	fillTrie(t, tr, []int{0, 1, 2, 3, 4, 5, 6})
+	for _, p := range testPairs {
+		v, err := tr.Get(p.key)
+		require.NoError(t, err)
+		assert.Equal(t, p.value, v)
+	}
+	_, err := tr.Get([]byte{0xab})
+	assert.Equal(t, ErrNotFound, err)
+	_, err = tr.Get([]byte{0x01, 0x02, 0x03, 0x04})
+	assert.Equal(t, ErrNotFound, err)
+
+	require.NoError(t, tr.Put([]byte{0x01, 0x02}, []byte("new")))
+	v, err := tr.Get([]byte{0x01, 0x02})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), v)
+
+	assert.Error(t, tr.Put([]byte{0x01}, nil))
+	assert.Error(t, tr.Put(make([]byte, MaxKeyLength+1), []byte("a")))
+}
+
+func TestTrieRootIsCanonical(t *testing.T) {
+	tr1 := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	fillTrie(t, tr1, []int{0, 1, 2, 3, 4, 5, 6})
+	tr2 := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	fillTrie(t, tr2, []int{6, 5, 4, 3, 2, 1, 0})
+	tr3 := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	fillTrie(t, tr3, []int{2, 5, 0, 3, 6, 1, 4})
+	assert.Equal(t, tr1.StateRoot(), tr2.StateRoot())
+	assert.Equal(t, tr1.StateRoot(), tr3.StateRoot())
+}
+
+func TestTrieDelete(t *testing.T) {
+	tr := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	fillTrie(t, tr, []int{0, 1, 3, 4, 5})
+	expected := tr.StateRoot()
+
+	fillTrie(t, tr, []int{2, 6})
+	require.NotEqual(t, expected, tr.StateRoot())
+	require.NoError(t, tr.Delete(testPairs[2].key))
+	require.NoError(t, tr.Delete(testPairs[6].key))
+	assert.Equal(t, expected, tr.StateRoot())
+	_, err := tr.Get(testPairs[2].key)
+	assert.Equal(t, ErrNotFound, err)
+
+	// Missing keys are ignored.
+	require.NoError(t, tr.Delete([]byte{0xff}))
+	assert.Equal(t, expected, tr.StateRoot())
+
+	for _, i := range []int{0, 1, 3, 4, 5} {
+		require.NoError(t, tr.Delete(testPairs[i].key))
+	}
+	assert.Equal(t, util.Uint256{}, tr.StateRoot())
+}
+
+func TestTrieFlush(t *testing.T) {
+	store := storage.NewMemoryStore()
+	tr := NewTrie(util.Uint256{}, store)
+	fillTrie(t, tr, []int{0, 1, 2, 3})
+	require.NoError(t, tr.Flush())
+	oldRoot := tr.StateRoot()
+
+	// Changes are made on top of the flushed trie.
+	fillTrie(t, tr, []int{4, 5, 6})
+	require.NoError(t, tr.Delete(testPairs[0].key))
+	require.NoError(t, tr.Flush())
+	newRoot := tr.StateRoot()
+
+	full := NewTrie(util.Uint256{}, storage.NewMemoryStore())
+	fillTrie(t, full, []int{1, 2, 3, 4, 5, 6})
+	assert.Equal(t, full.StateRoot(), newRoot)
+
+	// Both states are accessible.
+	old := NewTrie(oldRoot, store)
+	v, err := old.Get(testPairs[0].key)
+	require.NoError(t, err)
+	assert.Equal(t, testPairs[0].value, v)
+	_, err = old.Get(testPairs[4].key)
+	assert.Equal(t, ErrNotFound, err)
+
+	cur := NewTrie(newRoot, store)
+	for _, i := range []int{1, 2, 3, 4, 5, 6} {
+		v, err := cur.Get(testPairs[i].key)
+		require.NoError(t, err)
+		assert.Equal(t, testPairs[i].value, v)
+	}
+	_, err = cur.Get(testPairs[0].key)
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = NewTrie(util.Uint256{1, 2, 3}, store).Get(testPairs[0].key)
+	assert.Error(t, err)
+}
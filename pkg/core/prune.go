@@ -0,0 +1,157 @@
+package core
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/core/block"
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+// defaultPruneDepth is the number of the latest blocks kept intact in pruning
+// mode if it's not configured.
+const defaultPruneDepth = 10000
+
+// prunableKey returns the Store key of the list of transactions that can be
+// pruned along with the block with the given index.
+func prunableKey(index uint32) []byte {
+	return storage.AppendPrefixInt(storage.IXPrunable, int(index))
+}
+
+// markPrunable saves the list of transactions that have all of their outputs
+// spent and claimed after processing the given block, that is the ones of
+// transactions from this block and of transactions spent or claimed by it.
+// They're pruned along with this block, so rollback (that can't go deeper
+// than pruning) never makes pruned transactions spendable again.
+func markPrunable(cache *cachedDao, b *block.Block) error {
+	var (
+		seen   = make(map[util.Uint256]bool)
+		hashes []util.Uint256
+	)
+	check := func(h util.Uint256) error {
+		if seen[h] {
+			return nil
+		}
+		seen[h] = true
+		ok, err := isPrunable(cache, h)
+		if ok {
+			hashes = append(hashes, h)
+		}
+		return err
+	}
+	for _, tx := range b.Transactions {
+		refs := []util.Uint256{tx.Hash()}
+		for _, in := range tx.Inputs {
+			refs = append(refs, in.PrevHash)
+		}
+		if claim, ok := tx.Data.(*transaction.ClaimTX); ok {
+			for _, in := range claim.Claims {
+				refs = append(refs, in.PrevHash)
+			}
+		}
+		for _, h := range refs {
+			if err := check(h); err != nil {
+				return err
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	buf := io.NewBufBinWriter()
+	buf.WriteVarUint(uint64(len(hashes)))
+	for _, h := range hashes {
+		h.EncodeBinary(buf.BinWriter)
+	}
+	if buf.Err != nil {
+		return buf.Err
+	}
+	return cache.store.Put(prunableKey(b.Index), buf.Bytes())
+}
+
+// isPrunable checks whether the transaction with the given hash has all of its
+// outputs spent and claimed.
+func isPrunable(cache *cachedDao, hash util.Uint256) (bool, error) {
+	unspent, err := cache.GetUnspentCoinState(hash)
+	if err != nil && err != storage.ErrKeyNotFound {
+		return false, err
+	}
+	if unspent != nil && unspent.hasUnspent() {
+		return false, nil
+	}
+	spent, err := cache.GetSpentCoinState(hash)
+	if err != nil && err != storage.ErrKeyNotFound {
+		return false, err
+	}
+	return spent == nil || len(spent.items) == 0, nil
+}
+
+// pruneBlock replaces the block with the given index by its header and prunes
+// transactions marked by markPrunable when storing this block. Blocks must be
+// pruned in order, already pruned blocks are skipped.
+func (bc *Blockchain) pruneBlock(cache *cachedDao, index uint32) error {
+	hash := bc.GetHeaderHash(int(index))
+	b, sysFee, err := cache.GetBlock(hash)
+	if err != nil {
+		return err
+	}
+	// Blocks are pruned again when they're stored after rollback.
+	if len(b.Transactions) == 0 {
+		return nil
+	}
+	key := prunableKey(index)
+	data, err := cache.store.Get(key)
+	if err != nil && err != storage.ErrKeyNotFound {
+		return err
+	}
+	if data != nil {
+		r := io.NewBinReaderFromBuf(data)
+		n := r.ReadVarUint()
+		for i := uint64(0); i < n && r.Err == nil; i++ {
+			var h util.Uint256
+			h.DecodeBinary(r)
+			if r.Err == nil {
+				if err := pruneTransaction(cache, h, index); err != nil {
+					return err
+				}
+			}
+		}
+		if r.Err != nil {
+			return r.Err
+		}
+		if err := cache.store.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	buf := io.NewBufBinWriter()
+	buf.WriteU32LE(sysFee)
+	b.Header().EncodeBinary(buf.BinWriter)
+	if buf.Err != nil {
+		return buf.Err
+	}
+	return cache.store.Put(storage.AppendPrefix(storage.DataBlock, hash.BytesLE()), buf.Bytes())
+}
+
+// pruneTransaction removes the coin states of the transaction with the given
+// hash and replaces it by its height (so that it still can't be stored again)
+// if it was stored not later than the block with the given index.
+func pruneTransaction(cache *cachedDao, hash util.Uint256, index uint32) error {
+	_, height, err := cache.GetTransaction(hash)
+	if err == storage.ErrKeyNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// The same transaction can be stored again by some later block.
+	if height > index {
+		return nil
+	}
+	if err := cache.DeleteUnspentCoinState(hash); err != nil {
+		return err
+	}
+	if err := cache.DeleteSpentCoinState(hash); err != nil {
+		return err
+	}
+	return cache.StoreAsPrunedTransaction(hash, height)
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/CityOfZion/neo-go/pkg/core/block"
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPruningMode(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(cfg *config.ProtocolConfiguration) {
+		cfg.PruningMode = true
+		cfg.PruneDepth = 2
+		cfg.MaxRollbackDepth = 2
+	})
+	defer bc.Close()
+	// Transactions here are not signed, so they can't pass verification.
+	bc.config.VerifyTransactions = false
+
+	minerTX := func(nonce uint32) *transaction.Transaction {
+		return &transaction.Transaction{
+			Type: transaction.MinerType,
+			Data: &transaction.MinerTX{Nonce: nonce},
+		}
+	}
+	genesis, err := bc.GetBlock(bc.GetHeaderHash(0))
+	require.NoError(t, err)
+	issue := genesis.Transactions[len(genesis.Transactions)-1]
+	require.Equal(t, transaction.IssueType, issue.Type)
+
+	input := &transaction.Input{PrevHash: issue.Hash(), PrevIndex: 0}
+	spend := &transaction.Transaction{
+		Type:    transaction.ContractType,
+		Data:    &transaction.ContractTX{},
+		Inputs:  []transaction.Input{*input},
+		Outputs: []transaction.Output{issue.Outputs[0]},
+	}
+	spend.Outputs[0].ScriptHash = util.Uint160{1, 2, 3}
+	claim := &transaction.Transaction{
+		Type: transaction.ClaimType,
+		Data: &transaction.ClaimTX{Claims: []*transaction.Input{input}},
+	}
+	invoke := transaction.NewInvocationTX([]byte{byte(opcode.RET)}, 0)
+	b1 := newBlock(1, minerTX(1), spend, invoke)
+	b2 := newBlock(2, minerTX(2), claim)
+	b3 := newBlock(3, minerTX(3))
+	b4 := newBlock(4, minerTX(4))
+	for _, b := range []*block.Block{b1, b2, b3} {
+		require.NoError(t, bc.AddBlock(b))
+	}
+	sysFee := bc.GetSystemFeeAmount(b2.Hash())
+
+	// Block 1 is pruned along with its transactions without outputs, pruned
+	// transactions are still known, so they can't be stored again.
+	_, err = bc.GetBlock(b1.Hash())
+	require.Error(t, err)
+	h, err := bc.GetHeader(b1.Hash())
+	require.NoError(t, err)
+	require.Equal(t, b1.Hash(), h.Hash())
+	require.True(t, bc.HasBlock(b1.Hash()))
+	for _, tx := range []*transaction.Transaction{b1.Transactions[0], invoke} {
+		_, _, err = bc.GetTransaction(tx.Hash())
+		require.Error(t, err)
+		require.True(t, bc.HasTransaction(tx.Hash()))
+	}
+	require.Equal(t, ErrAlreadyExists, bc.PoolTx(invoke))
+	require.Error(t, bc.VerifyTx(invoke, nil))
+	_, _, err = bc.GetTransaction(spend.Hash())
+	require.NoError(t, err)
+	require.NotNil(t, bc.GetUnspentCoinState(spend.Hash()))
+	// The issue transaction is claimed by block 2, so it's pruned with it.
+	_, _, err = bc.GetTransaction(issue.Hash())
+	require.NoError(t, err)
+	_, err = bc.GetBlock(b2.Hash())
+	require.NoError(t, err)
+
+	require.NoError(t, bc.AddBlock(b4))
+	_, err = bc.GetBlock(b2.Hash())
+	require.Error(t, err)
+	require.True(t, bc.HasTransaction(claim.Hash()))
+	_, _, err = bc.GetTransaction(claim.Hash())
+	require.Error(t, err)
+	require.True(t, bc.HasTransaction(issue.Hash()))
+	_, _, err = bc.GetTransaction(issue.Hash())
+	require.Error(t, err)
+	require.Nil(t, bc.GetUnspentCoinState(issue.Hash()))
+	_, err = bc.dao.GetSpentCoinState(issue.Hash())
+	require.Equal(t, storage.ErrKeyNotFound, err)
+	require.Equal(t, sysFee, bc.GetSystemFeeAmount(b2.Hash()))
+	_, err = bc.GetBlock(b3.Hash())
+	require.NoError(t, err)
+	require.NotNil(t, bc.GetAccountState(spend.Outputs[0].ScriptHash))
+
+	// Pruned data is removed from the DB right away, it's not kept in undo
+	// data.
+	buf := io.NewBufBinWriter()
+	issue.EncodeBinary(buf.BinWriter)
+	require.NoError(t, buf.Err)
+	pruned := [][]byte{buf.Bytes()}
+	for _, b := range []*block.Block{b1, b2} {
+		trimmed, err := b.Trim()
+		require.NoError(t, err)
+		pruned = append(pruned, trimmed)
+	}
+	var undoCount int
+	bc.dao.store.Seek([]byte{}, func(k, v []byte) {
+		if storage.KeyPrefix(k[0]) == storage.IXUndo {
+			undoCount++
+		}
+		for _, p := range pruned {
+			require.False(t, bytes.Contains(v, p), "key %x", k)
+		}
+	})
+	require.Equal(t, 2, undoCount)
+
+	// Rollback doesn't restore pruned data.
+	require.Error(t, bc.Rollback(1))
+	require.NoError(t, bc.Rollback(2))
+	_, err = bc.GetBlock(b2.Hash())
+	require.Error(t, err)
+	require.True(t, bc.HasTransaction(issue.Hash()))
+	require.Nil(t, bc.GetUnspentCoinState(issue.Hash()))
+	require.NotNil(t, bc.GetUnspentCoinState(spend.Hash()))
+	require.NoError(t, bc.AddBlock(b3))
+	require.NoError(t, bc.AddBlock(b4))
+	_, err = bc.GetBlock(b3.Hash())
+	require.NoError(t, err)
+
+	t.Run("archival mode", func(t *testing.T) {
+		cfg := bc.config
+		cfg.ArchivalMode = true
+		_, err := NewBlockchain(storage.NewMemoryStore(), cfg, zaptest.NewLogger(t))
+		require.Error(t, err)
+	})
+	t.Run("rollback depth", func(t *testing.T) {
+		cfg := bc.config
+		cfg.MaxRollbackDepth = cfg.PruneDepth + 1
+		_, err := NewBlockchain(storage.NewMemoryStore(), cfg, zaptest.NewLogger(t))
+		require.Error(t, err)
+	})
+}
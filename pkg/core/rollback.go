@@ -0,0 +1,199 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// undoItem is the value the Store key had before the block was stored.
+type undoItem struct {
+	key    []byte
+	exists bool
+	value  []byte
+}
+
+// undoData is the information needed to revert all the changes made to the
+// Store by some block.
+type undoData struct {
+	items []undoItem
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (u *undoData) EncodeBinary(w *io.BinWriter) {
+	w.WriteVarUint(uint64(len(u.items)))
+	for i := range u.items {
+		w.WriteVarBytes(u.items[i].key)
+		w.WriteBool(u.items[i].exists)
+		if u.items[i].exists {
+			w.WriteVarBytes(u.items[i].value)
+		}
+	}
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (u *undoData) DecodeBinary(r *io.BinReader) {
+	n := r.ReadVarUint()
+	u.items = u.items[:0]
+	for i := uint64(0); i < n && r.Err == nil; i++ {
+		var item undoItem
+		item.key = r.ReadVarBytes()
+		item.exists = r.ReadBool()
+		if item.exists {
+			item.value = r.ReadVarBytes()
+		}
+		u.items = append(u.items, item)
+	}
+}
+
+// undoKey returns the Store key of the undo data for the block with the given
+// index.
+func undoKey(index uint32) []byte {
+	return storage.AppendPrefixInt(storage.IXUndo, int(index))
+}
+
+// saveUndoData saves the values that the keys changed in the cache had before
+// the block with the given index was stored. It must be called after all the
+// other changes for the block are made except pruning. Changes of the undo
+// data itself are not saved, so that undo data doesn't include older undo
+// data.
+func (bc *Blockchain) saveUndoData(cache *cachedDao, index uint32) error {
+	var (
+		err error
+		u   = new(undoData)
+	)
+	cache.store.SeekChanges([]byte{}, func(k, v []byte) {
+		if err != nil || storage.KeyPrefix(k[0]) == storage.IXUndo {
+			return
+		}
+		old, getErr := bc.dao.store.Get(k)
+		switch {
+		case getErr == storage.ErrKeyNotFound:
+			if v != nil {
+				u.items = append(u.items, undoItem{key: k})
+			}
+		case getErr != nil:
+			err = getErr
+		case v == nil || !bytes.Equal(old, v):
+			u.items = append(u.items, undoItem{key: k, exists: true, value: old})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(u.items, func(i, j int) bool {
+		return bytes.Compare(u.items[i].key, u.items[j].key) < 0
+	})
+	buf := io.NewBufBinWriter()
+	u.EncodeBinary(buf.BinWriter)
+	if buf.Err != nil {
+		return buf.Err
+	}
+	return cache.store.Put(undoKey(index), buf.Bytes())
+}
+
+// revertBlock restores the Store state before the block with the given index
+// using its undo data, blocks must be reverted starting from the latest one.
+func revertBlock(s storage.Store, index uint32) error {
+	key := undoKey(index)
+	b, err := s.Get(key)
+	if err != nil {
+		return errors.Wrap(err, "no undo data")
+	}
+	u := new(undoData)
+	r := io.NewBinReaderFromBuf(b)
+	u.DecodeBinary(r)
+	if r.Err != nil {
+		return errors.Wrap(r.Err, "bad undo data")
+	}
+	for _, item := range u.items {
+		if item.exists {
+			err = s.Put(item.key, item.value)
+		} else {
+			err = s.Delete(item.key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.Delete(key)
+}
+
+// Rollback reverts the chain to the given height removing all the blocks,
+// headers and transactions above it and restoring the state it had after the
+// block with this index. Undo data is only kept for the latest MaxRollbackDepth
+// blocks, blocks stored without it (like the ones imported from a snapshot)
+// can't be reverted. Transactions of removed blocks
+// are not returned to the memory pool and subscribers are not notified about
+// removed blocks.
+func (bc *Blockchain) Rollback(height uint32) error {
+	bc.addLock.Lock()
+	defer bc.addLock.Unlock()
+
+	current := bc.BlockHeight()
+	if height > current {
+		return fmt.Errorf("can't roll back to block %d, chain height is %d", height, current)
+	}
+	cache := storage.NewMemCachedStore(bc.dao.store)
+	for i := current; i > height; i-- {
+		if err := revertBlock(cache, i); err != nil {
+			return errors.Wrapf(err, "failed to revert block %d", i)
+		}
+	}
+
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	var err error
+	bc.headersOp <- func(headerList *HeaderHashList) {
+		for i := headerList.Len() - 1; i > int(height); i-- {
+			key := storage.AppendPrefix(storage.DataBlock, headerList.Get(i).BytesLE())
+			if err = cache.Delete(key); err != nil {
+				return
+			}
+		}
+		// Only complete batches of header hashes are stored.
+		stored := (height + 1) / headerBatchCount * headerBatchCount
+		for i := stored; i < bc.storedHeaderCount; i += headerBatchCount {
+			if err = cache.Delete(storage.AppendPrefixInt(storage.IXHeaderHashList, int(i))); err != nil {
+				return
+			}
+		}
+		hash := headerList.Get(int(height))
+		if err = cache.Put(storage.SYSCurrentHeader.Bytes(), hashAndIndexToBytes(hash, height)); err != nil {
+			return
+		}
+		if _, err = cache.Persist(); err != nil {
+			return
+		}
+		headerList.hashes = headerList.hashes[:height+1]
+		if stored < bc.storedHeaderCount {
+			bc.storedHeaderCount = stored
+		}
+		updateHeaderHeightMetric(int(height))
+	}
+	<-bc.headersOpDone
+	if err != nil {
+		return err
+	}
+
+	atomic.StoreUint32(&bc.blockHeight, height)
+	atomic.StoreUint32(&bc.persistedHeight, height)
+	updateBlockHeightMetric(height)
+	top, err := bc.GetBlock(bc.GetHeaderHash(int(height)))
+	if err != nil {
+		return errors.Wrap(err, "failed to get current block")
+	}
+	bc.topBlock.Store(top)
+	bc.memPool.RemoveStale(bc.isTxStillRelevant)
+	bc.log.Info("blockchain rolled back",
+		zap.Uint32("from", current),
+		zap.Uint32("to", height))
+	return nil
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEncodeDecodeUndoData(t *testing.T) {
+	u := &undoData{
+		items: []undoItem{
+			{key: []byte{1, 2, 3}},
+			{key: []byte{4}, exists: true, value: []byte{5, 6}},
+			{key: []byte{7}, exists: true, value: []byte{}},
+		},
+	}
+	buf := io.NewBufBinWriter()
+	u.EncodeBinary(buf.BinWriter)
+	require.NoError(t, buf.Err)
+	actual := new(undoData)
+	r := io.NewBinReaderFromBuf(buf.Bytes())
+	actual.DecodeBinary(r)
+	require.NoError(t, r.Err)
+	require.Equal(t, u, actual)
+}
+
+func TestRollback(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	script := []byte{byte(opcode.RET)}
+	h := hash.Hash160(script)
+	key := []byte("key")
+	b1 := newBlock(1, newMinerTX(), newStoragePutTX(t, script, key, []byte("v1")))
+	require.NoError(t, bc.AddBlock(b1))
+	root1, err := bc.GetStateRoot(1)
+	require.NoError(t, err)
+
+	b2 := newBlock(2, newMinerTX(), newStoragePutTX(t, script, key, []byte("v2")))
+	require.NoError(t, bc.AddBlock(b2))
+	root2, err := bc.GetStateRoot(2)
+	require.NoError(t, err)
+	b3 := newBlock(3, newMinerTX(), newStoragePutTX(t, script, []byte("other"), []byte("v3")))
+	require.NoError(t, bc.AddBlock(b3))
+	require.NoError(t, bc.AddHeaders(newBlock(4).Header(), newBlock(5).Header()))
+	require.Equal(t, uint32(5), bc.HeaderHeight())
+
+	require.Error(t, bc.Rollback(4))
+	require.NoError(t, bc.Rollback(1))
+	require.Equal(t, uint32(1), bc.BlockHeight())
+	require.Equal(t, uint32(1), bc.HeaderHeight())
+	require.Equal(t, b1.Hash(), bc.CurrentBlockHash())
+	require.Equal(t, b1.Hash(), bc.CurrentHeaderHash())
+	require.Equal(t, []byte("v1"), bc.GetStorageItem(h, key).Value)
+	require.Nil(t, bc.GetStorageItem(h, []byte("other")))
+	root, err := bc.GetStateRoot(1)
+	require.NoError(t, err)
+	require.Equal(t, root1, root)
+	_, err = bc.GetStateRoot(2)
+	require.Error(t, err)
+	require.False(t, bc.HasBlock(b2.Hash()))
+	_, err = bc.GetHeader(b3.Hash())
+	require.Error(t, err)
+	// Miner transactions are the same in all blocks.
+	require.True(t, bc.HasTransaction(b1.Transactions[0].Hash()))
+	require.True(t, bc.HasTransaction(b1.Transactions[1].Hash()))
+	require.False(t, bc.HasTransaction(b2.Transactions[1].Hash()))
+	require.False(t, bc.HasTransaction(b3.Transactions[1].Hash()))
+	_, height, err := bc.GetTransaction(b1.Transactions[0].Hash())
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), height)
+	top, err := bc.GetBlock(bc.CurrentBlockHash())
+	require.NoError(t, err)
+	require.Equal(t, b1.Hash(), top.Hash())
+
+	// The same block can be added again after rollback.
+	require.NoError(t, bc.AddBlock(b2))
+	root, err = bc.GetStateRoot(2)
+	require.NoError(t, err)
+	require.Equal(t, root2, root)
+	require.Equal(t, []byte("v2"), bc.GetStorageItem(h, key).Value)
+
+	t.Run("restore", func(t *testing.T) {
+		require.NoError(t, bc.Rollback(1))
+		_, err := bc.dao.Persist()
+		require.NoError(t, err)
+		restored, err := NewBlockchain(bc.dao.store, bc.config, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), restored.BlockHeight())
+		require.Equal(t, uint32(1), restored.persistedHeight)
+		require.Equal(t, 2, restored.headerList.Len())
+		require.Equal(t, b1.Hash(), restored.headerList.Last())
+	})
+	t.Run("no undo data", func(t *testing.T) {
+		require.NoError(t, bc.dao.store.Delete(undoKey(1)))
+		require.Error(t, bc.Rollback(0))
+		require.Equal(t, uint32(1), bc.BlockHeight())
+	})
+}
+
+func TestMaxRollbackDepth(t *testing.T) {
+	for _, depth := range []uint32{0, 2} {
+		bc := newTestChainWithCustomCfg(t, func(cfg *config.ProtocolConfiguration) {
+			cfg.MaxRollbackDepth = depth
+		})
+		for i := uint32(1); i <= 3; i++ {
+			require.NoError(t, bc.AddBlock(newBlock(i, newMinerTX())))
+		}
+		for i := uint32(0); i <= 3; i++ {
+			_, err := bc.dao.store.Get(undoKey(i))
+			if depth != 0 && i > 3-depth {
+				require.NoError(t, err, "block %d", i)
+			} else {
+				require.Equal(t, storage.ErrKeyNotFound, err, "block %d", i)
+			}
+		}
+		if depth != 0 {
+			require.Error(t, bc.Rollback(3-depth-1))
+			require.NoError(t, bc.Rollback(3-depth))
+			require.Equal(t, 3-depth, bc.BlockHeight())
+		} else {
+			require.Error(t, bc.Rollback(2))
+		}
+		bc.Close()
+	}
+}
@@ -0,0 +1,384 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	gio "io"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/CityOfZion/neo-go/pkg/core/mpt"
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// snapshotVersion is the version of the snapshot file format.
+	snapshotVersion = 0
+	// snapshotBatchSize is the number of snapshot entries written to the
+	// Store in one batch on import.
+	snapshotBatchSize = 10000
+)
+
+// snapshotPrefixes are prefixes of the Store keys exported into snapshots as
+// is. Transactions are exported in full only if they have unspent or
+// unclaimed outputs (other ones are exported as their heights, the same way
+// pruned transactions are stored) and state roots are recalculated on import.
+var snapshotPrefixes = []storage.KeyPrefix{
+	storage.DataBlock,
+	storage.STAccount,
+	storage.STCoin,
+	storage.STSpentCoin,
+	storage.STValidator,
+	storage.STAsset,
+	storage.STContract,
+	storage.STStorage,
+	storage.STNEP5Transfers,
+	storage.STNEP5Balances,
+	storage.IXHeaderHashList,
+	storage.IXValidatorsCount,
+	storage.SYSCurrentBlock,
+	storage.SYSCurrentHeader,
+	storage.SYSVersion,
+}
+
+// ErrStoreNotEmpty is returned on attempt to import a snapshot into the Store
+// that already has some chain in it.
+var ErrStoreNotEmpty = errors.New("the store is not empty")
+
+// SnapshotHeader describes the chain state snapshot.
+type SnapshotHeader struct {
+	// Magic is the magic number of the network.
+	Magic config.NetMode
+	// Version is the version of the DB format.
+	Version string
+	// Index is the height of the chain.
+	Index uint32
+	// Hash is the hash of the current block.
+	Hash util.Uint256
+	// StateRoot is the state root after processing the current block.
+	StateRoot util.Uint256
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (h *SnapshotHeader) EncodeBinary(w *io.BinWriter) {
+	w.WriteB(snapshotVersion)
+	w.WriteU32LE(uint32(h.Magic))
+	w.WriteString(h.Version)
+	w.WriteU32LE(h.Index)
+	w.WriteBytes(h.Hash[:])
+	w.WriteBytes(h.StateRoot[:])
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (h *SnapshotHeader) DecodeBinary(r *io.BinReader) {
+	if v := r.ReadB(); r.Err == nil && v != snapshotVersion {
+		r.Err = fmt.Errorf("unsupported snapshot version %d", v)
+		return
+	}
+	h.Magic = config.NetMode(r.ReadU32LE())
+	h.Version = r.ReadString()
+	h.Index = r.ReadU32LE()
+	r.ReadBytes(h.Hash[:])
+	r.ReadBytes(h.StateRoot[:])
+}
+
+// ExportSnapshot writes the state of the chain at the current height to w and
+// returns the snapshot header, the chain must not change during export. The
+// snapshot contains block headers with system fees, accounts, coins,
+// validators, assets, contracts, storage items, NEP5 data and transactions
+// with unspent or unclaimed outputs (along with the transactions of the
+// current block). Other transactions are exported as their heights only, so
+// that they can't be stored again (the same way as pruned ones). Application
+// logs, previous state roots and state history are not exported. The snapshot
+// is followed by the SHA256 checksum of its contents. State roots must be
+// enabled to make snapshots.
+func (bc *Blockchain) ExportSnapshot(w gio.Writer) (*SnapshotHeader, error) {
+	index := bc.BlockHeight()
+	root, err := bc.GetStateRoot(index)
+	if err != nil {
+		return nil, errors.Wrap(err, "no state root")
+	}
+	hdr := &SnapshotHeader{
+		Magic:     bc.config.Magic,
+		Version:   version,
+		Index:     index,
+		Hash:      bc.GetHeaderHash(int(index)),
+		StateRoot: root,
+	}
+	current, err := bc.GetBlock(hdr.Hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current block")
+	}
+	// Transactions needed in full are collected by their LE hashes.
+	needed := make(map[string]bool)
+	for _, tx := range current.Transactions {
+		needed[string(tx.Hash().BytesLE())] = true
+	}
+
+	sum := sha256.New()
+	bw := io.NewBinWriterFromIO(gio.MultiWriter(w, sum))
+	hdr.EncodeBinary(bw)
+	for _, p := range snapshotPrefixes {
+		var err error
+		bc.dao.store.Seek(p.Bytes(), func(k, v []byte) {
+			if err != nil {
+				return
+			}
+			if p == storage.STCoin || p == storage.STSpentCoin {
+				var ok bool
+				if ok, err = isCoinStateNeeded(p, v); ok {
+					needed[string(k[1:])] = true
+				}
+			}
+			bw.WriteVarBytes(k)
+			bw.WriteVarBytes(v)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "bad coin state")
+		}
+		if bw.Err != nil {
+			return nil, bw.Err
+		}
+	}
+	bc.dao.store.Seek(storage.DataTransaction.Bytes(), func(k, v []byte) {
+		bw.WriteVarBytes(k)
+		// Transactions are stored along with their heights, pruned
+		// ones are stored as heights only.
+		if len(v) > 4 && !needed[string(k[1:])] {
+			v = v[:4]
+		}
+		bw.WriteVarBytes(v)
+	})
+	// Empty key marks the end of entries.
+	bw.WriteVarBytes([]byte{})
+	if bw.Err != nil {
+		return nil, bw.Err
+	}
+	if _, err := w.Write(sum.Sum(nil)); err != nil {
+		return nil, err
+	}
+	return hdr, nil
+}
+
+// ImportSnapshot loads the chain state snapshot written by ExportSnapshot
+// from r into the empty Store s, verifies its checksum and state root and
+// returns its header. Blockchain created with this Store then continues from
+// the snapshot height. Snapshots can't be used in archival mode and require
+// state roots to be enabled. If the import fails, the imported data is removed
+// from the Store. The Store is marked until the import is finished, so that
+// Blockchain can't be created with the Store where the import was
+// interrupted, such Store can be used for another import.
+func ImportSnapshot(s storage.Store, cfg config.ProtocolConfiguration, r gio.Reader) (*SnapshotHeader, error) {
+	if cfg.ArchivalMode {
+		return nil, errors.New("archival mode requires processing the chain from the genesis block")
+	}
+	if !cfg.StateRootEnabled {
+		return nil, ErrStateRootDisabled
+	}
+	d := newDao(s)
+	if d.IsImportingSnapshot() {
+		// Remove the data of the interrupted import.
+		if err := clearSnapshotData(s); err != nil {
+			return nil, errors.Wrap(err, "failed to clear the store")
+		}
+	} else if _, err := d.GetVersion(); err == nil {
+		return nil, ErrStoreNotEmpty
+	}
+	if err := s.Put(storage.SYSSnapshotImport.Bytes(), []byte{1}); err != nil {
+		return nil, err
+	}
+	hdr, err := importSnapshot(s, cfg, r)
+	if err != nil {
+		if clearErr := clearSnapshotData(s); clearErr != nil {
+			return nil, errors.Wrapf(err, "failed to clear the store (%s) after import error", clearErr)
+		}
+		return nil, err
+	}
+	if err := s.Delete(storage.SYSSnapshotImport.Bytes()); err != nil {
+		return nil, err
+	}
+	return hdr, nil
+}
+
+// importSnapshot loads the snapshot from r into the Store s.
+func importSnapshot(s storage.Store, cfg config.ProtocolConfiguration, r gio.Reader) (*SnapshotHeader, error) {
+	d := newDao(s)
+	sum := sha256.New()
+	br := io.NewBinReaderFromIO(gio.TeeReader(r, sum))
+	hdr := new(SnapshotHeader)
+	hdr.DecodeBinary(br)
+	if br.Err != nil {
+		return nil, errors.Wrap(br.Err, "bad snapshot header")
+	}
+	if hdr.Magic != cfg.Magic {
+		return nil, fmt.Errorf("snapshot is made for network %d", hdr.Magic)
+	}
+	if hdr.Version != version {
+		return nil, fmt.Errorf("snapshot DB version mismatch between %s and %s", version, hdr.Version)
+	}
+
+	// System entries are written after the snapshot is verified, so that
+	// Blockchain can't be started with partially imported state.
+	sys := make(map[string][]byte)
+	for count := 1; ; count++ {
+		k := br.ReadVarBytes()
+		if br.Err != nil {
+			return nil, errors.Wrap(br.Err, "bad snapshot entry")
+		}
+		if len(k) == 0 {
+			break
+		}
+		v := br.ReadVarBytes()
+		if br.Err != nil {
+			return nil, errors.Wrap(br.Err, "bad snapshot entry")
+		}
+		switch p := storage.KeyPrefix(k[0]); {
+		case p == storage.SYSCurrentBlock || p == storage.SYSCurrentHeader || p == storage.SYSVersion:
+			sys[string(k)] = v
+			continue
+		case !isSnapshotPrefix(p):
+			return nil, fmt.Errorf("unexpected snapshot entry with prefix %x", byte(p))
+		}
+		if err := d.store.Put(k, v); err != nil {
+			return nil, err
+		}
+		if count%snapshotBatchSize == 0 {
+			if _, err := d.store.Persist(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	checksum := make([]byte, sha256.Size)
+	if _, err := gio.ReadFull(r, checksum); err != nil {
+		return nil, errors.Wrap(err, "no snapshot checksum")
+	}
+	if !bytes.Equal(checksum, sum.Sum(nil)) {
+		return nil, errors.New("snapshot checksum mismatch")
+	}
+	if _, err := d.store.Persist(); err != nil {
+		return nil, err
+	}
+
+	root, err := rebuildStateTrie(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to rebuild state trie")
+	}
+	if !root.Equals(hdr.StateRoot) {
+		return nil, fmt.Errorf("state root mismatch: snapshot has %s, state has %s", hdr.StateRoot.StringLE(), root.StringLE())
+	}
+	if err := d.PutStateRoot(hdr.Index, root); err != nil {
+		return nil, err
+	}
+	for k, v := range sys {
+		if err := d.store.Put([]byte(k), v); err != nil {
+			return nil, err
+		}
+	}
+	if height, err := d.GetCurrentBlockHeight(); err != nil || height != hdr.Index {
+		return nil, errors.New("snapshot current block doesn't match its header")
+	}
+	if _, err := d.GetVersion(); err != nil {
+		return nil, errors.New("snapshot has no DB version")
+	}
+	if _, err := d.store.Persist(); err != nil {
+		return nil, err
+	}
+	return hdr, nil
+}
+
+// isCoinStateNeeded checks whether the transaction with the given coin state
+// (stored with the given prefix) has unspent or unclaimed outputs.
+func isCoinStateNeeded(p storage.KeyPrefix, v []byte) (bool, error) {
+	r := io.NewBinReaderFromBuf(v)
+	if p == storage.STCoin {
+		unspent := new(UnspentCoinState)
+		unspent.DecodeBinary(r)
+		return r.Err == nil && unspent.hasUnspent(), r.Err
+	}
+	spent := new(SpentCoinState)
+	spent.DecodeBinary(r)
+	return r.Err == nil && len(spent.items) != 0, r.Err
+}
+
+// clearSnapshotData removes all the data written by ImportSnapshot from the
+// Store s.
+func clearSnapshotData(s storage.Store) error {
+	prefixes := append([]storage.KeyPrefix{storage.DataTransaction, storage.DataMPT, storage.IXStateRoot}, snapshotPrefixes...)
+	for _, p := range prefixes {
+		batch := s.Batch()
+		s.Seek(p.Bytes(), func(k, _ []byte) {
+			key := make([]byte, len(k))
+			copy(key, k)
+			batch.Delete(key)
+		})
+		if err := s.PutBatch(batch); err != nil {
+			return err
+		}
+	}
+	return s.Delete(storage.SYSSnapshotImport.Bytes())
+}
+
+// isSnapshotPrefix checks whether the Store keys with the given prefix can be
+// imported from a snapshot.
+func isSnapshotPrefix(p storage.KeyPrefix) bool {
+	if p == storage.DataTransaction {
+		return true
+	}
+	for _, sp := range snapshotPrefixes {
+		if p == sp {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuildStateTrie builds the MPT for the state in the Store s from scratch
+// and returns its root.
+func rebuildStateTrie(s storage.Store) (util.Uint256, error) {
+	// Trie nodes are flushed into a separate cache every snapshotBatchSize
+	// entries, because the Store can't be changed while seeking over it.
+	// To persist them without keeping the whole trie in memory, keys are
+	// sought in 256 ranges by their first byte after the prefix.
+	var (
+		cache   = storage.NewMemCachedStore(s)
+		tr      = mpt.NewTrie(util.Uint256{}, cache)
+		count   int
+		flushed bool
+	)
+	for _, p := range stateRootPrefixes {
+		for b := 0; b <= 0xff; b++ {
+			var err error
+			s.Seek([]byte{byte(p), byte(b)}, func(k, v []byte) {
+				if err != nil {
+					return
+				}
+				if err = tr.Put(k, v); err != nil {
+					return
+				}
+				if count++; count%snapshotBatchSize == 0 {
+					err = tr.Flush()
+					flushed = true
+				}
+			})
+			if err != nil {
+				return util.Uint256{}, err
+			}
+			if flushed {
+				if _, err := cache.Persist(); err != nil {
+					return util.Uint256{}, err
+				}
+				flushed = false
+			}
+		}
+	}
+	if err := tr.Flush(); err != nil {
+		return util.Uint256{}, err
+	}
+	if _, err := cache.Persist(); err != nil {
+		return util.Uint256{}, err
+	}
+	return tr.StateRoot(), nil
+}
@@ -0,0 +1,219 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/CityOfZion/neo-go/pkg/core/storage"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSnapshotHeader(t *testing.T) {
+	hdr := &SnapshotHeader{
+		Magic:   42,
+		Version: version,
+		Index:   7,
+	}
+	hdr.Hash[0] = 1
+	hdr.StateRoot[0] = 2
+	buf := io.NewBufBinWriter()
+	hdr.EncodeBinary(buf.BinWriter)
+	require.NoError(t, buf.Err)
+
+	actual := new(SnapshotHeader)
+	r := io.NewBinReaderFromBuf(buf.Bytes())
+	actual.DecodeBinary(r)
+	require.NoError(t, r.Err)
+	require.Equal(t, hdr, actual)
+
+	data := buf.Bytes()
+	data[0] = snapshotVersion + 1
+	r = io.NewBinReaderFromBuf(data)
+	actual.DecodeBinary(r)
+	require.Error(t, r.Err)
+}
+
+func TestSnapshot(t *testing.T) {
+	bc := newTestChain(t)
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	script := []byte{byte(opcode.RET)}
+	h := hash.Hash160(script)
+	key := []byte("key")
+	b1 := newBlock(1, newMinerTX(), newStoragePutTX(t, script, key, []byte("v1")))
+	b2 := newBlock(2, newMinerTX(), newStoragePutTX(t, script, []byte("other"), []byte("v2")))
+	require.NoError(t, bc.AddBlock(b1))
+	require.NoError(t, bc.AddBlock(b2))
+	genesis, err := bc.GetBlock(bc.GetHeaderHash(0))
+	require.NoError(t, err)
+	issue := genesis.Transactions[len(genesis.Transactions)-1]
+	require.Equal(t, transaction.IssueType, issue.Type)
+
+	buf := new(bytes.Buffer)
+	hdr, err := bc.ExportSnapshot(buf)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), hdr.Index)
+	require.Equal(t, bc.CurrentBlockHash(), hdr.Hash)
+	root, err := bc.GetStateRoot(2)
+	require.NoError(t, err)
+	require.Equal(t, root, hdr.StateRoot)
+	snapshot := buf.Bytes()
+
+	t.Run("import", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		actual, err := ImportSnapshot(store, bc.config, bytes.NewReader(snapshot))
+		require.NoError(t, err)
+		require.Equal(t, hdr, actual)
+
+		imported, err := NewBlockchain(store, bc.config, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		defer imported.Close()
+		go imported.Run()
+		imported.config.VerifyTransactions = false
+
+		require.Equal(t, uint32(2), imported.BlockHeight())
+		require.Equal(t, uint32(2), imported.HeaderHeight())
+		require.Equal(t, bc.GetHeaderHash(1), imported.GetHeaderHash(1))
+		require.Equal(t, bc.CurrentBlockHash(), imported.CurrentBlockHash())
+		require.Equal(t, []byte("v1"), imported.GetStorageItem(h, key).Value)
+		require.Equal(t, []byte("v2"), imported.GetStorageItem(h, []byte("other")).Value)
+		require.NotNil(t, imported.GetContractState(h))
+		actualRoot, err := imported.GetStateRoot(2)
+		require.NoError(t, err)
+		require.Equal(t, root, actualRoot)
+
+		// Transactions without unspent or unclaimed outputs are exported as
+		// markers, except for the ones of the current block.
+		tx := b1.Transactions[1]
+		require.True(t, imported.HasTransaction(tx.Hash()))
+		_, _, err = imported.GetTransaction(tx.Hash())
+		require.Error(t, err)
+		require.Equal(t, ErrAlreadyExists, imported.PoolTx(tx))
+		for _, tx := range []*transaction.Transaction{issue, b2.Transactions[1]} {
+			_, _, err = imported.GetTransaction(tx.Hash())
+			require.NoError(t, err)
+		}
+
+		b := newBlock(3, newMinerTX(), newStoragePutTX(t, script, key, []byte("v3")))
+		require.NoError(t, bc.AddBlock(b))
+		require.NoError(t, imported.AddBlock(b))
+		require.Equal(t, []byte("v3"), imported.GetStorageItem(h, key).Value)
+		expectedRoot, err := bc.GetStateRoot(3)
+		require.NoError(t, err)
+		actualRoot, err = imported.GetStateRoot(3)
+		require.NoError(t, err)
+		require.Equal(t, expectedRoot, actualRoot)
+	})
+	t.Run("not empty", func(t *testing.T) {
+		_, err := ImportSnapshot(bc.dao.store, bc.config, bytes.NewReader(snapshot))
+		require.Equal(t, ErrStoreNotEmpty, err)
+	})
+	// Imported data is removed if the import fails.
+	requireEmpty := func(t *testing.T, s storage.Store) {
+		s.Seek([]byte{}, func(k, _ []byte) {
+			t.Fatalf("unexpected key %x", k)
+		})
+	}
+	t.Run("bad checksum", func(t *testing.T) {
+		data := make([]byte, len(snapshot))
+		copy(data, snapshot)
+		data[len(data)-1] ^= 0xff
+		store := storage.NewMemoryStore()
+		_, err := ImportSnapshot(store, bc.config, bytes.NewReader(data))
+		require.Error(t, err)
+		requireEmpty(t, store)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		_, err := ImportSnapshot(store, bc.config, bytes.NewReader(snapshot[:len(snapshot)/2]))
+		require.Error(t, err)
+		requireEmpty(t, store)
+	})
+	t.Run("interrupted", func(t *testing.T) {
+		store := storage.NewMemoryStore()
+		leftover := storage.AppendPrefix(storage.STStorage, []byte{1, 2, 3})
+		require.NoError(t, store.Put(storage.SYSSnapshotImport.Bytes(), []byte{1}))
+		require.NoError(t, store.Put(leftover, []byte{4}))
+		_, err := NewBlockchain(store, bc.config, zaptest.NewLogger(t))
+		require.Error(t, err)
+
+		_, err = ImportSnapshot(store, bc.config, bytes.NewReader(snapshot))
+		require.NoError(t, err)
+		_, err = store.Get(leftover)
+		require.Equal(t, storage.ErrKeyNotFound, err)
+		_, err = store.Get(storage.SYSSnapshotImport.Bytes())
+		require.Equal(t, storage.ErrKeyNotFound, err)
+		imported, err := NewBlockchain(store, bc.config, zaptest.NewLogger(t))
+		require.NoError(t, err)
+		require.Equal(t, uint32(2), imported.BlockHeight())
+	})
+	t.Run("wrong network", func(t *testing.T) {
+		cfg := bc.config
+		cfg.Magic++
+		_, err := ImportSnapshot(storage.NewMemoryStore(), cfg, bytes.NewReader(snapshot))
+		require.Error(t, err)
+	})
+	t.Run("archival mode", func(t *testing.T) {
+		cfg := bc.config
+		cfg.ArchivalMode = true
+		_, err := ImportSnapshot(storage.NewMemoryStore(), cfg, bytes.NewReader(snapshot))
+		require.Error(t, err)
+	})
+	t.Run("state root disabled", func(t *testing.T) {
+		cfg := bc.config
+		cfg.StateRootEnabled = false
+		_, err := ImportSnapshot(storage.NewMemoryStore(), cfg, bytes.NewReader(snapshot))
+		require.Equal(t, ErrStateRootDisabled, err)
+	})
+}
+
+func TestSnapshotPruned(t *testing.T) {
+	bc := newTestChainWithCustomCfg(t, func(cfg *config.ProtocolConfiguration) {
+		cfg.PruningMode = true
+		cfg.PruneDepth = 2
+		cfg.MaxRollbackDepth = 2
+	})
+	defer bc.Close()
+	// Invocation transactions here are not signed, so they can't pass
+	// verification.
+	bc.config.VerifyTransactions = false
+
+	script := []byte{byte(opcode.RET)}
+	invoke := newStoragePutTX(t, script, []byte("key"), []byte("v1"))
+	require.NoError(t, bc.AddBlock(newBlock(1, newMinerTX(), invoke)))
+	for i := uint32(2); i <= 4; i++ {
+		require.NoError(t, bc.AddBlock(newBlock(i, newMinerTX())))
+	}
+	// Block 1 is pruned, so there is only a marker left for its transaction.
+	require.True(t, bc.HasTransaction(invoke.Hash()))
+	_, _, err := bc.GetTransaction(invoke.Hash())
+	require.Error(t, err)
+
+	buf := new(bytes.Buffer)
+	_, err = bc.ExportSnapshot(buf)
+	require.NoError(t, err)
+
+	store := storage.NewMemoryStore()
+	_, err = ImportSnapshot(store, bc.config, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	imported, err := NewBlockchain(store, bc.config, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer imported.Close()
+	imported.config.VerifyTransactions = false
+
+	require.Equal(t, uint32(4), imported.BlockHeight())
+	require.True(t, imported.HasTransaction(invoke.Hash()))
+	_, _, err = imported.GetTransaction(invoke.Hash())
+	require.Error(t, err)
+	require.Equal(t, ErrAlreadyExists, imported.PoolTx(invoke))
+	require.Error(t, imported.VerifyTx(invoke, nil))
+}
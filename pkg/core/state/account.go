@@ -14,6 +14,18 @@ type UnspentBalance struct {
 	Value util.Fixed8  `json:"value"`
 }
 
+// UnclaimedBalance represents a spent governing token output which can
+// still be claimed (that is, converted to GAS with a ClaimTX).
+type UnclaimedBalance struct {
+	Tx    util.Uint256
+	Index uint16
+	// Start is a height of the block where the output was created.
+	Start uint32
+	// End is a height of the block where the output was spent.
+	End   uint32
+	Value util.Fixed8
+}
+
 // UnspentBalances is a slice of UnspentBalance (mostly needed to sort them).
 type UnspentBalances []UnspentBalance
 
@@ -24,6 +36,7 @@ type Account struct {
 	IsFrozen   bool
 	Votes      []*keys.PublicKey
 	Balances   map[util.Uint256][]UnspentBalance
+	Unclaimed  []UnclaimedBalance
 }
 
 // NewAccount returns a new Account object.
@@ -34,6 +47,7 @@ func NewAccount(scriptHash util.Uint160) *Account {
 		IsFrozen:   false,
 		Votes:      []*keys.PublicKey{},
 		Balances:   make(map[util.Uint256][]UnspentBalance),
+		Unclaimed:  []UnclaimedBalance{},
 	}
 }
 
@@ -56,6 +70,8 @@ func (s *Account) DecodeBinary(br *io.BinReader) {
 		}
 		s.Balances[key] = ubs
 	}
+
+	br.ReadArray(&s.Unclaimed)
 }
 
 // EncodeBinary encodes Account to the given BinWriter.
@@ -73,6 +89,8 @@ func (s *Account) EncodeBinary(bw *io.BinWriter) {
 			v[i].EncodeBinary(bw)
 		}
 	}
+
+	bw.WriteArray(s.Unclaimed)
 }
 
 // DecodeBinary implements io.Serializable interface.
@@ -89,6 +107,24 @@ func (u *UnspentBalance) EncodeBinary(w *io.BinWriter) {
 	u.Value.EncodeBinary(w)
 }
 
+// DecodeBinary implements io.Serializable interface.
+func (u *UnclaimedBalance) DecodeBinary(r *io.BinReader) {
+	u.Tx.DecodeBinary(r)
+	u.Index = r.ReadU16LE()
+	u.Start = r.ReadU32LE()
+	u.End = r.ReadU32LE()
+	u.Value.DecodeBinary(r)
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (u *UnclaimedBalance) EncodeBinary(w *io.BinWriter) {
+	u.Tx.EncodeBinary(w)
+	w.WriteU16LE(u.Index)
+	w.WriteU32LE(u.Start)
+	w.WriteU32LE(u.End)
+	u.Value.EncodeBinary(w)
+}
+
 // GetBalanceValues sums all unspent outputs and returns a map of asset IDs to
 // overall balances.
 func (s *Account) GetBalanceValues() map[util.Uint256]util.Fixed8 {
@@ -15,6 +15,7 @@ func TestDecodeEncodeAccountState(t *testing.T) {
 		n        = 10
 		balances = make(map[util.Uint256][]UnspentBalance)
 		votes    = make([]*keys.PublicKey, n)
+		unclaims = make([]UnclaimedBalance, n)
 	)
 	for i := 0; i < n; i++ {
 		asset := random.Uint256()
@@ -28,6 +29,13 @@ func TestDecodeEncodeAccountState(t *testing.T) {
 		k, err := keys.NewPrivateKey()
 		assert.Nil(t, err)
 		votes[i] = k.PublicKey()
+		unclaims[i] = UnclaimedBalance{
+			Tx:    random.Uint256(),
+			Index: uint16(random.Int(0, 65535)),
+			Start: uint32(random.Int(0, 1000)),
+			End:   uint32(random.Int(1000, 2000)),
+			Value: util.Fixed8(int64(random.Int(1, 10000))),
+		}
 	}
 
 	a := &Account{
@@ -36,6 +44,7 @@ func TestDecodeEncodeAccountState(t *testing.T) {
 		IsFrozen:   true,
 		Votes:      votes,
 		Balances:   balances,
+		Unclaimed:  unclaims,
 	}
 
 	buf := io.NewBufBinWriter()
@@ -55,6 +64,7 @@ func TestDecodeEncodeAccountState(t *testing.T) {
 		assert.Equal(t, vote.X, aDecode.Votes[i].X)
 	}
 	assert.Equal(t, a.Balances, aDecode.Balances)
+	assert.Equal(t, a.Unclaimed, aDecode.Unclaimed)
 }
 
 func TestAccountStateBalanceValues(t *testing.T) {
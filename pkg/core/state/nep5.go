@@ -0,0 +1,164 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm/emit"
+)
+
+// NEP5TransferBatchSize is the maximum number of transfers stored in a single
+// NEP5TransferLog.
+const NEP5TransferBatchSize = 128
+
+// NEP5Tracker contains info about a single account in a NEP5 contract.
+type NEP5Tracker struct {
+	// Balance is the current balance of the account.
+	Balance *big.Int
+	// LastUpdatedBlock is a number of block when last `transfer` to or from the
+	// account occured.
+	LastUpdatedBlock uint32
+}
+
+// NEP5Balances is a map of the NEP5 contract hashes
+// to the corresponding structures.
+type NEP5Balances struct {
+	Trackers map[util.Uint160]NEP5Tracker
+	// NextTransferBatch is the number of the transfer log batch new
+	// transfers of the account are appended to.
+	NextTransferBatch uint32
+}
+
+// NEP5Transfer represents a single NEP5 Transfer event.
+type NEP5Transfer struct {
+	// Asset is a NEP5 contract hash.
+	Asset util.Uint160
+	// From is the address of the sender.
+	From util.Uint160
+	// To is the address of the receiver.
+	To util.Uint160
+	// Amount is the amount of tokens transferred.
+	// It is negative when tokens are sent and positive if they are received.
+	Amount *big.Int
+	// Block is a number of block when the event occured.
+	Block uint32
+	// Timestamp is the timestamp of the block where transfer occured.
+	Timestamp uint32
+	// Tx is a hash the transaction.
+	Tx util.Uint256
+}
+
+// NEP5TransferLog is a batch of the log of NEP5 token transfers for the
+// specific account, it holds up to NEP5TransferBatchSize transfers.
+// Transfers are stored one after another in their serialized form.
+type NEP5TransferLog struct {
+	Raw []byte
+}
+
+// NewNEP5Balances returns new NEP5Balances.
+func NewNEP5Balances() *NEP5Balances {
+	return &NEP5Balances{
+		Trackers: make(map[util.Uint160]NEP5Tracker),
+	}
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (bs *NEP5Balances) DecodeBinary(r *io.BinReader) {
+	lenBalances := r.ReadVarUint()
+	m := make(map[util.Uint160]NEP5Tracker, int(lenBalances))
+	for i := 0; i < int(lenBalances); i++ {
+		var key util.Uint160
+		var tr NEP5Tracker
+		r.ReadBytes(key[:])
+		tr.DecodeBinary(r)
+		m[key] = tr
+	}
+	bs.Trackers = m
+	bs.NextTransferBatch = r.ReadU32LE()
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (bs *NEP5Balances) EncodeBinary(w *io.BinWriter) {
+	w.WriteVarUint(uint64(len(bs.Trackers)))
+	for k, v := range bs.Trackers {
+		w.WriteBytes(k[:])
+		v.EncodeBinary(w)
+	}
+	w.WriteU32LE(bs.NextTransferBatch)
+}
+
+// Append appends single transfer to a log.
+func (lg *NEP5TransferLog) Append(tr *NEP5Transfer) error {
+	w := io.NewBufBinWriter()
+	tr.EncodeBinary(w.BinWriter)
+	if w.Err != nil {
+		return w.Err
+	}
+	lg.Raw = append(lg.Raw, w.Bytes()...)
+	return nil
+}
+
+// ForEach iterates over transfer log returning on first error.
+func (lg *NEP5TransferLog) ForEach(f func(*NEP5Transfer) error) error {
+	if lg == nil || len(lg.Raw) == 0 {
+		return nil
+	}
+	buf := bytes.NewReader(lg.Raw)
+	r := io.NewBinReaderFromIO(buf)
+	for buf.Len() > 0 {
+		tr := new(NEP5Transfer)
+		tr.DecodeBinary(r)
+		if r.Err != nil {
+			return r.Err
+		} else if err := f(tr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size returns an amount of transfer written in log.
+func (lg *NEP5TransferLog) Size() int {
+	var n int
+	_ = lg.ForEach(func(*NEP5Transfer) error {
+		n++
+		return nil
+	})
+	return n
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (t *NEP5Tracker) EncodeBinary(w *io.BinWriter) {
+	w.WriteVarBytes(emit.IntToBytes(t.Balance))
+	w.WriteU32LE(t.LastUpdatedBlock)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (t *NEP5Tracker) DecodeBinary(r *io.BinReader) {
+	t.Balance = emit.BytesToInt(r.ReadVarBytes())
+	t.LastUpdatedBlock = r.ReadU32LE()
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (t *NEP5Transfer) EncodeBinary(w *io.BinWriter) {
+	w.WriteBytes(t.Asset[:])
+	w.WriteBytes(t.Tx[:])
+	w.WriteBytes(t.From[:])
+	w.WriteBytes(t.To[:])
+	w.WriteU32LE(t.Block)
+	w.WriteU32LE(t.Timestamp)
+	w.WriteVarBytes(emit.IntToBytes(t.Amount))
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (t *NEP5Transfer) DecodeBinary(r *io.BinReader) {
+	r.ReadBytes(t.Asset[:])
+	r.ReadBytes(t.Tx[:])
+	r.ReadBytes(t.From[:])
+	r.ReadBytes(t.To[:])
+	t.Block = r.ReadU32LE()
+	t.Timestamp = r.ReadU32LE()
+	t.Amount = emit.BytesToInt(r.ReadVarBytes())
+}
@@ -0,0 +1,104 @@
+package state
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/CityOfZion/neo-go/pkg/internal/random"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNEP5TransferLog_Append(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	expected := []*NEP5Transfer{
+		randomTransfer(r),
+		randomTransfer(r),
+		randomTransfer(r),
+		randomTransfer(r),
+	}
+
+	lg := new(NEP5TransferLog)
+	for _, tr := range expected {
+		require.NoError(t, lg.Append(tr))
+	}
+
+	require.Equal(t, len(expected), lg.Size())
+
+	i := 0
+	err := lg.ForEach(func(tr *NEP5Transfer) error {
+		require.Equal(t, expected[i], tr)
+		i++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(expected), i)
+}
+
+func TestNEP5Tracker_EncodeBinary(t *testing.T) {
+	expected := &NEP5Tracker{
+		Balance:          new(big.Int).Lsh(big.NewInt(int64(rand.Uint32())+1), 70),
+		LastUpdatedBlock: rand.Uint32(),
+	}
+
+	testEncodeDecode(t, expected, new(NEP5Tracker))
+}
+
+func TestNEP5Balances_EncodeBinary(t *testing.T) {
+	expected := NewNEP5Balances()
+	expected.Trackers[random.Uint160()] = NEP5Tracker{Balance: big.NewInt(100500), LastUpdatedBlock: 42}
+	expected.Trackers[random.Uint160()] = NEP5Tracker{Balance: big.NewInt(-1), LastUpdatedBlock: 1}
+	expected.NextTransferBatch = 3
+
+	testEncodeDecode(t, expected, new(NEP5Balances))
+}
+
+func TestNEP5Transfer_DecodeBinary(t *testing.T) {
+	expected := &NEP5Transfer{
+		Asset:     util.Uint160{1, 2, 3},
+		From:      util.Uint160{5, 6, 7},
+		To:        util.Uint160{8, 9, 10},
+		Amount:    big.NewInt(42),
+		Block:     12345,
+		Timestamp: 54321,
+		Tx:        util.Uint256{8, 5, 3},
+	}
+
+	testEncodeDecode(t, expected, new(NEP5Transfer))
+}
+
+func TestNEP5Transfer_BigAmount(t *testing.T) {
+	expected := randomTransfer(rand.New(rand.NewSource(0)))
+	expected.Amount = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 100))
+
+	testEncodeDecode(t, expected, new(NEP5Transfer))
+}
+
+func randomTransfer(r *rand.Rand) *NEP5Transfer {
+	tr := &NEP5Transfer{
+		Amount:    big.NewInt(int64(r.Uint64())),
+		Block:     r.Uint32(),
+		Timestamp: r.Uint32(),
+	}
+
+	tr.Asset = random.Uint160()
+	tr.From = random.Uint160()
+	tr.To = random.Uint160()
+	tr.Tx = random.Uint256()
+
+	return tr
+}
+
+func testEncodeDecode(t *testing.T, expected, actual io.Serializable) {
+	w := io.NewBufBinWriter()
+	expected.EncodeBinary(w.BinWriter)
+	require.NoError(t, w.Err)
+
+	r := io.NewBinReaderFromBuf(w.Bytes())
+	actual.DecodeBinary(r)
+	require.NoError(t, r.Err)
+	require.Equal(t, expected, actual)
+}
@@ -1,5 +1,7 @@
 package storage
 
+import "strings"
+
 // MemCachedStore is a wrapper around persistent store that caches all changes
 // being made for them to be later flushed in one batch.
 type MemCachedStore struct {
@@ -50,6 +52,19 @@ func (s *MemCachedStore) Seek(key []byte, f func(k, v []byte)) {
 	})
 }
 
+// SeekChanges calls f for every key with the given prefix that is changed in
+// the cache, but not yet persisted. Deleted keys are passed with nil values.
+func (s *MemCachedStore) SeekChanges(prefix []byte, f func(k, v []byte)) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	s.MemoryStore.Seek(prefix, f)
+	for k := range s.del {
+		if strings.HasPrefix(k, string(prefix)) {
+			f([]byte(k), nil)
+		}
+	}
+}
+
 // Persist flushes all the MemoryStore contents into the (supposedly) persistent
 // store ps.
 func (s *MemCachedStore) Persist() (int, error) {
@@ -139,3 +139,24 @@ func TestCachedSeek(t *testing.T) {
 func newMemCachedStoreForTesting(t *testing.T) Store {
 	return NewMemCachedStore(NewMemoryStore())
 }
+
+func TestCachedSeekChanges(t *testing.T) {
+	var (
+		ps = NewMemoryStore()
+		ts = NewMemCachedStore(ps)
+	)
+	require.NoError(t, ps.Put([]byte("foo"), []byte("bar")))
+	require.NoError(t, ps.Put([]byte("fee"), []byte("pow")))
+	require.NoError(t, ts.Put([]byte("fuu"), []byte("wop")))
+	require.NoError(t, ts.Put([]byte("zoo"), []byte("zaq")))
+	require.NoError(t, ts.Delete([]byte("fee")))
+
+	changes := make(map[string][]byte)
+	ts.SeekChanges([]byte{'f'}, func(k, v []byte) {
+		changes[string(k)] = v
+	})
+	assert.Equal(t, map[string][]byte{
+		"fuu": []byte("wop"),
+		"fee": nil,
+	}, changes)
+}
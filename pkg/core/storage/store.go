@@ -9,6 +9,7 @@ import (
 const (
 	DataBlock         KeyPrefix = 0x01
 	DataTransaction   KeyPrefix = 0x02
+	DataMPT           KeyPrefix = 0x03
 	STAccount         KeyPrefix = 0x40
 	STCoin            KeyPrefix = 0x44
 	STSpentCoin       KeyPrefix = 0x45
@@ -17,10 +18,19 @@ const (
 	STNotification    KeyPrefix = 0x4d
 	STContract        KeyPrefix = 0x50
 	STStorage         KeyPrefix = 0x70
+	STNEP5Transfers   KeyPrefix = 0x72
+	STNEP5Balances    KeyPrefix = 0x73
 	IXHeaderHashList  KeyPrefix = 0x80
+	IXStateRoot       KeyPrefix = 0x82
+	IXStateHistory    KeyPrefix = 0x84
+	IXStateVersion    KeyPrefix = 0x85
+	IXUndo            KeyPrefix = 0x86
+	IXPrunable        KeyPrefix = 0x87
 	IXValidatorsCount KeyPrefix = 0x90
 	SYSCurrentBlock   KeyPrefix = 0xc0
 	SYSCurrentHeader  KeyPrefix = 0xc1
+	SYSArchivalMode   KeyPrefix = 0xc2
+	SYSSnapshotImport KeyPrefix = 0xc3
 	SYSVersion        KeyPrefix = 0xf0
 )
 
@@ -10,6 +10,7 @@ var (
 	prefixes = []KeyPrefix{
 		DataBlock,
 		DataTransaction,
+		DataMPT,
 		STAccount,
 		STCoin,
 		STValidator,
@@ -17,15 +18,23 @@ var (
 		STContract,
 		STStorage,
 		IXHeaderHashList,
+		IXStateRoot,
+		IXStateHistory,
+		IXStateVersion,
+		IXUndo,
+		IXPrunable,
 		IXValidatorsCount,
 		SYSCurrentBlock,
 		SYSCurrentHeader,
+		SYSArchivalMode,
+		SYSSnapshotImport,
 		SYSVersion,
 	}
 
 	expected = []uint8{
 		0x01,
 		0x02,
+		0x03,
 		0x40,
 		0x44,
 		0x48,
@@ -33,9 +42,16 @@ var (
 		0x50,
 		0x70,
 		0x80,
+		0x82,
+		0x84,
+		0x85,
+		0x86,
+		0x87,
 		0x90,
 		0xc0,
 		0xc1,
+		0xc2,
+		0xc3,
 		0xf0,
 	}
 )
@@ -1,5 +1,7 @@
 package transaction
 
+import "fmt"
+
 //go:generate stringer -type=AttrUsage
 
 // AttrUsage represents the purpose of the attribute.
@@ -49,3 +51,15 @@ const (
 	Remark14 AttrUsage = 0xfe
 	Remark15 AttrUsage = 0xff
 )
+
+// attrUsageFromString converts string representation of the attribute usage
+// (as returned by String) back into AttrUsage.
+func attrUsageFromString(s string) (AttrUsage, error) {
+	for i := 0; i <= 0xff; i++ {
+		usage := AttrUsage(i)
+		if usage.String() == s {
+			return usage, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown attribute usage: %s", s)
+}
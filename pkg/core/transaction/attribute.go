@@ -77,3 +77,22 @@ func (attr *Attribute) MarshalJSON() ([]byte, error) {
 		"data":  hex.EncodeToString(attr.Data),
 	})
 }
+
+// UnmarshalJSON implements the json Unmarshaller interface.
+func (attr *Attribute) UnmarshalJSON(data []byte) error {
+	m := map[string]string{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	usage, err := attrUsageFromString(m["usage"])
+	if err != nil {
+		return err
+	}
+	binData, err := hex.DecodeString(m["data"])
+	if err != nil {
+		return err
+	}
+	attr.Usage = usage
+	attr.Data = binData
+	return nil
+}
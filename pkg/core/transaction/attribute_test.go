@@ -0,0 +1,26 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeMarshalUnmarshalJSON(t *testing.T) {
+	expected := &Attribute{
+		Usage: Remark1,
+		Data:  []byte{1, 2, 3},
+	}
+
+	data, err := json.Marshal(expected)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"usage":"Remark1","data":"010203"}`, string(data))
+
+	actual := new(Attribute)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, expected, actual)
+
+	require.Error(t, json.Unmarshal([]byte(`{"usage":"Unknown","data":"01"}`), actual))
+	require.Error(t, json.Unmarshal([]byte(`{"usage":"Remark","data":"zz"}`), actual))
+}
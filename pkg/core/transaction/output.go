@@ -56,3 +56,25 @@ func (out *Output) MarshalJSON() ([]byte, error) {
 		"n":       out.Position,
 	})
 }
+
+// UnmarshalJSON implements the Unmarshaler interface.
+func (out *Output) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		AssetID  util.Uint256 `json:"asset"`
+		Amount   util.Fixed8  `json:"value"`
+		Address  string       `json:"address"`
+		Position int          `json:"n"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	scriptHash, err := address.StringToUint160(aux.Address)
+	if err != nil {
+		return err
+	}
+	out.AssetID = aux.AssetID
+	out.Amount = aux.Amount
+	out.ScriptHash = scriptHash
+	out.Position = aux.Position
+	return nil
+}
@@ -0,0 +1,28 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputMarshalUnmarshalJSON(t *testing.T) {
+	expected := &Output{
+		AssetID:    util.Uint256{1, 2, 3},
+		Amount:     util.Fixed8FromInt64(42),
+		ScriptHash: util.Uint160{4, 5, 6},
+		Position:   1,
+	}
+
+	data, err := json.Marshal(expected)
+	require.NoError(t, err)
+
+	actual := new(Output)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, expected, actual)
+
+	require.Error(t, json.Unmarshal([]byte(`{"asset":"0x01","value":"1","address":"A","n":0}`), actual))
+	require.Error(t, json.Unmarshal([]byte(`{"value":"1","address":"notanaddress","n":0}`), actual))
+}
@@ -37,6 +37,25 @@ func (w *Witness) MarshalJSON() ([]byte, error) {
 	return json.Marshal(data)
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (w *Witness) UnmarshalJSON(data []byte) error {
+	m := map[string]string{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	invocation, err := hex.DecodeString(m["invocation"])
+	if err != nil {
+		return err
+	}
+	verification, err := hex.DecodeString(m["verification"])
+	if err != nil {
+		return err
+	}
+	w.InvocationScript = invocation
+	w.VerificationScript = verification
+	return nil
+}
+
 // ScriptHash returns the hash of the VerificationScript.
 func (w Witness) ScriptHash() util.Uint160 {
 	return hash.Hash160(w.VerificationScript)
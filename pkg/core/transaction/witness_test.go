@@ -0,0 +1,25 @@
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWitnessMarshalUnmarshalJSON(t *testing.T) {
+	expected := &Witness{
+		InvocationScript:   []byte{1, 2, 3},
+		VerificationScript: []byte{4, 5, 6},
+	}
+
+	data, err := json.Marshal(expected)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"invocation":"010203","verification":"040506"}`, string(data))
+
+	actual := new(Witness)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, expected, actual)
+
+	require.Error(t, json.Unmarshal([]byte(`{"invocation":"zz"}`), actual))
+}
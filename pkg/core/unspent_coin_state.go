@@ -37,3 +37,19 @@ func (s *UnspentCoinState) DecodeBinary(br *io.BinReader) {
 		s.states[i] = state.Coin(br.ReadB())
 	}
 }
+
+// IsSpent returns true if the output with the given index is spent or doesn't
+// exist at all.
+func (s *UnspentCoinState) IsSpent(index uint16) bool {
+	return int(index) >= len(s.states) || s.states[index]&state.CoinSpent != 0
+}
+
+// hasUnspent checks whether some of the outputs are not spent.
+func (s *UnspentCoinState) hasUnspent() bool {
+	for _, st := range s.states {
+		if st&state.CoinSpent == 0 {
+			return true
+		}
+	}
+	return false
+}
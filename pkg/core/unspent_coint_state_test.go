@@ -27,3 +27,18 @@ func TestDecodeEncodeUnspentCoinState(t *testing.T) {
 	unspentDecode.DecodeBinary(r)
 	assert.Nil(t, r.Err)
 }
+
+func TestUnspentCoinState_IsSpent(t *testing.T) {
+	unspent := &UnspentCoinState{
+		states: []state.Coin{
+			state.CoinConfirmed,
+			state.CoinSpent,
+			state.CoinSpent | state.CoinClaimed,
+		},
+	}
+
+	assert.False(t, unspent.IsSpent(0))
+	assert.True(t, unspent.IsSpent(1))
+	assert.True(t, unspent.IsSpent(2))
+	assert.True(t, unspent.IsSpent(3))
+}
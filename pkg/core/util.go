@@ -92,6 +92,16 @@ func createGenesisBlock(cfg config.ProtocolConfiguration) (*block.Block, error)
 	return b, nil
 }
 
+// GoverningTokenID returns the governing token (NEO) asset ID.
+func GoverningTokenID() util.Uint256 {
+	return governingTokenTX().Hash()
+}
+
+// UtilityTokenID returns the utility token (GAS) asset ID.
+func UtilityTokenID() util.Uint256 {
+	return utilityTokenTX().Hash()
+}
+
 func governingTokenTX() *transaction.Transaction {
 	admin := hash.Hash160([]byte{byte(opcode.PUSHT)})
 	registerTX := &transaction.RegisterTX{
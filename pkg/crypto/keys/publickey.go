@@ -6,6 +6,7 @@ import (
 	"crypto/elliptic"
 	"crypto/x509"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -278,3 +279,22 @@ func (p *PublicKey) String() string {
 	by := hex.EncodeToString(p.Y.Bytes())
 	return fmt.Sprintf("%s%s", bx, by)
 }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p *PublicKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(p.Bytes()))
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (p *PublicKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return p.DecodeBytes(b)
+}
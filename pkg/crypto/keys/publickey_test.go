@@ -2,6 +2,7 @@ package keys
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"math/rand"
 	"sort"
 	"testing"
@@ -103,6 +104,20 @@ func TestDecodeBytes(t *testing.T) {
 	require.Equal(t, pubKey, decodedPubKey)
 }
 
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	pubKey := getPubKey(t)
+	data, err := json.Marshal(pubKey)
+	require.NoError(t, err)
+	require.Equal(t, `"`+hex.EncodeToString(pubKey.Bytes())+`"`, string(data))
+
+	actual := new(PublicKey)
+	require.NoError(t, json.Unmarshal(data, actual))
+	require.Equal(t, pubKey, actual)
+
+	require.Error(t, json.Unmarshal([]byte(`"notahex"`), actual))
+	require.Error(t, json.Unmarshal([]byte(`42`), actual))
+}
+
 func TestSort(t *testing.T) {
 	pubs1 := make(PublicKeys, 10)
 	for i := range pubs1 {
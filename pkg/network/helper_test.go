@@ -55,6 +55,9 @@ func (chain *testChain) AddBlock(block *block.Block) error {
 	}
 	return nil
 }
+func (chain *testChain) AddBlockVerified(block *block.Block) error {
+	return chain.AddBlock(block)
+}
 func (chain *testChain) BlockHeight() uint32 {
 	return atomic.LoadUint32(&chain.blockheight)
 }
@@ -76,6 +79,12 @@ func (chain testChain) GetHeaderHash(int) util.Uint256 {
 func (chain testChain) GetHeader(hash util.Uint256) (*block.Header, error) {
 	panic("TODO")
 }
+func (chain testChain) GetSystemFeeAmount(h util.Uint256) uint32 {
+	panic("TODO")
+}
+func (chain testChain) CalculateClaimable(util.Fixed8, uint32, uint32) (util.Fixed8, util.Fixed8) {
+	panic("TODO")
+}
 
 func (chain testChain) GetAssetState(util.Uint256) *state.Asset {
 	panic("TODO")
@@ -83,6 +92,42 @@ func (chain testChain) GetAssetState(util.Uint256) *state.Asset {
 func (chain testChain) GetAccountState(util.Uint160) *state.Account {
 	panic("TODO")
 }
+func (chain testChain) GetAppExecResult(util.Uint256) (*state.AppExecResult, error) {
+	panic("TODO")
+}
+func (chain testChain) GetNEP5Balances(util.Uint160) *state.NEP5Balances {
+	panic("TODO")
+}
+func (chain testChain) ForEachNEP5Transfer(util.Uint160, func(*state.NEP5Transfer) error) error {
+	panic("TODO")
+}
+func (chain testChain) SubscribeForBlocks(ch chan<- *block.Block) {
+	panic("TODO")
+}
+func (chain testChain) SubscribeForExecutions(ch chan<- *state.AppExecResult) {
+	panic("TODO")
+}
+func (chain testChain) SubscribeForNotifications(ch chan<- *state.NotificationEvent) {
+	panic("TODO")
+}
+func (chain testChain) SubscribeForTransactions(ch chan<- *transaction.Transaction) {
+	panic("TODO")
+}
+func (chain testChain) UnsubscribeFromBlocks(ch chan<- *block.Block) {
+	panic("TODO")
+}
+func (chain testChain) UnsubscribeFromExecutions(ch chan<- *state.AppExecResult) {
+	panic("TODO")
+}
+func (chain testChain) UnsubscribeFromNotifications(ch chan<- *state.NotificationEvent) {
+	panic("TODO")
+}
+func (chain testChain) UnsubscribeFromTransactions(ch chan<- *transaction.Transaction) {
+	panic("TODO")
+}
+func (chain testChain) GetEnrollments() ([]*state.Validator, error) {
+	panic("TODO")
+}
 func (chain testChain) GetValidators(...*transaction.Transaction) ([]*keys.PublicKey, error) {
 	panic("TODO")
 }
@@ -92,12 +137,36 @@ func (chain testChain) GetScriptHashesForVerifying(*transaction.Transaction) ([]
 func (chain testChain) GetStorageItem(scripthash util.Uint160, key []byte) *state.StorageItem {
 	panic("TODO")
 }
-func (chain testChain) GetTestVM() (*vm.VM, storage.Store) {
+func (chain testChain) GetTestVM(byte, *transaction.Transaction) (*vm.VM, storage.Store) {
+	panic("TODO")
+}
+func (chain testChain) GetAccountStateAt(util.Uint160, uint32) (*state.Account, error) {
+	panic("TODO")
+}
+func (chain testChain) GetContractStateAt(util.Uint160, uint32) (*state.Contract, error) {
+	panic("TODO")
+}
+func (chain testChain) GetStorageItemAt(util.Uint160, []byte, uint32) (*state.StorageItem, error) {
+	panic("TODO")
+}
+func (chain testChain) GetUnspentCoinStateAt(util.Uint256, uint32) (*core.UnspentCoinState, error) {
+	panic("TODO")
+}
+func (chain testChain) GetStateProof(util.Uint256, []byte) ([][]byte, error) {
+	panic("TODO")
+}
+func (chain testChain) GetStateRoot(uint32) (util.Uint256, error) {
+	panic("TODO")
+}
+func (chain testChain) TraceTransaction(util.Uint256) (*state.AppExecResult, []vm.TraceEntry, error) {
 	panic("TODO")
 }
 func (chain testChain) GetStorageItems(hash util.Uint160) (map[string]*state.StorageItem, error) {
 	panic("TODO")
 }
+func (chain testChain) GetStorageItemsWithPrefix(hash util.Uint160, prefix []byte) (map[string]*state.StorageItem, error) {
+	panic("TODO")
+}
 func (chain testChain) CurrentHeaderHash() util.Uint256 {
 	return util.Uint256{}
 }
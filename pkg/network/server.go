@@ -741,6 +741,24 @@ func (s *Server) relayBlock(b *block.Block) {
 	})
 }
 
+// RelayBlock adds the given block to the local chain performing full
+// verification of it and relays it to the connected peers on success. The
+// error returned contains the details of the block rejection if any.
+func (s *Server) RelayBlock(b *block.Block) (RelayReason, error) {
+	height := s.chain.BlockHeight()
+	if b.Index <= height {
+		return RelayAlreadyExists, fmt.Errorf("block %d is already in the chain, current height is %d", b.Index, height)
+	}
+	if b.Index > height+1 {
+		return RelayUnableToVerify, fmt.Errorf("block %d is too far ahead, current height is %d", b.Index, height)
+	}
+	if err := s.chain.AddBlockVerified(b); err != nil {
+		return RelayInvalid, err
+	}
+	s.relayBlock(b)
+	return RelaySucceed, nil
+}
+
 // verifyAndPoolTX verifies the TX and adds it to the local mempool.
 func (s *Server) verifyAndPoolTX(t *transaction.Transaction) RelayReason {
 	if t.Type == transaction.MinerType {
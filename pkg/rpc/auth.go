@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/pkg/errors"
+)
+
+// authEnabled returns true if the server requires authentication for (some)
+// methods.
+func (s *Server) authEnabled() bool {
+	return len(s.config.Auth.Users) != 0
+}
+
+// authenticate checks credentials of the given HTTP request and returns the
+// user they belong to. Nil user with nil error is returned for requests
+// without credentials (and for all requests if authentication is disabled).
+func (s *Server) authenticate(r *http.Request) (*config.RPCUser, error) {
+	if !s.authEnabled() {
+		return nil, nil
+	}
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, nil
+	}
+	users := s.config.Auth.Users
+	if login, password, ok := r.BasicAuth(); ok {
+		for i := range users {
+			if users[i].Login != "" && secureCompare(users[i].Login, login) &&
+				secureCompare(users[i].Password, password) {
+				return &users[i], nil
+			}
+		}
+		return nil, errors.New("invalid login or password")
+	}
+	const bearerPrefix = "Bearer "
+	if len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		token := header[len(bearerPrefix):]
+		for i := range users {
+			if users[i].Token != "" && secureCompare(users[i].Token, token) {
+				return &users[i], nil
+			}
+		}
+		return nil, errors.New("invalid token")
+	}
+	return nil, errors.New("unsupported authorization scheme")
+}
+
+// isAllowed checks whether the given user (nil for anonymous one) can call
+// the method specified.
+func (s *Server) isAllowed(method string, user *config.RPCUser) bool {
+	if !s.authEnabled() {
+		return true
+	}
+	restricted := s.config.Auth.RestrictedMethods
+	if len(restricted) != 0 && !containsString(restricted, method) {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	return len(user.Methods) == 0 || containsString(user.Methods, method)
+}
+
+// secureCompare compares two strings in constant time.
+func secureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func containsString(list []string, s string) bool {
+	for i := range list {
+		if list[i] == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initAuthServer(t *testing.T) (*Server, func()) {
+	chain, rpcServer := initClearServerWithInMemoryChain(t)
+	rpcServer.config.Auth = config.RPCAuthConfig{
+		Users: []config.RPCUser{
+			{Login: "admin", Password: "pass"},
+			{Token: "sometoken", Methods: []string{"getrawmempool"}},
+		},
+		RestrictedMethods: []string{"getrawmempool", "sendrawtransaction"},
+	}
+	return rpcServer, chain.Close
+}
+
+func doAuthRPCCall(t *testing.T, handler http.HandlerFunc, rpc string, setAuth func(r *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "http://0.0.0.0:20333/", strings.NewReader(rpc))
+	req.Header.Set("Content-Type", "application/json")
+	if setAuth != nil {
+		setAuth(req)
+	}
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestRPCAuth(t *testing.T) {
+	rpcServer, cleanup := initAuthServer(t)
+	defer cleanup()
+	handler := http.HandlerFunc(rpcServer.requestHandler)
+
+	const (
+		publicCall     = `{"jsonrpc": "2.0", "id": 1, "method": "getblockcount", "params": []}`
+		mempoolCall    = `{"jsonrpc": "2.0", "id": 1, "method": "getrawmempool", "params": []}`
+		restrictedCall = `{"jsonrpc": "2.0", "id": 1, "method": "sendrawtransaction", "params": ["00"]}`
+	)
+	basic := func(login, password string) func(r *http.Request) {
+		return func(r *http.Request) { r.SetBasicAuth(login, password) }
+	}
+	bearer := func(token string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+
+	t.Run("public method", func(t *testing.T) {
+		w := doAuthRPCCall(t, handler, publicCall, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("anonymous restricted", func(t *testing.T) {
+		w := doAuthRPCCall(t, handler, mempoolCall, nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		checkErrResponse(t, w.Body.Bytes(), true)
+	})
+	t.Run("basic", func(t *testing.T) {
+		w := doAuthRPCCall(t, handler, mempoolCall, basic("admin", "pass"))
+		require.Equal(t, http.StatusOK, w.Code)
+		checkErrResponse(t, w.Body.Bytes(), false)
+	})
+	t.Run("basic wrong password", func(t *testing.T) {
+		w := doAuthRPCCall(t, handler, publicCall, basic("admin", "wrong"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	})
+	t.Run("bearer", func(t *testing.T) {
+		w := doAuthRPCCall(t, handler, mempoolCall, bearer("sometoken"))
+		require.Equal(t, http.StatusOK, w.Code)
+		checkErrResponse(t, w.Body.Bytes(), false)
+	})
+	t.Run("bearer not allowed method", func(t *testing.T) {
+		w := doAuthRPCCall(t, handler, restrictedCall, bearer("sometoken"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		checkErrResponse(t, w.Body.Bytes(), true)
+	})
+	t.Run("bearer wrong token", func(t *testing.T) {
+		w := doAuthRPCCall(t, handler, publicCall, bearer("othertoken"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("all methods restricted", func(t *testing.T) {
+		rpcServer.config.Auth.RestrictedMethods = nil
+		defer func() {
+			rpcServer.config.Auth.RestrictedMethods = []string{"getrawmempool", "sendrawtransaction"}
+		}()
+		w := doAuthRPCCall(t, handler, publicCall, nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		w = doAuthRPCCall(t, handler, publicCall, basic("admin", "pass"))
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestClientTLSAndAuth(t *testing.T) {
+	rpcServer, cleanup := initAuthServer(t)
+	defer cleanup()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(rpcServer.requestHandler))
+	defer srv.Close()
+
+	caFile, err := ioutil.TempFile("", "neo-go-rpc-ca")
+	require.NoError(t, err)
+	defer os.Remove(caFile.Name())
+	require.NoError(t, pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	require.NoError(t, caFile.Close())
+
+	t.Run("no CA", func(t *testing.T) {
+		c, err := NewClient(context.Background(), srv.URL, ClientOptions{})
+		require.NoError(t, err)
+		_, err = c.GetRawMempool()
+		require.Error(t, err)
+	})
+	t.Run("bad CA file", func(t *testing.T) {
+		_, err := NewClient(context.Background(), srv.URL, ClientOptions{CACert: "/nonexistent"})
+		require.Error(t, err)
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		c, err := NewClient(context.Background(), srv.URL, ClientOptions{CACert: caFile.Name()})
+		require.NoError(t, err)
+		_, err = c.GetRawMempool()
+		require.Error(t, err)
+	})
+	t.Run("basic", func(t *testing.T) {
+		c, err := NewClient(context.Background(), srv.URL, ClientOptions{
+			CACert:   caFile.Name(),
+			User:     "admin",
+			Password: "pass",
+		})
+		require.NoError(t, err)
+		resp, err := c.GetRawMempool()
+		require.NoError(t, err)
+		require.Nil(t, resp.Error)
+	})
+	t.Run("bearer", func(t *testing.T) {
+		c, err := NewClient(context.Background(), srv.URL, ClientOptions{
+			CACert: caFile.Name(),
+			Token:  "sometoken",
+		})
+		require.NoError(t, err)
+		resp, err := c.GetRawMempool()
+		require.NoError(t, err)
+		require.Nil(t, resp.Error)
+	})
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	_, err := newServerTLSConfig(config.RPCTLSConfig{Enabled: true})
+	require.Error(t, err)
+
+	cfg, err := newServerTLSConfig(config.RPCTLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"})
+	require.NoError(t, err)
+	require.Nil(t, cfg.ClientCAs)
+
+	_, err = newServerTLSConfig(config.RPCTLSConfig{
+		Enabled:      true,
+		CertFile:     "cert.pem",
+		KeyFile:      "key.pem",
+		ClientCAFile: "/nonexistent",
+	})
+	require.Error(t, err)
+}
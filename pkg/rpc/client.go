@@ -3,6 +3,7 @@ package rpc
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -11,7 +12,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/CityOfZion/neo-go/pkg/core/state"
 	"github.com/CityOfZion/neo-go/pkg/core/transaction"
 	"github.com/CityOfZion/neo-go/pkg/crypto/keys"
 	"github.com/CityOfZion/neo-go/pkg/util"
@@ -38,21 +38,35 @@ type Client struct {
 	wif        *keys.WIF
 	balancerMu *sync.Mutex
 	balancer   BalanceGetter
+	user       string
+	password   string
+	token      string
 }
 
 // ClientOptions defines options for the RPC client.
 // All Values are optional. If any duration is not specified
 // a default of 3 seconds will be used.
 type ClientOptions struct {
-	Cert        string
-	Key         string
+	// Cert and Key are PEM files with the client certificate and key
+	// used for mutual TLS authentication.
+	Cert string
+	Key  string
+	// CACert is a PEM file with CA certificates used to verify the
+	// server certificate, system CA pool is used if it's not specified.
 	CACert      string
 	DialTimeout time.Duration
-	Client      *http.Client
+	// Client is the HTTP client to use, TLS options are ignored if it's
+	// specified.
+	Client *http.Client
 	// Version is the version of the client that will be send
 	// along with the request body. If no version is specified
 	// the default version (currently 2.0) will be used.
 	Version string
+	// User and Password are used for basic authentication.
+	User     string
+	Password string
+	// Token is used for bearer authentication.
+	Token string
 }
 
 // NewClient returns a new Client ready to use.
@@ -67,24 +81,25 @@ func NewClient(ctx context.Context, endpoint string, opts ClientOptions) (*Clien
 	}
 
 	if opts.Client == nil {
+		tlsConfig, err := newClientTLSConfig(opts)
+		if err != nil {
+			return nil, err
+		}
 		opts.Client = &http.Client{
 			Transport: &http.Transport{
 				DialContext: (&net.Dialer{
 					Timeout: opts.DialTimeout,
 				}).DialContext,
+				TLSClientConfig: tlsConfig,
 			},
 		}
 	}
 
-	// TODO(@antdm): Enable SSL.
-	if opts.Cert != "" && opts.Key != "" {
-	}
-
 	if opts.Client.Timeout == 0 {
 		opts.Client.Timeout = defaultRequestTimeout
 	}
 
-	return &Client{
+	c := &Client{
 		ctx:        ctx,
 		cli:        opts.Client,
 		cliMu:      new(sync.Mutex),
@@ -92,7 +107,36 @@ func NewClient(ctx context.Context, endpoint string, opts ClientOptions) (*Clien
 		wifMu:      new(sync.Mutex),
 		endpoint:   url,
 		version:    opts.Version,
-	}, nil
+		user:       opts.User,
+		password:   opts.Password,
+		token:      opts.Token,
+	}
+	c.balancer = NewNodeBalanceGetter(c, SmallestFirst)
+	return c, nil
+}
+
+// newClientTLSConfig creates TLS configuration from the given options, nil
+// is returned if there are no TLS-related options set.
+func newClientTLSConfig(opts ClientOptions) (*tls.Config, error) {
+	if opts.CACert == "" && opts.Cert == "" && opts.Key == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{}
+	if opts.CACert != "" {
+		pool, err := loadCertPool(opts.CACert)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if opts.Cert != "" || opts.Key != "" {
+		cert, err := tls.LoadX509KeyPair(opts.Cert, opts.Key)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
 }
 
 // WIF returns WIF structure associated with the client.
@@ -156,25 +200,11 @@ func (c *Client) SetClient(cli *http.Client) {
 
 // CalculateInputs creates input transactions for the specified amount of given
 // asset belonging to specified address. This implementation uses GetUnspents
-// JSON-RPC call internally, so make sure your RPC server suppors that.
+// JSON-RPC call internally, so make sure your RPC server suppors that. Inputs
+// are selected using SmallestFirst strategy, use NodeBalanceGetter if another
+// one is needed.
 func (c *Client) CalculateInputs(address string, asset util.Uint256, cost util.Fixed8) ([]transaction.Input, util.Fixed8, error) {
-	var utxos state.UnspentBalances
-
-	resp, err := c.GetUnspents(address)
-	if err != nil || resp.Error != nil {
-		if err == nil {
-			err = fmt.Errorf("remote returned %d: %s", resp.Error.Code, resp.Error.Message)
-		}
-		return nil, util.Fixed8(0), errors.Wrapf(err, "cannot get balance for address %v", address)
-	}
-	for _, ubi := range resp.Result.Balance {
-		if asset.Equals(ubi.AssetHash) {
-			utxos = ubi.Unspents
-			break
-		}
-	}
-	return unspentsToInputs(utxos, cost)
-
+	return NewNodeBalanceGetter(c, SmallestFirst).CalculateInputs(address, asset, cost)
 }
 
 func (c *Client) performRequest(method string, p params, v interface{}) error {
@@ -196,6 +226,11 @@ func (c *Client) performRequest(method string, p params, v interface{}) error {
 	if err != nil {
 		return err
 	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
 	resp, err := c.Client().Do(req)
 	if err != nil {
 		return err
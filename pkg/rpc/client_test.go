@@ -0,0 +1,307 @@
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/CityOfZion/neo-go/pkg/core"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/smartcontract"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm/emit"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initClientWithInMemoryChain(t *testing.T) (*core.Blockchain, *Client, func()) {
+	chain, handler := initServerWithInMemoryChain(t)
+	srv := httptest.NewServer(handler)
+	c, err := NewClient(context.Background(), srv.URL, ClientOptions{})
+	require.NoError(t, err)
+	return chain, c, func() {
+		srv.Close()
+		chain.Close()
+	}
+}
+
+func TestClientBlocks(t *testing.T) {
+	chain, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	count, err := c.GetBlockCount()
+	require.NoError(t, err)
+	require.Nil(t, count.Error)
+	assert.Equal(t, chain.BlockHeight()+1, count.Result)
+
+	best, err := c.GetBestBlockHash()
+	require.NoError(t, err)
+	require.Nil(t, best.Error)
+	assert.Equal(t, chain.CurrentBlockHash(), best.Result)
+
+	hash, err := c.GetBlockHash(1)
+	require.NoError(t, err)
+	require.Nil(t, hash.Error)
+	assert.Equal(t, chain.GetHeaderHash(1), hash.Result)
+
+	expected, err := chain.GetBlock(hash.Result)
+	require.NoError(t, err)
+
+	b, err := c.GetBlock(hash.Result.StringLE())
+	require.NoError(t, err)
+	require.Nil(t, b.Error)
+	assert.Equal(t, expected.Hash(), b.Result.Hash())
+	assert.Equal(t, len(expected.Transactions), len(b.Result.Transactions))
+
+	b, err = c.GetBlockByIndex(1)
+	require.NoError(t, err)
+	require.Nil(t, b.Error)
+	assert.Equal(t, expected.Hash(), b.Result.Hash())
+
+	bv, err := c.GetBlockVerbose(hash.Result.StringLE())
+	require.NoError(t, err)
+	require.Nil(t, bv.Error)
+	assert.Equal(t, expected.Hash(), bv.Result.Hash)
+	assert.Equal(t, expected.Index, bv.Result.Index)
+	assert.Equal(t, expected.MerkleRoot, bv.Result.MerkleRoot)
+	assert.Equal(t, chain.GetHeaderHash(2), bv.Result.NextBlockHash)
+	assert.Equal(t, len(expected.Transactions), len(bv.Result.Transactions))
+
+	bv, err = c.GetBlockByIndexVerbose(1)
+	require.NoError(t, err)
+	require.Nil(t, bv.Error)
+	assert.Equal(t, expected.Hash(), bv.Result.Hash)
+
+	b, err = c.GetBlockByIndex(chain.BlockHeight() + 1)
+	require.NoError(t, err)
+	require.NotNil(t, b.Error)
+	require.Nil(t, b.Result)
+}
+
+func TestClientTransactions(t *testing.T) {
+	chain, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	b, err := chain.GetBlock(chain.GetHeaderHash(0))
+	require.NoError(t, err)
+	expected := b.Transactions[1]
+
+	tx, err := c.GetRawTransaction(expected.Hash().StringLE())
+	require.NoError(t, err)
+	require.Nil(t, tx.Error)
+	assert.Equal(t, expected.Hash(), tx.Result.Hash())
+	assert.Equal(t, transaction.RegisterType, tx.Result.Type)
+
+	txv, err := c.GetRawTransactionVerbose(expected.Hash().StringLE())
+	require.NoError(t, err)
+	require.Nil(t, txv.Error)
+	assert.Equal(t, expected.Hash(), txv.Result.TxHash)
+	assert.Equal(t, b.Hash(), txv.Result.Blockhash)
+	assert.Equal(t, expected.Type, txv.Result.Type)
+
+	tx, err = c.GetRawTransaction("0000000000000000000000000000000000000000000000000000000000000000")
+	require.NoError(t, err)
+	require.NotNil(t, tx.Error)
+}
+
+func TestClientNodeInfo(t *testing.T) {
+	_, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	cc, err := c.GetConnectionCount()
+	require.NoError(t, err)
+	require.Nil(t, cc.Error)
+	assert.Equal(t, 0, cc.Result)
+
+	peers, err := c.GetPeers()
+	require.NoError(t, err)
+	require.Nil(t, peers.Error)
+	require.NotNil(t, peers.Result)
+
+	v, err := c.GetVersion()
+	require.NoError(t, err)
+	require.Nil(t, v.Error)
+	assert.NotEmpty(t, v.Result.UserAgent)
+}
+
+func TestClientAddressesAndAssets(t *testing.T) {
+	_, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	va, err := c.ValidateAddress("AQVh2pG732YvtNaxEGkQUei3YA4cvo7d2i")
+	require.NoError(t, err)
+	require.Nil(t, va.Error)
+	assert.True(t, va.Result.IsValid)
+
+	va, err = c.ValidateAddress("notanaddress")
+	require.NoError(t, err)
+	require.Nil(t, va.Error)
+	assert.False(t, va.Result.IsValid)
+
+	as, err := c.GetAssetState("602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7")
+	require.NoError(t, err)
+	require.Nil(t, as.Error)
+	require.NotNil(t, as.Result)
+	assert.Equal(t, "AWKECj9RD8rS8RPcpCgYVjk1DeYyHwxZm3", as.Result.Admin)
+
+	as, err = c.GetAssetState("602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de8")
+	require.NoError(t, err)
+	require.Nil(t, as.Error)
+	require.Nil(t, as.Result)
+}
+
+func TestClientInvokeWithSigners(t *testing.T) {
+	_, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	signer := util.Uint160{1, 2, 3}
+	w := io.NewBufBinWriter()
+	emit.Bytes(w.BinWriter, signer.BytesBE())
+	emit.Syscall(w.BinWriter, "Neo.Runtime.CheckWitness")
+	require.NoError(t, w.Err)
+	script := hex.EncodeToString(w.Bytes())
+
+	res, err := c.InvokeScript(script)
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	require.Equal(t, 1, len(res.Result.Stack))
+	assert.Equal(t, StackParam{Type: Boolean, Value: false}, res.Result.Stack[0])
+
+	res, err = c.InvokeScript(script, util.Uint160{3, 2, 1}, signer)
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	require.Equal(t, 1, len(res.Result.Stack))
+	assert.Equal(t, StackParam{Type: Boolean, Value: true}, res.Result.Stack[0])
+}
+
+func TestClientInvokeTrace(t *testing.T) {
+	_, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	script := []byte{byte(opcode.PUSH1), byte(opcode.THROW)}
+	res, err := c.TraceInvokeScript(hex.EncodeToString(script))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Equal(t, "FAULT", res.Result.State.String())
+	require.Equal(t, 2, len(res.Result.Trace))
+	assert.Equal(t, "PUSH1", res.Result.Trace[0].Opcode)
+	assert.Equal(t, "BigInteger 1", res.Result.Trace[0].Stack)
+	assert.Equal(t, "THROW", res.Result.Trace[1].Opcode)
+	assert.Equal(t, 1, res.Result.Trace[1].IP)
+	assert.Equal(t, hash.Hash160(script), res.Result.Trace[1].ScriptHash)
+
+	res, err = c.InvokeScript(hex.EncodeToString(script))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Nil(t, res.Result.Trace)
+
+	res, err = c.TraceInvokeFunction("50befd26fdf6e4d957c11e078b24ebce6291456f", "test", []smartcontract.Parameter{})
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.NotEqual(t, 0, len(res.Result.Trace))
+}
+
+func TestClientTraceTransaction(t *testing.T) {
+	chain, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	var checked int
+	for i := 0; i <= int(chain.BlockHeight()); i++ {
+		b, err := chain.GetBlock(chain.GetHeaderHash(i))
+		require.NoError(t, err)
+		for _, tx := range b.Transactions {
+			if tx.Type != transaction.InvocationType {
+				continue
+			}
+			res, err := c.TraceTransaction(tx.Hash().StringLE())
+			require.NoError(t, err)
+			require.Nil(t, res.Error)
+			assert.Equal(t, tx.Hash(), res.Result.TxHash)
+			assert.Equal(t, b.Hash(), res.Result.BlockHash)
+			require.Equal(t, 1, len(res.Result.Executions))
+			// The state might have been changed by subsequent blocks, so
+			// the result is only checked for consistency.
+			exec := res.Result.Executions[0]
+			assert.Equal(t, hash.Hash160(tx.Data.(*transaction.InvocationTX).Script), exec.ScriptHash)
+			require.NotEqual(t, 0, len(res.Result.Trace))
+			last := res.Result.Trace[len(res.Result.Trace)-1]
+			assert.Equal(t, exec.GasConsumed, last.GasConsumed)
+			checked++
+		}
+	}
+	require.NotEqual(t, 0, checked, "no invocation transactions checked")
+}
+
+func TestClientStateRoot(t *testing.T) {
+	chain, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	height := int(chain.BlockHeight())
+	res, err := c.GetStateRoot(height)
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	root, err := chain.GetStateRoot(uint32(height))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(height), res.Result.Index)
+	assert.Equal(t, root, res.Result.Root)
+
+	res, err = c.GetStateRoot(height + 1)
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+
+	proof, err := c.GetProof(root.StringLE(), "50befd26fdf6e4d957c11e078b24ebce6291456f", "01")
+	require.NoError(t, err)
+	require.NotNil(t, proof.Error)
+}
+
+func TestClientHistoricalState(t *testing.T) {
+	chain, handler := initServerWithCustomConfig(t, func(cfg *config.Config) {
+		cfg.ProtocolConfiguration.ArchivalMode = true
+	})
+	defer chain.Close()
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	c, err := NewClient(context.Background(), srv.URL, ClientOptions{})
+	require.NoError(t, err)
+
+	height := chain.BlockHeight()
+	const addr = "AZ81H31DMWzbSnFDLFkzh9vHwaDLayV7fU"
+	cur, err := c.GetAccountState(addr)
+	require.NoError(t, err)
+	hist, err := c.GetAccountStateAt(addr, height)
+	require.NoError(t, err)
+	require.NotNil(t, hist.Result)
+	assert.Equal(t, cur.Result, hist.Result)
+
+	// Outputs unspent now were unspent right after their creation and some
+	// of the spent ones should be found unspent in the past.
+	var spent int
+	for i := uint32(0); i <= height; i++ {
+		b, err := chain.GetBlock(chain.GetHeaderHash(int(i)))
+		require.NoError(t, err)
+		for _, tx := range b.Transactions {
+			for n := range tx.Outputs {
+				cur, err := c.GetTxOut(tx.Hash().StringLE(), n)
+				require.NoError(t, err)
+				hist, err := c.GetTxOutAt(tx.Hash().StringLE(), n, i)
+				require.NoError(t, err)
+				require.Nil(t, hist.Error)
+				if cur.Result != nil {
+					require.NotNil(t, hist.Result)
+				} else if hist.Result != nil {
+					spent++
+				}
+			}
+		}
+	}
+	require.NotEqual(t, 0, spent)
+
+	res, err := c.GetStorageAt("50befd26fdf6e4d957c11e078b24ebce6291456f", "01", height+1)
+	require.NoError(t, err)
+	require.NotNil(t, res.Error)
+}
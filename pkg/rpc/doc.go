@@ -32,46 +32,54 @@ An example:
   log.Println(resp.Result.Balances)
 
 TODO:
-	Merge structs so can be used by both server and client.
-	Add missing methods to client.
-	Allow client to connect using client cert.
 	More in-depth examples.
 
 Supported methods
 
-	getblock
+	findstorage
 	getaccountstate
-	getunspents
-	invokescript
-	invokefunction
-	sendrawtransaction
-	invoke
-	getrawtransaction
-
-Unsupported methods
-
-	validateaddress
+	getapplicationlog
+	getassetstate
+	getbestblockhash
+	getblock
+	getblockcount
+	getblockhash
+	getblockheader
 	getblocksysfee
+	getclaimable
+	getconnectioncount
 	getcontractstate
+	getmempoolentry
+	getnep5balances
+	getnep5transfers
+	getpeers
+	getproof
 	getrawmempool
+	getrawtransaction
+	getstateroot
 	getstorage
-	submitblock
 	gettxout
-	getassetstate
-	getpeers
+	getunclaimed
+	getunspents
+	getvalidators
 	getversion
-	getconnectioncount
-	getblockhash
-	getblockcount
-	getbestblockhash
+	invoke
+	invokefunction
+	invokescript
+	sendrawtransaction
+	submitblock
+	tracetransaction
+	validateaddress
 
 Server
 
 The server is written to support as much of the JSON-RPC 2.0 Spec as possible.
-The server is run as part of the node currently.
+The server is run as part of the node currently. It also accepts WebSocket
+connections on /ws path that allow to subscribe to chain events (new blocks,
+transactions, notifications and execution results) with subscribe and
+unsubscribe methods.
 
 TODO:
-	Implement HTTPS server.
 	Add remaining methods (Documented below).
 	Add Swagger spec and test using dredd in circleCI.
 
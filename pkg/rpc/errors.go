@@ -62,6 +62,20 @@ func NewInternalServerError(data string, cause error) *Error {
 	return newError(-32603, http.StatusInternalServerError, "Internal error", data, cause)
 }
 
+// NewUnauthorizedError creates a new error with code
+// -32001, it's returned for requests with invalid credentials and
+// for methods that the client is not allowed to call.
+func NewUnauthorizedError(data string, cause error) *Error {
+	return newError(-32001, http.StatusUnauthorized, "Unauthorized", data, cause)
+}
+
+// NewLimitExceededError creates a new error with code
+// -32005, it's returned when the client exceeds the request rate limit or
+// when the server is too busy to process the request.
+func NewLimitExceededError(data string, cause error) *Error {
+	return newError(-32005, http.StatusTooManyRequests, "Limit exceeded", data, cause)
+}
+
 // Error implements the error interface.
 func (e Error) Error() string {
 	return fmt.Sprintf("%s (%d) - %s - %s", e.Message, e.Code, e.Data, e.Cause)
@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/CityOfZion/neo-go/config"
+)
+
+type (
+	// rateLimiter is a per-IP token bucket request rate limiter.
+	rateLimiter struct {
+		lock        sync.Mutex
+		rate        float64
+		burst       float64
+		buckets     map[string]*bucket
+		lastCleanup time.Time
+	}
+
+	// bucket is a token bucket of a single client.
+	bucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// defaultMaxRequestBodySize is the maximum size of request body used when
+// it's not specified in the configuration.
+const defaultMaxRequestBodySize = 4 * 1024 * 1024
+
+// rateLimiterCleanupPeriod is the period of removing buckets of inactive
+// clients from the rate limiter.
+const rateLimiterCleanupPeriod = time.Minute
+
+// invocationMethods are methods running the VM that are subject to the
+// concurrent invocations limit.
+var invocationMethods = []string{"invoke", "invokefunction", "invokescript", "tracetransaction"}
+
+// newRateLimiter creates a rate limiter with the given configuration, it
+// returns nil if rate limiting is disabled.
+func newRateLimiter(cfg config.RPCRateLimitConfig) *rateLimiter {
+	if cfg.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = cfg.RequestsPerSecond
+	}
+	return &rateLimiter{
+		rate:        float64(cfg.RequestsPerSecond),
+		burst:       float64(burst),
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// allow checks whether n more requests can be made from the given address
+// and accounts for them if so. Nil limiter allows everything.
+func (l *rateLimiter) allow(addr string, n int) bool {
+	if l == nil {
+		return true
+	}
+	now := time.Now()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if now.Sub(l.lastCleanup) >= rateLimiterCleanupPeriod {
+		for a, b := range l.buckets {
+			if l.refill(b, now) >= l.burst {
+				delete(l.buckets, a)
+			}
+		}
+		l.lastCleanup = now
+	}
+	b, ok := l.buckets[addr]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[addr] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// refill returns the number of tokens in the bucket at the given moment.
+func (l *rateLimiter) refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.last).Seconds()*l.rate
+	if tokens > l.burst {
+		tokens = l.burst
+	}
+	return tokens
+}
+
+// clientAddr returns IP address of the client making the request.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// checkRate checks whether n more requests can be made from the given
+// address.
+func (s *Server) checkRate(addr string, n int) error {
+	if !s.limiter.allow(addr, n) {
+		return NewLimitExceededError("Request rate limit exceeded", nil)
+	}
+	return nil
+}
+
+// checkBatchRate checks whether the rest of the batch of n requests can be
+// made from the given address, its first request is accounted for by
+// checkRate before decoding the batch. The batch is charged not more than
+// the burst size, so that batches bigger than it can be made at all.
+func (s *Server) checkBatchRate(addr string, n int) error {
+	if n <= 1 {
+		return nil
+	}
+	if s.limiter != nil && float64(n) > s.limiter.burst {
+		n = int(s.limiter.burst)
+	}
+	return s.checkRate(addr, n-1)
+}
+
+// isEnabled checks whether the method is enabled in the configuration.
+func (s *Server) isEnabled(method string) bool {
+	if len(s.config.EnabledMethods) != 0 && !containsString(s.config.EnabledMethods, method) {
+		return false
+	}
+	return !containsString(s.config.DisabledMethods, method)
+}
+
+// maxRequestBodySize returns the maximum size of a single request body.
+func (s *Server) maxRequestBodySize() int {
+	if s.config.MaxRequestBodySize > 0 {
+		return s.config.MaxRequestBodySize
+	}
+	return defaultMaxRequestBodySize
+}
+
+// acquireInvocation takes a slot for VM-backed call if the method given is
+// one of them, release function returned must be called after the call is
+// processed.
+func (s *Server) acquireInvocation(method string) (func(), error) {
+	if s.invocations == nil || !containsString(invocationMethods, method) {
+		return func() {}, nil
+	}
+	select {
+	case s.invocations <- struct{}{}:
+		return func() { <-s.invocations }, nil
+	default:
+		return nil, NewLimitExceededError(
+			fmt.Sprintf("Too many concurrent invocations, the limit is %d", cap(s.invocations)), nil)
+	}
+}
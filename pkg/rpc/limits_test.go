@@ -0,0 +1,136 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	require.Nil(t, newRateLimiter(config.RPCRateLimitConfig{}))
+	require.True(t, (*rateLimiter)(nil).allow("1.1.1.1", 100))
+
+	l := newRateLimiter(config.RPCRateLimitConfig{RequestsPerSecond: 2, Burst: 5})
+	require.True(t, l.allow("1.1.1.1", 3))
+	require.True(t, l.allow("1.1.1.1", 2))
+	require.False(t, l.allow("1.1.1.1", 1))
+	require.True(t, l.allow("2.2.2.2", 5))
+	require.False(t, l.allow("3.3.3.3", 6))
+
+	// Pretend a second has passed.
+	l.buckets["1.1.1.1"].last = l.buckets["1.1.1.1"].last.Add(-time.Second)
+	require.True(t, l.allow("1.1.1.1", 2))
+	require.False(t, l.allow("1.1.1.1", 1))
+
+	// Full buckets are removed on cleanup.
+	for _, b := range l.buckets {
+		b.last = b.last.Add(-time.Hour)
+	}
+	l.lastCleanup = l.lastCleanup.Add(-rateLimiterCleanupPeriod)
+	require.True(t, l.allow("1.1.1.1", 1))
+	assert.Equal(t, 1, len(l.buckets))
+
+	l = newRateLimiter(config.RPCRateLimitConfig{RequestsPerSecond: 2})
+	require.True(t, l.allow("1.1.1.1", 2))
+	require.False(t, l.allow("1.1.1.1", 1))
+}
+
+func TestRPCLimits(t *testing.T) {
+	chain, rpcServer := initClearServerWithInMemoryChain(t)
+	defer chain.Close()
+	handler := http.HandlerFunc(rpcServer.requestHandler)
+
+	const call = `{"jsonrpc": "2.0", "id": 1, "method": "getblockcount", "params": []}`
+
+	t.Run("body size", func(t *testing.T) {
+		rpcServer.config.MaxRequestBodySize = len(call)
+		defer func() { rpcServer.config.MaxRequestBodySize = 0 }()
+
+		checkErrResponse(t, doRPCCall(call, handler, t), false)
+		checkErrResponse(t, doRPCCall(call+"  ", handler, t), true)
+	})
+	t.Run("disabled methods", func(t *testing.T) {
+		rpcServer.config.EnabledMethods = []string{"getblockcount", "getbestblockhash"}
+		rpcServer.config.DisabledMethods = []string{"getbestblockhash"}
+		defer func() {
+			rpcServer.config.EnabledMethods = nil
+			rpcServer.config.DisabledMethods = nil
+		}()
+
+		checkErrResponse(t, doRPCCall(call, handler, t), false)
+		checkErrResponse(t, doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": "getbestblockhash", "params": []}`, handler, t), true)
+		checkErrResponse(t, doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": "getversion", "params": []}`, handler, t), true)
+	})
+	t.Run("concurrent invocations", func(t *testing.T) {
+		rpcServer.invocations = make(chan struct{}, 1)
+		defer func() { rpcServer.invocations = nil }()
+
+		const invoke = `{"jsonrpc": "2.0", "id": 1, "method": "invokescript", "params": ["51"]}`
+		checkErrResponse(t, doRPCCall(invoke, handler, t), false)
+		require.Equal(t, 0, len(rpcServer.invocations))
+
+		rpcServer.invocations <- struct{}{}
+		body := doRPCCall(invoke, handler, t)
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(body, &resp))
+		assert.Equal(t, -32005, resp.Error.Code)
+		// Other methods are not affected.
+		checkErrResponse(t, doRPCCall(call, handler, t), false)
+	})
+	t.Run("rate", func(t *testing.T) {
+		rpcServer.limiter = newRateLimiter(config.RPCRateLimitConfig{RequestsPerSecond: 1, Burst: 3})
+		defer func() { rpcServer.limiter = nil }()
+
+		checkBatchResponse(t, doRPCCall(`[`+call+`,`+call+`]`, handler, t), 2)
+		checkErrResponse(t, doRPCCall(call, handler, t), false)
+
+		req := httptest.NewRequest("POST", "http://0.0.0.0:20333/", strings.NewReader(call))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		checkErrResponse(t, w.Body.Bytes(), true)
+
+		// Another client is not limited.
+		req = httptest.NewRequest("POST", "http://0.0.0.0:20333/", strings.NewReader(call))
+		req.RemoteAddr = "192.0.2.2:1234"
+		w = httptest.NewRecorder()
+		handler(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("rate, malformed requests", func(t *testing.T) {
+		rpcServer.limiter = newRateLimiter(config.RPCRateLimitConfig{RequestsPerSecond: 1, Burst: 2})
+		defer func() { rpcServer.limiter = nil }()
+
+		var resp ErrorResponse
+		for i := 0; i < 2; i++ {
+			require.NoError(t, json.Unmarshal(doRPCCall(`{`, handler, t), &resp))
+			assert.Equal(t, -32700, resp.Error.Code)
+		}
+		require.NoError(t, json.Unmarshal(doRPCCall(`{`, handler, t), &resp))
+		assert.Equal(t, -32005, resp.Error.Code)
+	})
+	t.Run("rate, batch bigger than burst", func(t *testing.T) {
+		rpcServer.limiter = newRateLimiter(config.RPCRateLimitConfig{RequestsPerSecond: 1, Burst: 2})
+		defer func() { rpcServer.limiter = nil }()
+
+		batch := `[` + call + `,` + call + `,` + call + `]`
+		checkBatchResponse(t, doRPCCall(batch, handler, t), 3)
+		checkErrResponse(t, doRPCCall(call, handler, t), true)
+	})
+}
+
+func checkBatchResponse(t *testing.T, body []byte, n int) {
+	var resps []ErrorResponse
+	require.NoError(t, json.Unmarshal(body, &resps))
+	require.Equal(t, n, len(resps))
+	for _, resp := range resps {
+		assert.Equal(t, 0, resp.Error.Code)
+	}
+}
@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// Handler is an RPC method implementation. It receives request
+	// parameters and returns the result to be sent to the client or an
+	// error. Errors of *Error type are passed to the client as is, all
+	// the others are converted into internal server errors.
+	Handler func(reqParams Params) (interface{}, error)
+
+	// method is a registered RPC method.
+	method struct {
+		handler func(s *Server, reqParams Params) (interface{}, error)
+		counter prometheus.Counter
+	}
+)
+
+// rpcHandlers contains all the standard RPC methods supported by the server.
+var rpcHandlers = map[string]method{
+	"getaccountstate": {func(s *Server, ps Params) (interface{}, error) {
+		return s.getAccountState(ps, false)
+	}, getaccountstateCalled},
+	"getapplicationlog":  {(*Server).getApplicationLog, getapplicationlogCalled},
+	"getassetstate":      {(*Server).getAssetState, getassetstateCalled},
+	"getbestblockhash":   {(*Server).getBestBlockHash, getbestblockhashCalled},
+	"getblock":           {(*Server).getBlock, getbestblockCalled},
+	"getblockcount":      {(*Server).getBlockCount, getblockcountCalled},
+	"getblockhash":       {(*Server).getBlockHash, getblockHashCalled},
+	"getblockheader":     {(*Server).getBlockHeader, getblockheaderCalled},
+	"getblocksysfee":     {(*Server).getBlockSysFee, getblocksysfeeCalled},
+	"getclaimable":       {(*Server).getClaimable, getclaimableCalled},
+	"getconnectioncount": {(*Server).getConnectionCount, getconnectioncountCalled},
+	"getcontractstate":   {(*Server).getContractState, getcontractstateCalled},
+	"getmempoolentry":    {(*Server).getMempoolEntry, getmempoolentryCalled},
+	"getnep5balances":    {(*Server).getNEP5Balances, getnep5balancesCalled},
+	"getnep5transfers":   {(*Server).getNEP5Transfers, getnep5transfersCalled},
+	"getpeers":           {(*Server).getPeers, getpeersCalled},
+	"getrawmempool":      {(*Server).getRawMempool, getrawmempoolCalled},
+	"getrawtransaction":  {(*Server).getrawtransaction, getrawtransactionCalled},
+	"getproof":           {(*Server).getProof, getproofCalled},
+	"getstateroot":       {(*Server).getStateRoot, getstaterootCalled},
+	"getstorage":         {(*Server).getStorage, getstorageCalled},
+	"gettxout":           {(*Server).getTxOut, gettxoutCalled},
+	"getunclaimed":       {(*Server).getUnclaimed, getunclaimedCalled},
+	"getunspents": {func(s *Server, ps Params) (interface{}, error) {
+		return s.getAccountState(ps, true)
+	}, getunspentsCalled},
+	"getvalidators":      {(*Server).getValidators, getvalidatorsCalled},
+	"getversion":         {(*Server).getVersion, getversionCalled},
+	"findstorage":        {(*Server).findStorage, findstorageCalled},
+	"invoke":             {(*Server).invoke, invokeCalled},
+	"invokefunction":     {(*Server).invokeFunction, invokefunctionCalled},
+	"invokescript":       {(*Server).invokescript, invokescriptCalled},
+	"sendrawtransaction": {(*Server).sendrawtransaction, sendrawtransactionCalled},
+	"submitblock":        {(*Server).submitBlock, submitblockCalled},
+	"tracetransaction":   {(*Server).traceTransaction, tracetransactionCalled},
+	"validateaddress":    {(*Server).validateAddress, validateaddressCalled},
+}
+
+// wsOnlyMethods are methods that are handled separately for websocket
+// connections, they can't be overridden.
+var wsOnlyMethods = []string{"subscribe", "unsubscribe"}
+
+// RegisterMethod adds a custom RPC method to the server. Each call of the
+// method increments `neogo_<name>_called` Prometheus counter. Names of the
+// standard methods can't be reused. It's not safe for concurrent use and must
+// be called before the server is started.
+func (s *Server) RegisterMethod(name string, h Handler) error {
+	if h == nil {
+		return errors.New("nil handler")
+	}
+	if _, ok := s.methods[name]; ok || containsString(wsOnlyMethods, name) {
+		return errors.Errorf("method '%s' is already registered", name)
+	}
+	counter, err := newMethodCounter(name)
+	if err != nil {
+		return err
+	}
+	s.methods[name] = method{
+		handler: func(_ *Server, reqParams Params) (interface{}, error) {
+			return h(reqParams)
+		},
+		counter: counter,
+	}
+	return nil
+}
+
+// newMethodCounter creates and registers a call counter for the method
+// specified, an already registered one is reused (as there can be several
+// servers with the same method).
+func newMethodCounter(name string) (prometheus.Counter, error) {
+	counter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      fmt.Sprintf("Number of calls to %s rpc endpoint", name),
+			Name:      name + "_called",
+			Namespace: "neogo",
+		},
+	)
+	err := prometheus.Register(counter)
+	if err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return nil, errors.Wrapf(err, "can't register counter for '%s'", name)
+		}
+		existing, ok := are.ExistingCollector.(prometheus.Counter)
+		if !ok {
+			return nil, errors.Errorf("'%s' metric is already registered with a different type", name)
+		}
+		counter = existing
+	}
+	return counter, nil
+}
@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterMethod(t *testing.T) {
+	chain, rpcServer := initClearServerWithInMemoryChain(t)
+	defer chain.Close()
+	handler := http.HandlerFunc(rpcServer.requestHandler)
+
+	require.NoError(t, rpcServer.RegisterMethod("concat", func(ps Params) (interface{}, error) {
+		var (
+			s string
+			n int
+		)
+		if err := ps.Decode(&s, &n); err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errors.New("negative count")
+		}
+		var res string
+		for i := 0; i < n; i++ {
+			res += s
+		}
+		return res, nil
+	}))
+
+	require.Error(t, rpcServer.RegisterMethod("concat", func(Params) (interface{}, error) { return nil, nil }))
+	require.Error(t, rpcServer.RegisterMethod("getblockcount", func(Params) (interface{}, error) { return nil, nil }))
+	require.Error(t, rpcServer.RegisterMethod("subscribe", func(Params) (interface{}, error) { return nil, nil }))
+	require.Error(t, rpcServer.RegisterMethod("other", nil))
+	require.Error(t, rpcServer.RegisterMethod("bad-name", func(Params) (interface{}, error) { return nil, nil }))
+
+	body := doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": "concat", "params": ["ab", 3]}`, handler, t)
+	checkErrResponse(t, body, false)
+	var res struct {
+		Result string `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(body, &res))
+	assert.Equal(t, "ababab", res.Result)
+
+	for _, params := range []string{`[]`, `["ab"]`, `[3, "ab"]`, `["ab", -1]`} {
+		body = doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": "concat", "params": `+params+`}`, handler, t)
+		checkErrResponse(t, body, true)
+	}
+
+	body = doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": "getblockcount", "params": []}`, handler, t)
+	checkErrResponse(t, body, false)
+
+	// Counters are shared between servers.
+	anotherChain, another := initClearServerWithInMemoryChain(t)
+	defer anotherChain.Close()
+	require.NoError(t, another.RegisterMethod("concat", func(Params) (interface{}, error) { return nil, nil }))
+}
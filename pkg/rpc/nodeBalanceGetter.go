@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/CityOfZion/neo-go/pkg/core/state"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	errs "github.com/pkg/errors"
+)
+
+type (
+	// InputSelection is a strategy of choosing unspent outputs to be used
+	// as transaction inputs.
+	InputSelection byte
+
+	// NodeBalanceGetter is a BalanceGetter that uses `getunspents` RPC call
+	// of the node the client is connected to.
+	NodeBalanceGetter struct {
+		client    *Client
+		selection InputSelection
+	}
+)
+
+const (
+	// SmallestFirst selects outputs starting from the smallest one until
+	// the required amount is reached.
+	SmallestFirst InputSelection = iota
+	// LargestFirst selects outputs starting from the largest one until the
+	// required amount is reached, it produces transactions with the
+	// minimum number of inputs.
+	LargestFirst
+	// ExactMatch selects a single output with exactly the required value if
+	// there is one (so that no change is needed), otherwise it works the
+	// same way LargestFirst does.
+	ExactMatch
+)
+
+// NewNodeBalanceGetter creates a BalanceGetter using the given client and
+// input selection strategy.
+func NewNodeBalanceGetter(c *Client, selection InputSelection) *NodeBalanceGetter {
+	return &NodeBalanceGetter{
+		client:    c,
+		selection: selection,
+	}
+}
+
+// CalculateInputs implements BalanceGetter interface.
+func (b *NodeBalanceGetter) CalculateInputs(address string, assetID util.Uint256, amount util.Fixed8) ([]transaction.Input, util.Fixed8, error) {
+	var utxos state.UnspentBalances
+
+	resp, err := b.client.GetUnspents(address)
+	if err != nil || resp.Error != nil {
+		if err == nil {
+			err = fmt.Errorf("remote returned %d: %s", resp.Error.Code, resp.Error.Message)
+		}
+		return nil, util.Fixed8(0), errs.Wrapf(err, "cannot get balance for address %v", address)
+	}
+	for _, ubi := range resp.Result.Balance {
+		if assetID.Equals(ubi.AssetHash) {
+			utxos = ubi.Unspents
+			break
+		}
+	}
+	return selectInputs(utxos, amount, b.selection)
+}
+
+// selectInputs chooses unspent outputs containing the required amount of
+// asset using the given strategy and creates transaction inputs for them.
+// The choice only depends on the set of outputs, not on their order.
+func selectInputs(utxos state.UnspentBalances, required util.Fixed8, selection InputSelection) ([]transaction.Input, util.Fixed8, error) {
+	sorted := make(state.UnspentBalances, len(utxos))
+	copy(sorted, utxos)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Value != sorted[j].Value {
+			if selection == SmallestFirst {
+				return sorted[i].Value < sorted[j].Value
+			}
+			return sorted[i].Value > sorted[j].Value
+		}
+		if c := sorted[i].Tx.CompareTo(sorted[j].Tx); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	if selection == ExactMatch && required > 0 {
+		for i := range sorted {
+			if sorted[i].Value == required {
+				sorted = sorted[i : i+1]
+				break
+			}
+		}
+	}
+
+	var (
+		num      int
+		selected = util.Fixed8(0)
+	)
+	for _, us := range sorted {
+		if selected >= required {
+			break
+		}
+		selected += us.Value
+		num++
+	}
+	if selected < required {
+		return nil, util.Fixed8(0), errors.New("cannot compose inputs for transaction; check sender balance")
+	}
+
+	inputs := make([]transaction.Input, 0, num)
+	for i := 0; i < num; i++ {
+		inputs = append(inputs, transaction.Input{
+			PrevHash:  sorted[i].Tx,
+			PrevIndex: sorted[i].Index,
+		})
+	}
+
+	return inputs, selected, nil
+}
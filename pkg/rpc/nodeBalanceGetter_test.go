@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/CityOfZion/neo-go/pkg/core/state"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectInputs(t *testing.T) {
+	utxos := state.UnspentBalances{
+		{Tx: util.Uint256{1}, Index: 0, Value: util.Fixed8FromInt64(5)},
+		{Tx: util.Uint256{2}, Index: 1, Value: util.Fixed8FromInt64(1)},
+		{Tx: util.Uint256{3}, Index: 0, Value: util.Fixed8FromInt64(3)},
+		{Tx: util.Uint256{2}, Index: 0, Value: util.Fixed8FromInt64(1)},
+	}
+	input := func(i int) transaction.Input {
+		return transaction.Input{PrevHash: utxos[i].Tx, PrevIndex: utxos[i].Index}
+	}
+	reversed := make(state.UnspentBalances, len(utxos))
+	for i := range utxos {
+		reversed[len(utxos)-1-i] = utxos[i]
+	}
+
+	testCases := []struct {
+		name      string
+		selection InputSelection
+		required  int64
+		inputs    []transaction.Input
+		total     int64
+	}{
+		{"smallest first", SmallestFirst, 2, []transaction.Input{input(3), input(1)}, 2},
+		{"smallest first, more", SmallestFirst, 4, []transaction.Input{input(3), input(1), input(2)}, 5},
+		{"largest first", LargestFirst, 2, []transaction.Input{input(0)}, 5},
+		{"largest first, more", LargestFirst, 7, []transaction.Input{input(0), input(2)}, 8},
+		{"exact match", ExactMatch, 3, []transaction.Input{input(2)}, 3},
+		{"exact match, fallback", ExactMatch, 4, []transaction.Input{input(0)}, 5},
+		{"zero", LargestFirst, 0, []transaction.Input{}, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, us := range []state.UnspentBalances{utxos, reversed} {
+				inputs, total, err := selectInputs(us, util.Fixed8FromInt64(tc.required), tc.selection)
+				require.NoError(t, err)
+				assert.Equal(t, tc.inputs, inputs)
+				assert.Equal(t, util.Fixed8FromInt64(tc.total), total)
+			}
+		})
+	}
+
+	// Original slice is not modified.
+	assert.Equal(t, util.Uint256{1}, utxos[0].Tx)
+
+	for _, sel := range []InputSelection{SmallestFirst, LargestFirst, ExactMatch} {
+		_, _, err := selectInputs(utxos, util.Fixed8FromInt64(11), sel)
+		require.Error(t, err)
+	}
+}
+
+func TestNodeBalanceGetter(t *testing.T) {
+	_, c, cleanup := initClientWithInMemoryChain(t)
+	defer cleanup()
+
+	const addr = "AZ81H31DMWzbSnFDLFkzh9vHwaDLayV7fU"
+	resp, err := c.GetUnspents(addr)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	require.Equal(t, 1, len(resp.Result.Balance))
+	balance := resp.Result.Balance[0]
+
+	// Node-backed getter is the default one.
+	b, ok := c.Balancer().(*NodeBalanceGetter)
+	require.True(t, ok)
+	require.Equal(t, SmallestFirst, b.selection)
+
+	for _, sel := range []InputSelection{SmallestFirst, LargestFirst, ExactMatch} {
+		b := NewNodeBalanceGetter(c, sel)
+		inputs, total, err := b.CalculateInputs(addr, balance.AssetHash, balance.Amount)
+		require.NoError(t, err)
+		assert.Equal(t, balance.Amount, total)
+		require.Equal(t, 1, len(inputs))
+		assert.Equal(t, balance.Unspents[0].Tx, inputs[0].PrevHash)
+
+		_, _, err = b.CalculateInputs(addr, balance.AssetHash, balance.Amount+1)
+		require.Error(t, err)
+		_, _, err = b.CalculateInputs(addr, util.Uint256{1, 2, 3}, 1)
+		require.Error(t, err)
+		_, _, err = b.CalculateInputs("notanaddress", balance.AssetHash, 1)
+		require.Error(t, err)
+	}
+}
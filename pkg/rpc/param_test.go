@@ -181,3 +181,36 @@ func TestParamGetBytesHex(t *testing.T) {
 	_, err = p.GetBytesHex()
 	require.NotNil(t, err)
 }
+
+func TestParamsDecode(t *testing.T) {
+	var (
+		u160, _ = util.Uint160DecodeStringLE("50befd26fdf6e4d957c11e078b24ebce6291456f")
+		u256, _ = util.Uint256DecodeStringLE("602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7")
+		addr    = address.Uint160ToString(u160)
+		ps      Params
+	)
+	require.NoError(t, json.Unmarshal([]byte(`["str", 42, "`+u256.StringLE()+`", "`+u160.StringLE()+`", "`+addr+`", "0a0b", [1, 2]]`), &ps))
+
+	var (
+		s        string
+		i        int
+		h256     util.Uint256
+		h160     util.Uint160
+		fromAddr util.Uint160
+		b        []byte
+		arr      []Param
+	)
+	require.NoError(t, ps.Decode(&s, &i, &h256, &h160, &fromAddr, &b, &arr))
+	assert.Equal(t, "str", s)
+	assert.Equal(t, 42, i)
+	assert.Equal(t, u256, h256)
+	assert.Equal(t, u160, h160)
+	assert.Equal(t, u160, fromAddr)
+	assert.Equal(t, []byte{0x0a, 0x0b}, b)
+	assert.Equal(t, 2, len(arr))
+
+	require.Error(t, ps.Decode(&i))
+	require.Error(t, ps.Decode(&s, &s))
+	require.Error(t, ps[:1].Decode(&s, &i))
+	require.Error(t, ps.Decode(&struct{}{}))
+}
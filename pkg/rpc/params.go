@@ -1,5 +1,12 @@
 package rpc
 
+import (
+	"fmt"
+
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/pkg/errors"
+)
+
 type (
 	// Params represents the JSON-RPC params.
 	Params []Param
@@ -24,3 +31,52 @@ func (p Params) ValueWithType(index int, valType paramType) (*Param, bool) {
 
 	return nil, false
 }
+
+// Decode decodes parameters into values pointed to by vals (in the same
+// order), there must be at least as many parameters as values given.
+// Supported value types are *string, *int, *[]byte (hex-encoded string),
+// *util.Uint256, *util.Uint160 (hex-encoded string or address), *FuncParam,
+// *[]Param and *Param. Invalid parameters error is returned if some parameter
+// can't be decoded.
+func (p Params) Decode(vals ...interface{}) error {
+	for i, v := range vals {
+		param, ok := p.Value(i)
+		if !ok {
+			return NewInvalidParamsError(fmt.Sprintf("Param at index %d is missing", i), nil)
+		}
+		if err := param.decode(v); err != nil {
+			return NewInvalidParamsError(fmt.Sprintf("Param at index %d: %s", i, err), err)
+		}
+	}
+	return nil
+}
+
+// decode decodes parameter value into the value pointed to by v.
+func (p Param) decode(v interface{}) error {
+	var err error
+
+	switch val := v.(type) {
+	case *string:
+		*val, err = p.GetString()
+	case *int:
+		*val, err = p.GetInt()
+	case *[]byte:
+		*val, err = p.GetBytesHex()
+	case *util.Uint256:
+		*val, err = p.GetUint256()
+	case *util.Uint160:
+		*val, err = p.GetUint160FromHex()
+		if err != nil {
+			*val, err = p.GetUint160FromAddress()
+		}
+	case *FuncParam:
+		*val, err = p.GetFuncParam()
+	case *[]Param:
+		*val, err = p.GetArray()
+	case *Param:
+		*val = p
+	default:
+		err = errors.Errorf("unsupported type %T", v)
+	}
+	return err
+}
@@ -84,6 +84,166 @@ var (
 		},
 	)
 
+	getapplicationlogCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getapplicationlog rpc endpoint",
+			Name:      "getapplicationlog_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getnep5balancesCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getnep5balances rpc endpoint",
+			Name:      "getnep5balances_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getnep5transfersCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getnep5transfers rpc endpoint",
+			Name:      "getnep5transfers_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getrawmempoolCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getrawmempool rpc endpoint",
+			Name:      "getrawmempool_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getmempoolentryCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getmempoolentry rpc endpoint",
+			Name:      "getmempoolentry_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getcontractstateCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getcontractstate rpc endpoint",
+			Name:      "getcontractstate_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getstorageCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getstorage rpc endpoint",
+			Name:      "getstorage_called",
+			Namespace: "neogo",
+		},
+	)
+
+	findstorageCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to findstorage rpc endpoint",
+			Name:      "findstorage_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getstaterootCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getstateroot rpc endpoint",
+			Name:      "getstateroot_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getproofCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getproof rpc endpoint",
+			Name:      "getproof_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getblockheaderCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getblockheader rpc endpoint",
+			Name:      "getblockheader_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getblocksysfeeCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getblocksysfee rpc endpoint",
+			Name:      "getblocksysfee_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getclaimableCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getclaimable rpc endpoint",
+			Name:      "getclaimable_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getunclaimedCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getunclaimed rpc endpoint",
+			Name:      "getunclaimed_called",
+			Namespace: "neogo",
+		},
+	)
+
+	gettxoutCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to gettxout rpc endpoint",
+			Name:      "gettxout_called",
+			Namespace: "neogo",
+		},
+	)
+
+	getvalidatorsCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to getvalidators rpc endpoint",
+			Name:      "getvalidators_called",
+			Namespace: "neogo",
+		},
+	)
+
+	submitblockCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to submitblock rpc endpoint",
+			Name:      "submitblock_called",
+			Namespace: "neogo",
+		},
+	)
+
+	invokeCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to invoke rpc endpoint",
+			Name:      "invoke_called",
+			Namespace: "neogo",
+		},
+	)
+
+	invokefunctionCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to invokefunction rpc endpoint",
+			Name:      "invokefunction_called",
+			Namespace: "neogo",
+		},
+	)
+
+	invokescriptCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to invokescript rpc endpoint",
+			Name:      "invokescript_called",
+			Namespace: "neogo",
+		},
+	)
+
 	getrawtransactionCalled = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Help:      "Number of calls to getrawtransaction rpc endpoint",
@@ -107,6 +267,14 @@ var (
 			Namespace: "neogo",
 		},
 	)
+
+	tracetransactionCalled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of calls to tracetransaction rpc endpoint",
+			Name:      "tracetransaction_called",
+			Namespace: "neogo",
+		},
+	)
 )
 
 func init() {
@@ -121,8 +289,29 @@ func init() {
 		validateaddressCalled,
 		getassetstateCalled,
 		getaccountstateCalled,
+		getapplicationlogCalled,
+		getnep5balancesCalled,
+		getnep5transfersCalled,
 		getunspentsCalled,
+		getrawmempoolCalled,
+		getmempoolentryCalled,
+		getcontractstateCalled,
+		getstorageCalled,
+		findstorageCalled,
+		getstaterootCalled,
+		getproofCalled,
+		getblockheaderCalled,
+		getblocksysfeeCalled,
+		getclaimableCalled,
+		getunclaimedCalled,
+		gettxoutCalled,
+		getvalidatorsCalled,
+		submitblockCalled,
+		invokeCalled,
+		invokefunctionCalled,
+		invokescriptCalled,
 		getrawtransactionCalled,
 		sendrawtransactionCalled,
+		tracetransactionCalled,
 	)
 }
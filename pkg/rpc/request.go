@@ -1,8 +1,10 @@
 package rpc
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -21,6 +23,10 @@ type (
 		Method    string          `json:"method"`
 		RawParams json.RawMessage `json:"params,omitempty"`
 		RawID     json.RawMessage `json:"id,omitempty"`
+
+		// decodeErr is set for requests that couldn't be decoded from
+		// a batch.
+		decodeErr error
 	}
 
 	// Response represents a standard JSON-RPC 2.0
@@ -57,6 +63,56 @@ func (r *Request) DecodeData(data io.ReadCloser) error {
 	return nil
 }
 
+// DecodeRequests decodes the given reader into a list of requests. It
+// accepts both single requests and batches of them (JSON arrays of requests),
+// the second value returned is true in the latter case. Version of the
+// requests decoded is not checked.
+func DecodeRequests(data io.ReadCloser) ([]*Request, bool, error) {
+	defer data.Close()
+
+	raw, err := ioutil.ReadAll(data)
+	if err != nil {
+		return nil, false, errors.Errorf("error reading JSON payload: %s", err)
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '[' {
+		req := &Request{}
+		if err := json.NewDecoder(bytes.NewReader(raw)).Decode(req); err != nil {
+			return nil, false, errors.Errorf("error parsing JSON payload: %s", err)
+		}
+		return []*Request{req}, false, nil
+	}
+
+	var rawReqs []json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(raw)).Decode(&rawReqs); err != nil {
+		return nil, true, errors.Errorf("error parsing JSON batch payload: %s", err)
+	}
+	reqs := make([]*Request, len(rawReqs))
+	for i := range rawReqs {
+		req := &Request{}
+		err := json.Unmarshal(rawReqs[i], req)
+		if err == nil && bytes.Equal(rawReqs[i], []byte("null")) {
+			err = errors.New("null request")
+		}
+		if err != nil {
+			// Invalid batch elements are answered with the null id.
+			req = &Request{
+				JSONRPC:   jsonRPCVersion,
+				RawID:     json.RawMessage("null"),
+				decodeErr: errors.Errorf("error parsing batch element %d: %s", i, err),
+			}
+		}
+		reqs[i] = req
+	}
+	return reqs, true, nil
+}
+
+// IsNotification returns true if the request has no id and thus doesn't
+// expect any response.
+func (r *Request) IsNotification() bool {
+	return r.RawID == nil
+}
+
 // Params takes a slice of any type and attempts to bind
 // the params to it.
 func (r *Request) Params() (*Params, error) {
@@ -72,6 +128,19 @@ func (r *Request) Params() (*Params, error) {
 
 // WriteErrorResponse writes an error response to the ResponseWriter.
 func (s *Server) WriteErrorResponse(r *Request, w http.ResponseWriter, err error) {
+	response := s.packErrorResponse(r, err)
+	w.WriteHeader(response.Error.HTTPCode)
+	s.writeServerResponse(r, w, response)
+}
+
+// WriteResponse encodes the response and writes it to the ResponseWriter.
+func (s *Server) WriteResponse(r *Request, w http.ResponseWriter, result interface{}) {
+	s.writeServerResponse(r, w, s.packResponse(r, result))
+}
+
+// packErrorResponse creates an error response for the given request and logs
+// the error.
+func (s *Server) packErrorResponse(r *Request, err error) Response {
 	jsonErr, ok := err.(*Error)
 	if !ok {
 		jsonErr = NewInternalServerError("Internal server error", err)
@@ -94,23 +163,21 @@ func (s *Server) WriteErrorResponse(r *Request, w http.ResponseWriter, err error
 	}
 
 	s.log.Error("Error encountered with rpc request", logFields...)
-
-	w.WriteHeader(jsonErr.HTTPCode)
-	s.writeServerResponse(r, w, response)
+	return response
 }
 
-// WriteResponse encodes the response and writes it to the ResponseWriter.
-func (s *Server) WriteResponse(r *Request, w http.ResponseWriter, result interface{}) {
-	response := Response{
+// packResponse creates a successful response for the given request.
+func (s *Server) packResponse(r *Request, result interface{}) Response {
+	return Response{
 		JSONRPC: r.JSONRPC,
 		Result:  result,
 		ID:      r.RawID,
 	}
-
-	s.writeServerResponse(r, w, response)
 }
 
-func (s *Server) writeServerResponse(r *Request, w http.ResponseWriter, response Response) {
+// writeServerResponse writes the given response (which can be a single
+// Response or a batch of them) to the ResponseWriter.
+func (s *Server) writeServerResponse(r *Request, w http.ResponseWriter, response interface{}) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	if s.config.EnableCORSWorkaround {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
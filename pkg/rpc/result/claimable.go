@@ -0,0 +1,38 @@
+package result
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+type (
+	// ClaimableInfo is a result of the getclaimable RPC call.
+	ClaimableInfo struct {
+		Spents    []Claimable `json:"claimable"`
+		Address   string      `json:"address"`
+		Unclaimed util.Fixed8 `json:"unclaimed"`
+	}
+
+	// Claimable represents a spent NEO output which can be claimed along with
+	// the amount of GAS it has generated.
+	Claimable struct {
+		Tx          util.Uint256 `json:"txid"`
+		N           int          `json:"n"`
+		Value       util.Fixed8  `json:"value"`
+		StartHeight uint32       `json:"start_height"`
+		EndHeight   uint32       `json:"end_height"`
+		Generated   util.Fixed8  `json:"generated"`
+		SysFee      util.Fixed8  `json:"sys_fee"`
+		Unclaimed   util.Fixed8  `json:"unclaimed"`
+	}
+
+	// Unclaimed is a result of the getunclaimed RPC call.
+	Unclaimed struct {
+		// Available is the amount of GAS that can be claimed right away
+		// (generated by spent NEO outputs).
+		Available util.Fixed8 `json:"available"`
+		// Unavailable is the amount of GAS generated by unspent NEO outputs,
+		// it can only be claimed after spending them.
+		Unavailable util.Fixed8 `json:"unavailable"`
+		Unclaimed   util.Fixed8 `json:"unclaimed"`
+	}
+)
@@ -0,0 +1,37 @@
+package result
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+type (
+	// NEP5Balances is a result for the getnep5balances RPC call.
+	NEP5Balances struct {
+		Balances []NEP5Balance `json:"balance"`
+		Address  string        `json:"address"`
+	}
+
+	// NEP5Balance is a structure holding balance of a NEP5 asset.
+	NEP5Balance struct {
+		Asset       util.Uint160 `json:"asset_hash"`
+		Amount      string       `json:"amount"`
+		LastUpdated uint32       `json:"last_updated_block"`
+	}
+
+	// NEP5Transfers is a result for the getnep5transfers RPC.
+	NEP5Transfers struct {
+		Sent     []NEP5Transfer `json:"sent"`
+		Received []NEP5Transfer `json:"received"`
+		Address  string         `json:"address"`
+	}
+
+	// NEP5Transfer represents single NEP5 transfer event.
+	NEP5Transfer struct {
+		Timestamp uint32       `json:"timestamp"`
+		Asset     util.Uint160 `json:"asset_hash"`
+		Address   string       `json:"transfer_address,omitempty"`
+		Amount    string       `json:"amount"`
+		Index     uint32       `json:"block_index"`
+		TxHash    util.Uint256 `json:"tx_hash"`
+	}
+)
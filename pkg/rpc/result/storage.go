@@ -0,0 +1,37 @@
+package result
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+type (
+	// FoundStorage is a result of the findstorage RPC call.
+	FoundStorage struct {
+		Results []KeyValue `json:"results"`
+		// Next is the index of the first item of the next page, it's only
+		// meaningful if Truncated is set.
+		Next      int  `json:"next"`
+		Truncated bool `json:"truncated"`
+	}
+
+	// StateRoot is a result of the getstateroot RPC call.
+	StateRoot struct {
+		Index uint32       `json:"index"`
+		Root  util.Uint256 `json:"stateroot"`
+	}
+
+	// Proof is a result of the getproof RPC call. Key is the hex-encoded
+	// key of the storage item in the state and Proof is the list of
+	// hex-encoded serialized MPT nodes from the root to the item, they can
+	// be checked with mpt.VerifyProof.
+	Proof struct {
+		Key   string   `json:"key"`
+		Proof []string `json:"proof"`
+	}
+
+	// KeyValue represents a single hex-encoded storage key-value pair.
+	KeyValue struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+)
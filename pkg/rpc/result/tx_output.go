@@ -0,0 +1,25 @@
+package result
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/encoding/address"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+// TransactionOutput is a wrapper to represent transaction's output.
+type TransactionOutput struct {
+	N       int          `json:"n"`
+	Asset   util.Uint256 `json:"asset"`
+	Value   util.Fixed8  `json:"value"`
+	Address string       `json:"address"`
+}
+
+// NewTxOutput converts out to a TransactionOutput.
+func NewTxOutput(out *transaction.Output) *TransactionOutput {
+	return &TransactionOutput{
+		N:       out.Position,
+		Asset:   out.AssetID,
+		Value:   out.Amount,
+		Address: address.Uint160ToString(out.ScriptHash),
+	}
+}
@@ -0,0 +1,15 @@
+package result
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/crypto/keys"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+// Validator is used for the representation of
+// state.Validator on the RPC Server.
+type Validator struct {
+	PublicKey  keys.PublicKey `json:"publickey"`
+	Votes      util.Fixed8    `json:"votes"`
+	Registered bool           `json:"registered"`
+	Active     bool           `json:"active"`
+}
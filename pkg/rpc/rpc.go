@@ -2,31 +2,213 @@ package rpc
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 
+	"github.com/CityOfZion/neo-go/pkg/core/block"
 	"github.com/CityOfZion/neo-go/pkg/core/transaction"
 	"github.com/CityOfZion/neo-go/pkg/crypto/keys"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/rpc/wrappers"
 	"github.com/CityOfZion/neo-go/pkg/smartcontract"
+	"github.com/CityOfZion/neo-go/pkg/smartcontract/trigger"
 	"github.com/CityOfZion/neo-go/pkg/util"
 	"github.com/pkg/errors"
 )
 
-// getBlock returns a block by its hash or index/height. If verbose is true
-// the response will contain a pretty Block object instead of the raw hex string.
-// missing output wrapper at the moment, thus commented out
-// func (c *Client) getBlock(indexOrHash interface{}, verbose bool) (*response, error) {
-// 	var (
-// 		params = newParams(indexOrHash)
-// 		resp   = &response{}
-// 	)
-// 	if verbose {
-// 		params = newParams(indexOrHash, 1)
-// 	}
-// 	if err := c.performRequest("getblock", params, resp); err != nil {
-// 		return nil, err
-// 	}
-// 	return resp, nil
-// }
+// GetBestBlockHash returns the hash of the tallest block in the main chain.
+func (c *Client) GetBestBlockHash() (*HashResponse, error) {
+	var (
+		params = newParams()
+		resp   = &HashResponse{}
+	)
+	if err := c.performRequest("getbestblockhash", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBlockCount returns the number of blocks in the main chain.
+func (c *Client) GetBlockCount() (*BlockCountResponse, error) {
+	var (
+		params = newParams()
+		resp   = &BlockCountResponse{}
+	)
+	if err := c.performRequest("getblockcount", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBlockHash returns the hash of the block with the given index.
+func (c *Client) GetBlockHash(index uint32) (*HashResponse, error) {
+	var (
+		params = newParams(index)
+		resp   = &HashResponse{}
+	)
+	if err := c.performRequest("getblockhash", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBlock returns a block by its hash.
+func (c *Client) GetBlock(hash string) (*BlockResponse, error) {
+	return c.getBlock(newParams(hash))
+}
+
+// GetBlockByIndex returns a block by its index/height.
+func (c *Client) GetBlockByIndex(index uint32) (*BlockResponse, error) {
+	return c.getBlock(newParams(index))
+}
+
+func (c *Client) getBlock(params params) (*BlockResponse, error) {
+	var (
+		raw  = &rawResponse{}
+		resp = &BlockResponse{}
+	)
+	if err := c.performRequest("getblock", params, raw); err != nil {
+		return nil, err
+	}
+	resp.responseHeader = raw.responseHeader
+	resp.Error = raw.Error
+	if raw.Error == nil {
+		resp.Result = new(block.Block)
+		if err := decodeRawResult(raw.Result, resp.Result); err != nil {
+			return nil, errors.Wrap(err, "failed to decode block")
+		}
+	}
+	return resp, nil
+}
+
+// GetBlockVerbose returns a block with additional metadata by its hash.
+func (c *Client) GetBlockVerbose(hash string) (*BlockVerboseResponse, error) {
+	var (
+		params = newParams(hash, 1)
+		resp   = &BlockVerboseResponse{}
+	)
+	if err := c.performRequest("getblock", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBlockByIndexVerbose returns a block with additional metadata by its
+// index/height.
+func (c *Client) GetBlockByIndexVerbose(index uint32) (*BlockVerboseResponse, error) {
+	var (
+		params = newParams(index, 1)
+		resp   = &BlockVerboseResponse{}
+	)
+	if err := c.performRequest("getblock", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetRawTransaction returns a transaction by its hash.
+func (c *Client) GetRawTransaction(hash string) (*RawTransactionResponse, error) {
+	var (
+		params = newParams(hash)
+		raw    = &rawResponse{}
+		resp   = &RawTransactionResponse{}
+	)
+	if err := c.performRequest("getrawtransaction", params, raw); err != nil {
+		return nil, err
+	}
+	resp.responseHeader = raw.responseHeader
+	resp.Error = raw.Error
+	if raw.Error == nil {
+		resp.Result = new(transaction.Transaction)
+		if err := decodeRawResult(raw.Result, resp.Result); err != nil {
+			return nil, errors.Wrap(err, "failed to decode transaction")
+		}
+	}
+	return resp, nil
+}
+
+// GetRawTransactionVerbose returns a transaction with additional metadata
+// by its hash.
+func (c *Client) GetRawTransactionVerbose(hash string) (*RawTransactionVerboseResponse, error) {
+	var (
+		params = newParams(hash, 1)
+		resp   = &RawTransactionVerboseResponse{}
+	)
+	if err := c.performRequest("getrawtransaction", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetAssetState returns information about the asset with the given id.
+func (c *Client) GetAssetState(id string) (*AssetStateResponse, error) {
+	var (
+		params = newParams(id)
+		raw    = &rawResponse{}
+		resp   = &AssetStateResponse{}
+	)
+	if err := c.performRequest("getassetstate", params, raw); err != nil {
+		return nil, err
+	}
+	resp.responseHeader = raw.responseHeader
+	resp.Error = raw.Error
+	// Server returns a string instead of an object for unknown assets.
+	if raw.Error == nil && len(raw.Result) != 0 && raw.Result[0] == '{' {
+		resp.Result = new(wrappers.AssetState)
+		if err := json.Unmarshal(raw.Result, resp.Result); err != nil {
+			return nil, errors.Wrap(err, "failed to decode asset state")
+		}
+	}
+	return resp, nil
+}
+
+// GetConnectionCount returns the number of peers connected to the node.
+func (c *Client) GetConnectionCount() (*ConnectionCountResponse, error) {
+	var (
+		params = newParams()
+		resp   = &ConnectionCountResponse{}
+	)
+	if err := c.performRequest("getconnectioncount", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetPeers returns lists of connected, unconnected and bad peers of the node.
+func (c *Client) GetPeers() (*PeersResponse, error) {
+	var (
+		params = newParams()
+		resp   = &PeersResponse{}
+	)
+	if err := c.performRequest("getpeers", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetVersion returns the version information about the node.
+func (c *Client) GetVersion() (*VersionResponse, error) {
+	var (
+		params = newParams()
+		resp   = &VersionResponse{}
+	)
+	if err := c.performRequest("getversion", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ValidateAddress checks whether the given string is a valid NEO address.
+func (c *Client) ValidateAddress(address string) (*ValidateAddressResponse, error) {
+	var (
+		params = newParams(address)
+		resp   = &ValidateAddressResponse{}
+	)
+	if err := c.performRequest("validateaddress", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
 
 // GetAccountState returns detailed information about a NEO account.
 func (c *Client) GetAccountState(address string) (*AccountStateResponse, error) {
@@ -40,6 +222,298 @@ func (c *Client) GetAccountState(address string) (*AccountStateResponse, error)
 	return resp, nil
 }
 
+// GetAccountStateAt returns detailed information about a NEO account after
+// processing the block with the given index, it requires archival node.
+func (c *Client) GetAccountStateAt(address string, height uint32) (*AccountStateResponse, error) {
+	var (
+		params = newParams(address, height)
+		resp   = &AccountStateResponse{}
+	)
+	if err := c.performRequest("getaccountstate", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetApplicationLog returns the contract log based on the specified txid.
+func (c *Client) GetApplicationLog(hash string) (*ApplicationLogResponse, error) {
+	var (
+		params = newParams(hash)
+		resp   = &ApplicationLogResponse{}
+	)
+	if err := c.performRequest("getapplicationlog", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// TraceTransaction re-executes the invocation transaction with the specified
+// txid and returns its execution log along with the trace of instructions
+// executed.
+func (c *Client) TraceTransaction(hash string) (*TransactionTraceResponse, error) {
+	var (
+		params = newParams(hash)
+		resp   = &TransactionTraceResponse{}
+	)
+	if err := c.performRequest("tracetransaction", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNEP5Balances returns NEP5 balances for the specified address.
+func (c *Client) GetNEP5Balances(address string) (*NEP5BalancesResponse, error) {
+	var (
+		params = newParams(address)
+		resp   = &NEP5BalancesResponse{}
+	)
+	if err := c.performRequest("getnep5balances", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetNEP5Transfers returns NEP5 transfers for the specified address. Optional
+// start and end timestamps (in seconds) limit the time range of transfers.
+func (c *Client) GetNEP5Transfers(address string, timestamps ...uint32) (*NEP5TransfersResponse, error) {
+	vals := []interface{}{address}
+	for _, ts := range timestamps {
+		vals = append(vals, ts)
+	}
+	var (
+		params = newParams(vals...)
+		resp   = &NEP5TransfersResponse{}
+	)
+	if err := c.performRequest("getnep5transfers", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetRawMempool returns hashes of all verified transactions in the node's
+// memory pool.
+func (c *Client) GetRawMempool() (*RawMempoolResponse, error) {
+	var (
+		params = newParams()
+		resp   = &RawMempoolResponse{}
+	)
+	if err := c.performRequest("getrawmempool", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetRawMempoolVerbose returns fee-related data of all verified transactions
+// in the node's memory pool.
+func (c *Client) GetRawMempoolVerbose() (*RawMempoolVerboseResponse, error) {
+	var (
+		params = newParams(1)
+		resp   = &RawMempoolVerboseResponse{}
+	)
+	if err := c.performRequest("getrawmempool", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMempoolEntry returns fee-related data of the memory pool transaction
+// with the specified hash.
+func (c *Client) GetMempoolEntry(hash string) (*MempoolEntryResponse, error) {
+	var (
+		params = newParams(hash)
+		resp   = &MempoolEntryResponse{}
+	)
+	if err := c.performRequest("getmempoolentry", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBlockHeader returns verbose representation of the block header with the
+// specified hash.
+func (c *Client) GetBlockHeader(hash string) (*BlockHeaderResponse, error) {
+	var (
+		params = newParams(hash, 1)
+		resp   = &BlockHeaderResponse{}
+	)
+	if err := c.performRequest("getblockheader", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetBlockSysFee returns the cumulative system fee amount of all blocks up to
+// (and including) the block with the specified index.
+func (c *Client) GetBlockSysFee(index uint32) (*BlockSysFeeResponse, error) {
+	var (
+		params = newParams(index)
+		resp   = &BlockSysFeeResponse{}
+	)
+	if err := c.performRequest("getblocksysfee", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetClaimable returns spent NEO outputs of the specified address that can be
+// claimed along with the amount of GAS they've generated.
+func (c *Client) GetClaimable(address string) (*ClaimableResponse, error) {
+	var (
+		params = newParams(address)
+		resp   = &ClaimableResponse{}
+	)
+	if err := c.performRequest("getclaimable", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetUnclaimed returns the amount of GAS available and unavailable for claim
+// for the specified address.
+func (c *Client) GetUnclaimed(address string) (*UnclaimedResponse, error) {
+	var (
+		params = newParams(address)
+		resp   = &UnclaimedResponse{}
+	)
+	if err := c.performRequest("getunclaimed", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetContractState returns contract state of the contract with the specified
+// script hash.
+func (c *Client) GetContractState(scriptHash string) (*ContractStateResponse, error) {
+	var (
+		params = newParams(scriptHash)
+		resp   = &ContractStateResponse{}
+	)
+	if err := c.performRequest("getcontractstate", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetContractStateAt returns contract state of the contract with the specified
+// script hash after processing the block with the given index, it requires
+// archival node.
+func (c *Client) GetContractStateAt(scriptHash string, height uint32) (*ContractStateResponse, error) {
+	var (
+		params = newParams(scriptHash, height)
+		resp   = &ContractStateResponse{}
+	)
+	if err := c.performRequest("getcontractstate", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStorage returns hex-encoded storage value of the specified contract
+// by the given hex-encoded key.
+func (c *Client) GetStorage(scriptHash string, key string) (*StorageResponse, error) {
+	var (
+		params = newParams(scriptHash, key)
+		resp   = &StorageResponse{}
+	)
+	if err := c.performRequest("getstorage", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStorageAt returns hex-encoded storage value of the specified contract
+// by the given hex-encoded key after processing the block with the given
+// index, it requires archival node.
+func (c *Client) GetStorageAt(scriptHash string, key string, height uint32) (*StorageResponse, error) {
+	var (
+		params = newParams(scriptHash, key, height)
+		resp   = &StorageResponse{}
+	)
+	if err := c.performRequest("getstorage", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FindStorage returns a page of storage items of the specified contract whose
+// keys start with the given hex-encoded prefix, start is the index of the
+// first item to return.
+func (c *Client) FindStorage(scriptHash string, prefix string, start int) (*FindStorageResponse, error) {
+	var (
+		params = newParams(scriptHash, prefix, start)
+		resp   = &FindStorageResponse{}
+	)
+	if err := c.performRequest("findstorage", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStateRoot returns the state root after processing the block with the
+// given index.
+func (c *Client) GetStateRoot(index int) (*StateRootResponse, error) {
+	var (
+		params = newParams(index)
+		resp   = &StateRootResponse{}
+	)
+	if err := c.performRequest("getstateroot", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetProof returns a proof of the storage item of the specified contract with
+// the given hex-encoded key in the state with the given root.
+func (c *Client) GetProof(root string, scriptHash string, key string) (*ProofResponse, error) {
+	var (
+		params = newParams(root, scriptHash, key)
+		resp   = &ProofResponse{}
+	)
+	if err := c.performRequest("getproof", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetTxOut returns the specified unspent transaction output. Result is nil
+// if the output is already spent.
+func (c *Client) GetTxOut(hash string, n int) (*TxOutResponse, error) {
+	var (
+		params = newParams(hash, n)
+		resp   = &TxOutResponse{}
+	)
+	if err := c.performRequest("gettxout", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetTxOutAt returns the specified transaction output if it was unspent after
+// processing the block with the given index, it requires archival node.
+func (c *Client) GetTxOutAt(hash string, n int, height uint32) (*TxOutResponse, error) {
+	var (
+		params = newParams(hash, n, height)
+		resp   = &TxOutResponse{}
+	)
+	if err := c.performRequest("gettxout", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetValidators returns information about all validator candidates and their
+// voting status.
+func (c *Client) GetValidators() (*ValidatorsResponse, error) {
+	var (
+		params = newParams()
+		resp   = &ValidatorsResponse{}
+	)
+	if err := c.performRequest("getvalidators", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetUnspents returns UTXOs for the given NEO account.
 func (c *Client) GetUnspents(address string) (*UnspentResponse, error) {
 	var (
@@ -52,67 +526,90 @@ func (c *Client) GetUnspents(address string) (*UnspentResponse, error) {
 	return resp, nil
 }
 
-// InvokeScript returns the result of the given script after running it true the VM.
-// NOTE: This is a test invoke and will not affect the blockchain.
-func (c *Client) InvokeScript(script string) (*InvokeScriptResponse, error) {
+// GetUnspentsAt returns UTXOs for the given NEO account after processing the
+// block with the given index, it requires archival node.
+func (c *Client) GetUnspentsAt(address string, height uint32) (*UnspentResponse, error) {
 	var (
-		params = newParams(script)
-		resp   = &InvokeScriptResponse{}
+		params = newParams(address, height)
+		resp   = &UnspentResponse{}
 	)
-	if err := c.performRequest("invokescript", params, resp); err != nil {
+	if err := c.performRequest("getunspents", params, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+// InvokeScript returns the result of the given script after running it true the VM.
+// Optional signers are treated as verified by CheckWitness during execution.
+// NOTE: This is a test invoke and will not affect the blockchain.
+func (c *Client) InvokeScript(script string, signers ...util.Uint160) (*InvokeScriptResponse, error) {
+	return c.invoke("invokescript", false, signers, script)
+}
+
+// TraceInvokeScript is the same as InvokeScript, but the result also contains
+// the execution trace of the script.
+func (c *Client) TraceInvokeScript(script string, signers ...util.Uint160) (*InvokeScriptResponse, error) {
+	return c.invoke("invokescript", true, signers, script)
+}
+
 // InvokeFunction returns the results after calling the smart contract scripthash
-// with the given operation and parameters.
+// with the given operation and parameters. Optional signers are treated as
+// verified by CheckWitness during execution.
 // NOTE: this is test invoke and will not affect the blockchain.
-func (c *Client) InvokeFunction(script, operation string, params []smartcontract.Parameter) (*InvokeScriptResponse, error) {
-	var (
-		p    = newParams(script, operation, params)
-		resp = &InvokeScriptResponse{}
-	)
-	if err := c.performRequest("invokefunction", p, resp); err != nil {
-		return nil, err
-	}
-	return resp, nil
+func (c *Client) InvokeFunction(script, operation string, params []smartcontract.Parameter, signers ...util.Uint160) (*InvokeScriptResponse, error) {
+	return c.invoke("invokefunction", false, signers, script, operation, params)
+}
+
+// TraceInvokeFunction is the same as InvokeFunction, but the result also
+// contains the execution trace of the script.
+func (c *Client) TraceInvokeFunction(script, operation string, params []smartcontract.Parameter, signers ...util.Uint160) (*InvokeScriptResponse, error) {
+	return c.invoke("invokefunction", true, signers, script, operation, params)
 }
 
 // Invoke returns the results after calling the smart contract scripthash
-// with the given parameters.
-func (c *Client) Invoke(script string, params []smartcontract.Parameter) (*InvokeScriptResponse, error) {
+// with the given parameters. Optional signers are treated as verified by
+// CheckWitness during execution.
+func (c *Client) Invoke(script string, params []smartcontract.Parameter, signers ...util.Uint160) (*InvokeScriptResponse, error) {
+	return c.invoke("invoke", false, signers, script, params)
+}
+
+// TraceInvoke is the same as Invoke, but the result also contains the
+// execution trace of the script.
+func (c *Client) TraceInvoke(script string, params []smartcontract.Parameter, signers ...util.Uint160) (*InvokeScriptResponse, error) {
+	return c.invoke("invoke", true, signers, script, params)
+}
+
+// invoke performs one of invoke* calls with the given parameters appending
+// signers list and trace flag to them if needed.
+func (c *Client) invoke(method string, trace bool, signers []util.Uint160, vals ...interface{}) (*InvokeScriptResponse, error) {
+	if len(signers) != 0 || trace {
+		hashes := make([]string, len(signers))
+		for i := range signers {
+			hashes[i] = signers[i].StringLE()
+		}
+		vals = append(vals, hashes)
+	}
+	if trace {
+		vals = append(vals, trigger.String(trigger.Application), 1)
+	}
 	var (
-		p    = newParams(script, params)
+		p    = newParams(vals...)
 		resp = &InvokeScriptResponse{}
 	)
-	if err := c.performRequest("invoke", p, resp); err != nil {
+	if err := c.performRequest(method, p, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-// getRawTransaction queries a transaction by hash.
-// missing output wrapper at the moment, thus commented out
-// func (c *Client) getRawTransaction(hash string, verbose bool) (*response, error) {
-// 	var (
-// 		params = newParams(hash, verbose)
-// 		resp   = &response{}
-// 	)
-// 	if err := c.performRequest("getrawtransaction", params, resp); err != nil {
-// 		return nil, err
-// 	}
-// 	return resp, nil
-// }
-
-// sendRawTransaction broadcasts a transaction over the NEO network.
+// SendRawTransaction broadcasts a transaction over the NEO network.
 // The given hex string needs to be signed with a keypair.
 // When the result of the response object is true, the TX has successfully
 // been broadcasted to the network.
-func (c *Client) sendRawTransaction(rawTX *transaction.Transaction) (*response, error) {
+func (c *Client) SendRawTransaction(rawTX *transaction.Transaction) (*SendRawTransactionResponse, error) {
 	var (
 		params = newParams(hex.EncodeToString(rawTX.Bytes()))
-		resp   = &response{}
+		resp   = &SendRawTransactionResponse{}
 	)
 	if err := c.performRequest("sendrawtransaction", params, resp); err != nil {
 		return nil, err
@@ -120,6 +617,24 @@ func (c *Client) sendRawTransaction(rawTX *transaction.Transaction) (*response,
 	return resp, nil
 }
 
+// SubmitBlock broadcasts a raw block over the NEO network. The block is
+// fully verified by the node before being accepted.
+func (c *Client) SubmitBlock(b *block.Block) (*SubmitBlockResponse, error) {
+	var (
+		buf  = io.NewBufBinWriter()
+		resp = &SubmitBlockResponse{}
+	)
+	b.EncodeBinary(buf.BinWriter)
+	if buf.Err != nil {
+		return nil, buf.Err
+	}
+	params := newParams(hex.EncodeToString(buf.Bytes()))
+	if err := c.performRequest("submitblock", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // SendToAddress sends an amount of specific asset to a given address.
 // This call requires open wallet. (`wif` key in client struct.)
 // If response.Result is `true` then transaction was formed correctly and was written in blockchain.
@@ -134,14 +649,14 @@ func (c *Client) SendToAddress(asset util.Uint256, address string, amount util.F
 			wif:      c.WIF(),
 			balancer: c.Balancer(),
 		}
-		resp     *response
+		resp     *SendRawTransactionResponse
 		response = &SendToAddressResponse{}
 	)
 
 	if rawTx, err = CreateRawContractTransaction(txParams); err != nil {
 		return nil, errors.Wrap(err, "failed to create raw transaction for `sendtoaddress`")
 	}
-	if resp, err = c.sendRawTransaction(rawTx); err != nil {
+	if resp, err = c.SendRawTransaction(rawTx); err != nil {
 		return nil, errors.Wrap(err, "failed to send raw transaction")
 	}
 	response.Error = resp.Error
@@ -177,7 +692,7 @@ func (c *Client) SignAndPushInvocationTx(script []byte, wif *keys.WIF, gas util.
 		return txHash, errors.Wrap(err, "failed to sign tx")
 	}
 	txHash = tx.Hash()
-	resp, err := c.sendRawTransaction(tx)
+	resp, err := c.SendRawTransaction(tx)
 
 	if err != nil {
 		return txHash, errors.Wrap(err, "failed sendning tx")
@@ -187,3 +702,18 @@ func (c *Client) SignAndPushInvocationTx(script []byte, wif *keys.WIF, gas util.
 	}
 	return txHash, nil
 }
+
+// decodeRawResult decodes hex-encoded binary result into the given value.
+func decodeRawResult(result json.RawMessage, v io.Serializable) error {
+	var s string
+	if err := json.Unmarshal(result, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	r := io.NewBinReaderFromBuf(b)
+	v.DecodeBinary(r)
+	return r.Err
+}
@@ -1,22 +1,40 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/CityOfZion/neo-go/config"
 	"github.com/CityOfZion/neo-go/pkg/core"
+	"github.com/CityOfZion/neo-go/pkg/core/block"
 	"github.com/CityOfZion/neo-go/pkg/core/state"
 	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/crypto/keys"
+	"github.com/CityOfZion/neo-go/pkg/encoding/address"
 	"github.com/CityOfZion/neo-go/pkg/io"
 	"github.com/CityOfZion/neo-go/pkg/network"
 	"github.com/CityOfZion/neo-go/pkg/rpc/result"
 	"github.com/CityOfZion/neo-go/pkg/rpc/wrappers"
+	"github.com/CityOfZion/neo-go/pkg/smartcontract/trigger"
 	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm"
+	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
 
@@ -28,9 +46,72 @@ type (
 		config     config.RPCConfig
 		coreServer *network.Server
 		log        *zap.Logger
+		shutdown   chan struct{}
+		// shutdownOnce guards shutdown channel closing.
+		shutdownOnce sync.Once
+		methods      map[string]method
+		limiter      *rateLimiter
+		// invocations is a semaphore for VM-backed calls, it's nil
+		// if there is no limit.
+		invocations chan struct{}
+
+		subsLock       sync.RWMutex
+		subscribers    map[*subscriber]bool
+		blockCh        chan *block.Block
+		executionCh    chan *state.AppExecResult
+		notificationCh chan *state.NotificationEvent
+		transactionCh  chan *transaction.Transaction
+		// chainSubLock protects chainSubscribed, the server subscribes to
+		// chain events only when the first websocket client connects.
+		chainSubLock    sync.Mutex
+		chainSubscribed bool
+		// subEventsStarted is set when handleSubEvents is started and
+		// subEventsToExitCh is closed when it exits.
+		subEventsStarted  atomic.Bool
+		subEventsToExitCh chan struct{}
+	}
+
+	// invocation holds optional parameters of invoke* calls.
+	invocation struct {
+		trigger byte
+		signers []util.Uint160
+		trace   bool
 	}
 )
 
+// nep5TransfersDefaultRange is the default time range (in seconds) used by
+// getnep5transfers when no start timestamp is given.
+const nep5TransfersDefaultRange = 7 * 24 * 60 * 60
+
+// maxFindStorageResults is the maximum number of storage items returned by a
+// single findstorage call.
+const maxFindStorageResults = 50
+
+// defaultMaxBatchSize is the maximum number of requests in a single batch
+// used when it's not specified in the configuration.
+const defaultMaxBatchSize = 100
+
+// maxInvocationTraceLength is the maximum number of instructions returned in
+// the execution trace of invoke* calls.
+const maxInvocationTraceLength = 16 * 1024
+
+const (
+	// maxSubscribers is the maximum number of simultaneous websocket
+	// connections.
+	maxSubscribers = 64
+	// notificationBufSize is the size of the per-connection outgoing
+	// message queue, events are dropped (and event_missed is sent) when
+	// it's full.
+	notificationBufSize = 1024
+	// wsPongLimit is the time limit for the client's pong reply.
+	wsPongLimit = 60 * time.Second
+	// wsPingPeriod is the period of pings sent to the client, it must be
+	// less than wsPongLimit.
+	wsPingPeriod = wsPongLimit / 2
+	// wsWriteLimit is the time limit for writing a single message.
+	wsWriteLimit = wsPingPeriod / 2
+)
+
 var invalidBlockHeightError = func(index int, height int) error {
 	return errors.Errorf("Param at index %d should be greater than or equal to 0 and less then or equal to current block height, got: %d", index, height)
 }
@@ -41,274 +122,1420 @@ func NewServer(chain core.Blockchainer, conf config.RPCConfig, coreServer *netwo
 		Addr: conf.Address + ":" + strconv.FormatUint(uint64(conf.Port), 10),
 	}
 
-	return Server{
-		Server:     httpServer,
-		chain:      chain,
-		config:     conf,
-		coreServer: coreServer,
-		log:        log,
+	methods := make(map[string]method, len(rpcHandlers))
+	for name, m := range rpcHandlers {
+		methods[name] = m
+	}
+
+	var invocations chan struct{}
+	if conf.MaxConcurrentInvocations > 0 {
+		invocations = make(chan struct{}, conf.MaxConcurrentInvocations)
+	}
+
+	return Server{
+		Server:     httpServer,
+		chain:      chain,
+		config:     conf,
+		coreServer: coreServer,
+		log:        log,
+		shutdown:   make(chan struct{}),
+		methods:    methods,
+		limiter:    newRateLimiter(conf.RateLimit),
+
+		invocations: invocations,
+
+		subscribers:       make(map[*subscriber]bool),
+		subEventsToExitCh: make(chan struct{}),
+		// These are NOT buffered to preserve original order of events.
+		blockCh:        make(chan *block.Block),
+		executionCh:    make(chan *state.AppExecResult),
+		notificationCh: make(chan *state.NotificationEvent),
+		transactionCh:  make(chan *transaction.Transaction),
+	}
+}
+
+// Start creates a new JSON-RPC server
+// listening on the configured port.
+func (s *Server) Start(errChan chan error) {
+	if !s.config.Enabled {
+		s.log.Info("RPC server is not enabled")
+		return
+	}
+	s.Handler = http.HandlerFunc(s.requestHandler)
+	go s.handleSubEvents()
+	if !s.config.TLSConfig.Enabled {
+		s.log.Info("starting rpc-server", zap.String("endpoint", s.Addr))
+		errChan <- s.ListenAndServe()
+		return
+	}
+
+	tlsConfig, err := newServerTLSConfig(s.config.TLSConfig)
+	if err != nil {
+		errChan <- err
+		return
+	}
+	s.TLSConfig = tlsConfig
+	s.log.Info("starting rpc-server (https)", zap.String("endpoint", s.Addr))
+	errChan <- s.ListenAndServeTLS(s.config.TLSConfig.CertFile, s.config.TLSConfig.KeyFile)
+}
+
+// newServerTLSConfig creates TLS configuration for the server. Certificate and
+// key are loaded by the http.Server itself, so this only sets up client
+// certificate verification if it's enabled.
+func newServerTLSConfig(cfg config.RPCTLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("TLS is enabled, but certificate or key file is not specified")
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if cfg.ClientCAFile != "" {
+		pool, err := loadCertPool(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// loadCertPool creates a certificate pool from the given PEM file.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pemData, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read CA file %s", file)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, errors.Errorf("no valid certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// Shutdown overrides the http.Server Shutdown
+// method. It also waits for the server to unsubscribe from chain events, so
+// the chain can be closed after it returns.
+func (s *Server) Shutdown() error {
+	s.log.Info("shutting down rpc-server", zap.String("endpoint", s.Addr))
+	// Websocket connections are hijacked, so they're not closed by the
+	// http.Server, the shutdown channel signals them to exit.
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+	err := s.Server.Shutdown(context.Background())
+	if s.subEventsStarted.Load() {
+		<-s.subEventsToExitCh
+	}
+	return err
+}
+
+func (s *Server) requestHandler(w http.ResponseWriter, httpRequest *http.Request) {
+	req := NewRequest()
+	addr := clientAddr(httpRequest)
+
+	if httpRequest.URL.Path == "/ws" && httpRequest.Method == "GET" {
+		if err := s.checkRate(addr, 1); err != nil {
+			s.WriteErrorResponse(req, w, err)
+			return
+		}
+		user, ok := s.authenticateHTTP(w, httpRequest)
+		if ok {
+			s.handleWsConn(w, httpRequest, user, addr)
+		}
+		return
+	}
+
+	if httpRequest.Method != "POST" {
+		s.WriteErrorResponse(
+			req,
+			w,
+			NewInvalidParamsError(
+				fmt.Sprintf("Invalid method '%s', please retry with 'POST'", httpRequest.Method), nil,
+			),
+		)
+		return
+	}
+
+	maxBodySize := s.maxRequestBodySize()
+	if httpRequest.ContentLength > int64(maxBodySize) {
+		s.WriteErrorResponse(req, w, NewInvalidRequestError(
+			fmt.Sprintf("Request body is too big, the limit is %d bytes", maxBodySize), nil))
+		return
+	}
+	// The request is accounted for before decoding it, so that malformed
+	// requests are limited too.
+	if err := s.checkRate(addr, 1); err != nil {
+		s.WriteErrorResponse(req, w, err)
+		return
+	}
+	httpRequest.Body = http.MaxBytesReader(w, httpRequest.Body, int64(maxBodySize))
+	reqs, isBatch, err := DecodeRequests(httpRequest.Body)
+	if err != nil {
+		s.WriteErrorResponse(req, w, NewParseError("Problem parsing JSON-RPC request body", err))
+		return
+	}
+
+	// Every request of a batch counts against the rate limit.
+	if err := s.checkBatchRate(addr, len(reqs)); err != nil {
+		s.WriteErrorResponse(req, w, err)
+		return
+	}
+	user, ok := s.authenticateHTTP(w, httpRequest)
+	if !ok {
+		return
+	}
+
+	if !isBatch {
+		req = reqs[0]
+		resp := s.handleRequest(req, user, nil)
+		if req.IsNotification() {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if resp.Error != nil {
+			w.WriteHeader(resp.Error.HTTPCode)
+		}
+		s.writeServerResponse(req, w, resp)
+		return
+	}
+
+	if err := s.checkBatch(reqs); err != nil {
+		s.WriteErrorResponse(req, w, err)
+		return
+	}
+	batchResp := s.handleBatch(reqs, user, nil)
+	if len(batchResp) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.writeServerResponse(req, w, batchResp)
+}
+
+// authenticateHTTP authenticates the user making the request, it writes an
+// error response and returns false if credentials are invalid.
+func (s *Server) authenticateHTTP(w http.ResponseWriter, httpRequest *http.Request) (*config.RPCUser, bool) {
+	user, err := s.authenticate(httpRequest)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="neo-go", charset="UTF-8"`)
+		s.WriteErrorResponse(NewRequest(), w, NewUnauthorizedError("Invalid credentials", err))
+		return nil, false
+	}
+	return user, true
+}
+
+// checkBatch checks whether the batch of requests given can be processed.
+func (s *Server) checkBatch(reqs []*Request) error {
+	if len(reqs) == 0 {
+		return NewInvalidRequestError("Empty batch request", nil)
+	}
+	if maxSize := s.maxBatchSize(); len(reqs) > maxSize {
+		return NewInvalidRequestError(
+			fmt.Sprintf("Batch is too big: %d requests, the limit is %d", len(reqs), maxSize), nil)
+	}
+	return nil
+}
+
+// handleBatch processes the batch of requests concurrently and returns
+// responses for all of them except notifications in the original order.
+func (s *Server) handleBatch(reqs []*Request, user *config.RPCUser, sub *subscriber) []Response {
+	var (
+		resps = make([]*Response, len(reqs))
+		wg    sync.WaitGroup
+	)
+	wg.Add(len(reqs))
+	for i := range reqs {
+		go func(i int) {
+			defer wg.Done()
+			resp := s.handleRequest(reqs[i], user, sub)
+			if !reqs[i].IsNotification() {
+				resps[i] = &resp
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var batchResp = make([]Response, 0, len(resps))
+	for _, resp := range resps {
+		if resp != nil {
+			batchResp = append(batchResp, *resp)
+		}
+	}
+	return batchResp
+}
+
+// handleWsConn upgrades the given connection to websocket and serves it.
+func (s *Server) handleWsConn(w http.ResponseWriter, httpRequest *http.Request, user *config.RPCUser, addr string) {
+	s.subsLock.RLock()
+	numOfSubs := len(s.subscribers)
+	s.subsLock.RUnlock()
+	if numOfSubs >= maxSubscribers {
+		s.WriteErrorResponse(NewRequest(), w, NewInternalServerError("websocket users limit reached", nil))
+		return
+	}
+	upgrader := websocket.Upgrader{}
+	if s.config.EnableCORSWorkaround {
+		upgrader.CheckOrigin = func(*http.Request) bool { return true }
+	}
+	ws, err := upgrader.Upgrade(w, httpRequest, nil)
+	if err != nil {
+		s.log.Info("websocket connection upgrade failed", zap.Error(err))
+		return
+	}
+	writer := make(chan *websocket.PreparedMessage, notificationBufSize)
+	subscr := &subscriber{writer: writer, ws: ws, user: user, addr: addr, done: make(chan struct{})}
+	s.subsLock.Lock()
+	s.subscribers[subscr] = true
+	s.subsLock.Unlock()
+	s.subscribeToChain()
+
+	writerDone := make(chan struct{})
+	go s.handleWsWrites(ws, writer, subscr, writerDone)
+	s.handleWsReads(ws, writer, subscr, writerDone)
+}
+
+// handleWsWrites is a websocket writer routine. It sends all responses and
+// events queued for the subscriber and pings the client periodically.
+func (s *Server) handleWsWrites(ws *websocket.Conn, writer <-chan *websocket.PreparedMessage, subscr *subscriber, writerDone chan<- struct{}) {
+	pingTicker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		pingTicker.Stop()
+		ws.Close()
+		close(writerDone)
+	}()
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case <-subscr.done:
+			return
+		case msg := <-writer:
+			if err := ws.SetWriteDeadline(time.Now().Add(wsWriteLimit)); err != nil {
+				return
+			}
+			if err := ws.WritePreparedMessage(msg); err != nil {
+				return
+			}
+		case <-pingTicker.C:
+			if err := ws.SetWriteDeadline(time.Now().Add(wsWriteLimit)); err != nil {
+				return
+			}
+			if err := ws.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleWsReads is a websocket reader routine. It reads and processes
+// JSON-RPC requests (single or batched) and queues responses for them.
+func (s *Server) handleWsReads(ws *websocket.Conn, writer chan<- *websocket.PreparedMessage, subscr *subscriber, writerDone <-chan struct{}) {
+	ws.SetReadLimit(int64(s.maxRequestBodySize()))
+	err := ws.SetReadDeadline(time.Now().Add(wsPongLimit))
+	ws.SetPongHandler(func(string) error { return ws.SetReadDeadline(time.Now().Add(wsPongLimit)) })
+	for err == nil {
+		var data []byte
+		_, data, err = ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		var (
+			resp    interface{}
+			reqs    []*Request
+			isBatch bool
+			decErr  error
+		)
+		// The message is accounted for before decoding it, so that
+		// malformed messages are limited too.
+		rateErr := s.checkRate(subscr.addr, 1)
+		if rateErr == nil {
+			reqs, isBatch, decErr = DecodeRequests(ioutil.NopCloser(bytes.NewReader(data)))
+			if decErr == nil {
+				rateErr = s.checkBatchRate(subscr.addr, len(reqs))
+			}
+		}
+		switch {
+		case decErr != nil:
+			resp = s.packErrorResponse(NewRequest(), NewParseError("Problem parsing JSON-RPC request body", decErr))
+		case rateErr != nil:
+			resp = s.packErrorResponse(NewRequest(), rateErr)
+		case !isBatch:
+			r := s.handleRequest(reqs[0], subscr.user, subscr)
+			if !reqs[0].IsNotification() {
+				resp = r
+			}
+		default:
+			if batchErr := s.checkBatch(reqs); batchErr != nil {
+				resp = s.packErrorResponse(NewRequest(), batchErr)
+			} else if batchResp := s.handleBatch(reqs, subscr.user, subscr); len(batchResp) != 0 {
+				resp = batchResp
+			}
+		}
+		if resp == nil {
+			continue
+		}
+		var msg *websocket.PreparedMessage
+		msg, err = prepareWsMessage(resp)
+		if err != nil {
+			s.log.Error("failed to encode websocket response", zap.Error(err))
+			break
+		}
+		select {
+		case writer <- msg:
+		case <-writerDone:
+			err = errors.New("writer is closed")
+		}
+	}
+	s.subsLock.Lock()
+	delete(s.subscribers, subscr)
+	s.subsLock.Unlock()
+	close(subscr.done)
+	ws.Close()
+}
+
+// prepareWsMessage encodes the given value into a websocket text message.
+func prepareWsMessage(v interface{}) (*websocket.PreparedMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return websocket.NewPreparedMessage(websocket.TextMessage, b)
+}
+
+// subscribe handles subscription requests from websocket clients.
+func (s *Server) subscribe(reqParams Params, sub *subscriber) (interface{}, error) {
+	p, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	streamName, err := p.GetString()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	event, err := GetEventIDFromString(streamName)
+	if err != nil || event == MissedEventID {
+		return nil, errInvalidParams
+	}
+	var contract *util.Uint160
+	if p, ok := reqParams.Value(1); ok {
+		if event != NotificationEventID {
+			return nil, NewInvalidParamsError("filters are only supported for notifications", nil)
+		}
+		if p.Type != stringT {
+			return nil, errInvalidParams
+		}
+		u, err := p.GetUint160FromHex()
+		if err != nil {
+			return nil, errInvalidParams
+		}
+		contract = &u
+	}
+
+	s.subsLock.Lock()
+	defer s.subsLock.Unlock()
+	for id := range sub.feeds {
+		if sub.feeds[id].event == InvalidEventID {
+			sub.feeds[id] = feed{event: event, contract: contract}
+			return strconv.FormatInt(int64(id), 10), nil
+		}
+	}
+	return nil, NewInternalServerError("maximum number of subscriptions is reached", nil)
+}
+
+// unsubscribe handles unsubscription requests from websocket clients.
+func (s *Server) unsubscribe(reqParams Params, sub *subscriber) (interface{}, error) {
+	p, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	str, err := p.GetString()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	id, err := strconv.Atoi(str)
+	if err != nil || id < 0 || id >= len(sub.feeds) {
+		return nil, errInvalidParams
+	}
+
+	s.subsLock.Lock()
+	defer s.subsLock.Unlock()
+	if sub.feeds[id].event == InvalidEventID {
+		return nil, errInvalidParams
+	}
+	sub.feeds[id] = feed{}
+	return true, nil
+}
+
+// handleSubEvents receives chain events and broadcasts them to websocket
+// subscribers. Slow subscribers don't block it, if subscriber's queue is full
+// the event is dropped and event_missed notification is queued instead.
+func (s *Server) handleSubEvents() {
+	s.subEventsStarted.Store(true)
+	defer close(s.subEventsToExitCh)
+	overflowMsg, err := prepareWsMessage(Notification{
+		JSONRPC: jsonRPCVersion,
+		Event:   MissedEventID,
+		Payload: make([]interface{}, 0),
+	})
+	if err != nil {
+		s.log.Error("fatal: failed to prepare overflow message", zap.Error(err))
+		return
+	}
+
+chloop:
+	for {
+		var (
+			resp = Notification{
+				JSONRPC: jsonRPCVersion,
+				Payload: make([]interface{}, 1),
+			}
+			contract *util.Uint160
+		)
+		select {
+		case <-s.shutdown:
+			break chloop
+		case b := <-s.blockCh:
+			resp.Event = BlockEventID
+			resp.Payload[0] = wrappers.NewBlock(b, s.chain)
+		case aer := <-s.executionCh:
+			resp.Event = ExecutionEventID
+			resp.Payload[0] = s.newExecutionLog(aer)
+		case n := <-s.notificationCh:
+			resp.Event = NotificationEventID
+			resp.Payload[0] = wrappers.NotificationEvent{
+				Contract: n.ScriptHash,
+				Item:     n.Item.ToContractParameter(make(map[vm.StackItem]bool)),
+			}
+			contract = &n.ScriptHash
+		case tx := <-s.transactionCh:
+			resp.Event = TransactionEventID
+			resp.Payload[0] = tx
+		}
+		msg, err := prepareWsMessage(resp)
+		if err != nil {
+			s.log.Error("failed to encode event", zap.Stringer("event", resp.Event), zap.Error(err))
+			continue
+		}
+		s.subsLock.RLock()
+		for sub := range s.subscribers {
+			if sub.overflown.Load() {
+				continue
+			}
+			for i := range sub.feeds {
+				if !sub.feeds[i].matches(resp.Event, contract) {
+					continue
+				}
+				select {
+				case sub.writer <- msg:
+				default:
+					sub.overflown.Store(true)
+					// MissedEvent is to be delivered eventually.
+					go func(sub *subscriber) {
+						select {
+						case sub.writer <- overflowMsg:
+						case <-sub.done:
+						}
+						sub.overflown.Store(false)
+					}(sub)
+				}
+				// The message is sent only once per subscriber.
+				break
+			}
+		}
+		s.subsLock.RUnlock()
+	}
+	s.unsubscribeFromChain()
+}
+
+// subscribeToChain subscribes the server to chain events if it's not yet
+// subscribed, events are received by handleSubEvents. It does nothing after
+// the server is shut down.
+func (s *Server) subscribeToChain() {
+	s.chainSubLock.Lock()
+	defer s.chainSubLock.Unlock()
+	if s.chainSubscribed {
+		return
+	}
+	select {
+	case <-s.shutdown:
+		return
+	default:
+	}
+	s.chain.SubscribeForBlocks(s.blockCh)
+	s.chain.SubscribeForExecutions(s.executionCh)
+	s.chain.SubscribeForNotifications(s.notificationCh)
+	s.chain.SubscribeForTransactions(s.transactionCh)
+	s.chainSubscribed = true
+}
+
+// unsubscribeFromChain unsubscribes the server from chain events (if it's
+// subscribed) draining event channels to avoid blocking the chain.
+func (s *Server) unsubscribeFromChain() {
+	s.chainSubLock.Lock()
+	defer s.chainSubLock.Unlock()
+	if !s.chainSubscribed {
+		return
+	}
+	s.chainSubscribed = false
+	done := make(chan struct{})
+	go func() {
+		s.chain.UnsubscribeFromBlocks(s.blockCh)
+		s.chain.UnsubscribeFromExecutions(s.executionCh)
+		s.chain.UnsubscribeFromNotifications(s.notificationCh)
+		s.chain.UnsubscribeFromTransactions(s.transactionCh)
+		close(done)
+	}()
+	for {
+		select {
+		case <-s.blockCh:
+		case <-s.executionCh:
+		case <-s.notificationCh:
+		case <-s.transactionCh:
+		case <-done:
+			return
+		}
+	}
+}
+
+// newExecutionLog creates an application log for the given execution result
+// sent to transaction_executed subscribers.
+func (s *Server) newExecutionLog(aer *state.AppExecResult) wrappers.ApplicationLog {
+	var scriptHash util.Uint160
+	tx, _, err := s.chain.GetTransaction(aer.TxHash)
+	if err == nil {
+		if invocation, ok := tx.Data.(*transaction.InvocationTX); ok {
+			scriptHash = hash.Hash160(invocation.Script)
+		}
+	}
+	return wrappers.NewApplicationLog(aer, scriptHash)
+}
+
+// maxBatchSize returns the maximum number of requests allowed in a single
+// batch.
+func (s *Server) maxBatchSize() int {
+	if s.config.MaxBatchSize > 0 {
+		return s.config.MaxBatchSize
+	}
+	return defaultMaxBatchSize
+}
+
+// handleRequest processes a single request and returns the response for it.
+// User is the authenticated user making the request (nil for anonymous ones),
+// subscriber is only set for requests coming via websocket connections.
+func (s *Server) handleRequest(req *Request, user *config.RPCUser, sub *subscriber) Response {
+	if req.decodeErr != nil {
+		return s.packErrorResponse(req, NewInvalidRequestError("Problem parsing JSON-RPC request", req.decodeErr))
+	}
+	if req.JSONRPC != jsonRPCVersion {
+		return s.packErrorResponse(req, NewInvalidRequestError(
+			fmt.Sprintf("Invalid version, expected 2.0 got: '%s'", req.JSONRPC), nil))
+	}
+
+	if !s.isEnabled(req.Method) {
+		return s.packErrorResponse(req, NewMethodNotFoundError(
+			fmt.Sprintf("Method '%s' is disabled", req.Method), nil))
+	}
+
+	if !s.isAllowed(req.Method, user) {
+		return s.packErrorResponse(req, NewUnauthorizedError(
+			fmt.Sprintf("Method '%s' requires authentication", req.Method), nil))
+	}
+
+	reqParams, err := req.Params()
+	if err != nil {
+		return s.packErrorResponse(req, NewInvalidParamsError("Problem parsing request parameters", err))
+	}
+
+	var results interface{}
+	switch {
+	case sub != nil && req.Method == "subscribe":
+		results, err = s.subscribe(*reqParams, sub)
+	case sub != nil && req.Method == "unsubscribe":
+		results, err = s.unsubscribe(*reqParams, sub)
+	default:
+		results, err = s.methodHandler(req, *reqParams)
+	}
+	if err != nil {
+		return s.packErrorResponse(req, err)
+	}
+	return s.packResponse(req, results)
+}
+
+func (s *Server) methodHandler(req *Request, reqParams Params) (interface{}, error) {
+	s.log.Debug("processing rpc request",
+		zap.String("method", req.Method),
+		zap.String("params", fmt.Sprintf("%v", reqParams)))
+
+	m, ok := s.methods[req.Method]
+	if !ok {
+		return nil, NewMethodNotFoundError(fmt.Sprintf("Method '%s' not supported", req.Method), nil)
+	}
+	m.counter.Inc()
+	release, err := s.acquireInvocation(req.Method)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return m.handler(s, reqParams)
+}
+
+func (s *Server) getBestBlockHash(_ Params) (interface{}, error) {
+	return "0x" + s.chain.CurrentBlockHash().StringLE(), nil
+}
+
+func (s *Server) getBlock(reqParams Params) (interface{}, error) {
+	var hash util.Uint256
+
+	param, ok := reqParams.Value(0)
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	switch param.Type {
+	case stringT:
+		var err error
+		hash, err = param.GetUint256()
+		if err != nil {
+			return nil, errInvalidParams
+		}
+	case numberT:
+		num, err := s.blockHeightFromParam(param)
+		if err != nil {
+			return nil, errInvalidParams
+		}
+		hash = s.chain.GetHeaderHash(num)
+	default:
+		return nil, errInvalidParams
+	}
+
+	block, err := s.chain.GetBlock(hash)
+	if err != nil {
+		return nil, NewInternalServerError(fmt.Sprintf("Problem locating block with hash: %s", hash), err)
+	}
+
+	if len(reqParams) == 2 && reqParams[1].Value == 1 {
+		return wrappers.NewBlock(block, s.chain), nil
+	}
+	writer := io.NewBufBinWriter()
+	block.EncodeBinary(writer.BinWriter)
+	return hex.EncodeToString(writer.Bytes()), nil
+}
+
+func (s *Server) getBlockCount(_ Params) (interface{}, error) {
+	return s.chain.BlockHeight() + 1, nil
+}
+
+func (s *Server) getBlockHash(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, numberT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	num, err := s.blockHeightFromParam(param)
+	if err != nil {
+		return nil, errInvalidParams
+	}
+
+	return s.chain.GetHeaderHash(num), nil
+}
+
+func (s *Server) getConnectionCount(_ Params) (interface{}, error) {
+	return s.coreServer.PeerCount(), nil
+}
+
+func (s *Server) getVersion(_ Params) (interface{}, error) {
+	return result.Version{
+		Port:      s.coreServer.Port,
+		Nonce:     s.coreServer.ID(),
+		UserAgent: s.coreServer.UserAgent,
+	}, nil
+}
+
+func (s *Server) getPeers(_ Params) (interface{}, error) {
+	peers := result.NewPeers()
+	for _, addr := range s.coreServer.UnconnectedPeers() {
+		peers.AddPeer("unconnected", addr)
+	}
+
+	for _, addr := range s.coreServer.BadPeers() {
+		peers.AddPeer("bad", addr)
+	}
+
+	for addr := range s.coreServer.Peers() {
+		peers.AddPeer("connected", addr.PeerAddr().String())
+	}
+
+	return peers, nil
+}
+
+func (s *Server) validateAddress(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.Value(0)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	return wrappers.ValidateAddress(param.Value), nil
+}
+
+func (s *Server) getAssetState(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	paramAssetID, err := param.GetUint256()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+
+	as := s.chain.GetAssetState(paramAssetID)
+	if as != nil {
+		return wrappers.NewAssetState(as), nil
+	}
+	return "Invalid assetid", nil
+}
+
+func (s *Server) getrawtransaction(reqParams Params) (interface{}, error) {
+	var resultsErr error
+	var results interface{}
+
+	if param0, ok := reqParams.Value(0); !ok {
+		return nil, errInvalidParams
+	} else if txHash, err := param0.GetUint256(); err != nil {
+		resultsErr = errInvalidParams
+	} else if tx, height, err := s.chain.GetTransaction(txHash); err != nil {
+		err = errors.Wrapf(err, "Invalid transaction hash: %s", txHash)
+		return nil, NewInvalidParamsError(err.Error(), err)
+	} else if len(reqParams) >= 2 {
+		_header := s.chain.GetHeaderHash(int(height))
+		header, err := s.chain.GetHeader(_header)
+		if err != nil {
+			resultsErr = NewInvalidParamsError(err.Error(), err)
+		}
+
+		param1, _ := reqParams.Value(1)
+		switch v := param1.Value.(type) {
+
+		case int, float64, bool, string:
+			if v == 0 || v == "0" || v == 0.0 || v == false || v == "false" {
+				results = hex.EncodeToString(tx.Bytes())
+			} else {
+				results = wrappers.NewTransactionOutputRaw(tx, header, s.chain)
+			}
+		default:
+			results = wrappers.NewTransactionOutputRaw(tx, header, s.chain)
+		}
+	} else {
+		results = hex.EncodeToString(tx.Bytes())
+	}
+
+	return results, resultsErr
+}
+
+// getBlockHeader returns block header identified by its hash or height either
+// in hex-encoded or in verbose (JSON) form.
+func (s *Server) getBlockHeader(reqParams Params) (interface{}, error) {
+	var hash util.Uint256
+
+	param, ok := reqParams.Value(0)
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	switch param.Type {
+	case stringT:
+		var err error
+		hash, err = param.GetUint256()
+		if err != nil {
+			return nil, errInvalidParams
+		}
+	case numberT:
+		num, err := s.blockHeightFromParam(param)
+		if err != nil {
+			return nil, errInvalidParams
+		}
+		hash = s.chain.GetHeaderHash(num)
+	default:
+		return nil, errInvalidParams
+	}
+
+	header, err := s.chain.GetHeader(hash)
+	if err != nil {
+		return nil, NewInvalidParamsError(fmt.Sprintf("Unknown block header %s", hash), err)
+	}
+
+	if len(reqParams) == 2 && reqParams[1].Value == 1 {
+		return wrappers.NewHeader(header, s.chain), nil
+	}
+	writer := io.NewBufBinWriter()
+	header.EncodeBinary(writer.BinWriter)
+	if writer.Err != nil {
+		return nil, NewInternalServerError("failed to encode header", writer.Err)
+	}
+	return hex.EncodeToString(writer.Bytes()), nil
+}
+
+// getBlockSysFee returns the cumulative system fee amount (in GAS) of all
+// blocks up to (and including) the block with the specified height.
+func (s *Server) getBlockSysFee(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, numberT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	num, err := s.blockHeightFromParam(param)
+	if err != nil {
+		return nil, NewInvalidParamsError(err.Error(), err)
+	}
+
+	headerHash := s.chain.GetHeaderHash(num)
+	return strconv.FormatUint(uint64(s.chain.GetSystemFeeAmount(headerHash)), 10), nil
+}
+
+// getRawMempool returns hashes of all verified transactions from the memory
+// pool, or their fee-related data if verbose mode is requested.
+func (s *Server) getRawMempool(reqParams Params) (interface{}, error) {
+	txs := s.chain.GetMemPool().GetVerifiedTransactions()
+	if len(reqParams) > 0 && reqParams[0].Value == 1 {
+		entries := make([]wrappers.MempoolEntry, 0, len(txs))
+		for _, tx := range txs {
+			entries = append(entries, wrappers.NewMempoolEntry(tx, s.chain))
+		}
+		return entries, nil
+	}
+
+	hashes := make([]util.Uint256, 0, len(txs))
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes, nil
+}
+
+// getMempoolEntry returns fee-related data of the memory pool transaction
+// with the specified hash.
+func (s *Server) getMempoolEntry(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	txHash, err := param.GetUint256()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+
+	tx, ok := s.chain.GetMemPool().TryGetValue(txHash)
+	if !ok {
+		return nil, NewInvalidParamsError(fmt.Sprintf("Transaction %s is not in the memory pool", txHash), nil)
+	}
+	return wrappers.NewMempoolEntry(tx, s.chain), nil
+}
+
+// getContractState returns contract state (metadata, script and parameters)
+// of the contract with the specified script hash.
+func (s *Server) getContractState(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	scriptHash, err := param.GetUint160FromHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	height, historic, err := s.historicHeight(reqParams, 1)
+	if err != nil {
+		return nil, err
+	}
+	var cs *state.Contract
+	if historic {
+		cs, err = s.chain.GetContractStateAt(scriptHash, height)
+		if err != nil {
+			return nil, NewInvalidParamsError(err.Error(), err)
+		}
+	} else {
+		cs = s.chain.GetContractState(scriptHash)
+	}
+	if cs == nil {
+		return nil, NewInvalidParamsError(fmt.Sprintf("Unknown contract %s", scriptHash), nil)
+	}
+	return wrappers.NewContractState(cs), nil
+}
+
+// getStorage returns hex-encoded storage value of the specified contract by
+// the specified key or nil if there is no such item.
+func (s *Server) getStorage(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	scriptHash, err := param.GetUint160FromHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	param, ok = reqParams.ValueWithType(1, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	key, err := param.GetBytesHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	height, historic, err := s.historicHeight(reqParams, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	var item *state.StorageItem
+	if historic {
+		item, err = s.chain.GetStorageItemAt(scriptHash, key, height)
+		if err != nil {
+			return nil, NewInvalidParamsError(err.Error(), err)
+		}
+	} else {
+		item = s.chain.GetStorageItem(scriptHash, key)
+	}
+	if item == nil {
+		return nil, nil
+	}
+	return hex.EncodeToString(item.Value), nil
+}
+
+// findStorage returns storage items of the specified contract whose keys start
+// with the specified prefix. Items are ordered by key and are returned in pages
+// of at most maxFindStorageResults elements, an optional third parameter
+// specifies the index of the first item to return.
+func (s *Server) findStorage(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	scriptHash, err := param.GetUint160FromHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	param, ok = reqParams.ValueWithType(1, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	prefix, err := param.GetBytesHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	var start int
+	if len(reqParams) > 2 {
+		param, ok = reqParams.ValueWithType(2, numberT)
+		if !ok {
+			return nil, errInvalidParams
+		}
+		start, err = param.GetInt()
+		if err != nil || start < 0 {
+			return nil, errInvalidParams
+		}
+	}
+
+	items, err := s.chain.GetStorageItemsWithPrefix(scriptHash, prefix)
+	if err != nil {
+		return nil, NewInternalServerError("failed to get storage items", err)
+	}
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := &result.FoundStorage{Results: []result.KeyValue{}}
+	for i := start; i < len(keys); i++ {
+		if len(res.Results) == maxFindStorageResults {
+			res.Truncated = true
+			res.Next = i
+			break
+		}
+		res.Results = append(res.Results, result.KeyValue{
+			Key:   hex.EncodeToString([]byte(keys[i])),
+			Value: hex.EncodeToString(items[keys[i]].Value),
+		})
+	}
+	return res, nil
+}
+
+// getStateRoot returns the state root after processing the block specified by
+// its index or hash.
+func (s *Server) getStateRoot(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.Value(0)
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	var index uint32
+	switch param.Type {
+	case stringT:
+		hash, err := param.GetUint256()
+		if err != nil {
+			return nil, errInvalidParams
+		}
+		header, err := s.chain.GetHeader(hash)
+		if err != nil {
+			return nil, NewInvalidParamsError(fmt.Sprintf("Unknown block: %s", hash), err)
+		}
+		index = header.Index
+	case numberT:
+		num, err := s.blockHeightFromParam(param)
+		if err != nil {
+			return nil, errInvalidParams
+		}
+		index = uint32(num)
+	default:
+		return nil, errInvalidParams
+	}
+
+	root, err := s.chain.GetStateRoot(index)
+	if err != nil {
+		return nil, NewInvalidParamsError(fmt.Sprintf("No state root for block %d", index), err)
+	}
+	return result.StateRoot{Index: index, Root: root}, nil
+}
+
+// getProof returns a proof of the contract's storage item in the state with
+// the specified root.
+func (s *Server) getProof(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	root, err := param.GetUint256()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	param, ok = reqParams.ValueWithType(1, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	scriptHash, err := param.GetUint160FromHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	param, ok = reqParams.ValueWithType(2, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	key, err := param.GetBytesHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+
+	skey := core.StorageItemKey(scriptHash, key)
+	proof, err := s.chain.GetStateProof(root, skey)
+	if err != nil {
+		return nil, NewInvalidParamsError("Unknown state root or storage item", err)
 	}
-}
-
-// Start creates a new JSON-RPC server
-// listening on the configured port.
-func (s *Server) Start(errChan chan error) {
-	if !s.config.Enabled {
-		s.log.Info("RPC server is not enabled")
-		return
+	res := result.Proof{
+		Key:   hex.EncodeToString(skey),
+		Proof: make([]string, len(proof)),
 	}
-	s.Handler = http.HandlerFunc(s.requestHandler)
-	s.log.Info("starting rpc-server", zap.String("endpoint", s.Addr))
-
-	errChan <- s.ListenAndServe()
-}
-
-// Shutdown overrides the http.Server Shutdown
-// method.
-func (s *Server) Shutdown() error {
-	s.log.Info("shutting down rpc-server", zap.String("endpoint", s.Addr))
-	return s.Server.Shutdown(context.Background())
+	for i := range proof {
+		res.Proof[i] = hex.EncodeToString(proof[i])
+	}
+	return res, nil
 }
 
-func (s *Server) requestHandler(w http.ResponseWriter, httpRequest *http.Request) {
-	req := NewRequest()
-
-	if httpRequest.Method != "POST" {
-		s.WriteErrorResponse(
-			req,
-			w,
-			NewInvalidParamsError(
-				fmt.Sprintf("Invalid method '%s', please retry with 'POST'", httpRequest.Method), nil,
-			),
-		)
-		return
+// getTxOut returns the specified unspent transaction output or nil if it's
+// spent or doesn't exist.
+func (s *Server) getTxOut(ps Params) (interface{}, error) {
+	p, ok := ps.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	h, err := p.GetUint256()
+	if err != nil {
+		return nil, errInvalidParams
 	}
 
-	err := req.DecodeData(httpRequest.Body)
+	p, ok = ps.ValueWithType(1, numberT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	num, err := p.GetInt()
+	if err != nil || num < 0 || num > math.MaxUint16 {
+		return nil, errInvalidParams
+	}
+	height, historic, err := s.historicHeight(ps, 2)
 	if err != nil {
-		s.WriteErrorResponse(req, w, NewParseError("Problem parsing JSON-RPC request body", err))
-		return
+		return nil, err
 	}
 
-	reqParams, err := req.Params()
+	tx, _, err := s.chain.GetTransaction(h)
 	if err != nil {
-		s.WriteErrorResponse(req, w, NewInvalidParamsError("Problem parsing request parameters", err))
-		return
+		return nil, NewInvalidParamsError(err.Error(), err)
+	}
+	if num >= len(tx.Outputs) {
+		return nil, NewInvalidParamsError("invalid index", errors.New("too big index"))
 	}
 
-	s.methodHandler(w, req, *reqParams)
-}
+	var ucs *core.UnspentCoinState
+	if historic {
+		ucs, err = s.chain.GetUnspentCoinStateAt(h, height)
+		if err != nil {
+			return nil, NewInvalidParamsError(err.Error(), err)
+		}
+	} else {
+		ucs = s.chain.GetUnspentCoinState(h)
+	}
+	if ucs == nil || ucs.IsSpent(uint16(num)) {
+		return nil, nil
+	}
 
-func (s *Server) methodHandler(w http.ResponseWriter, req *Request, reqParams Params) {
-	s.log.Debug("processing rpc request",
-		zap.String("method", req.Method),
-		zap.String("params", fmt.Sprintf("%v", reqParams)))
+	out := tx.Outputs[num]
+	out.Position = num
+	return result.NewTxOutput(&out), nil
+}
 
-	var (
-		results    interface{}
-		resultsErr error
-	)
+// getValidators returns the current NEO consensus nodes information and voting
+// status.
+func (s *Server) getValidators(_ Params) (interface{}, error) {
+	validators, err := s.chain.GetValidators()
+	if err != nil {
+		return nil, NewInternalServerError("can't get validators", err)
+	}
+	enrollments, err := s.chain.GetEnrollments()
+	if err != nil {
+		return nil, NewInternalServerError("can't get enrollments", err)
+	}
+	var res = make([]result.Validator, 0, len(enrollments))
+	for _, v := range enrollments {
+		res = append(res, result.Validator{
+			PublicKey:  *v.PublicKey,
+			Votes:      v.Votes,
+			Registered: v.Registered,
+			Active:     keys.PublicKeys(validators).Contains(v.PublicKey),
+		})
+	}
+	return res, nil
+}
 
-Methods:
-	switch req.Method {
-	case "getbestblockhash":
-		getbestblockhashCalled.Inc()
-		results = "0x" + s.chain.CurrentBlockHash().StringLE()
+// getApplicationLog returns the contract log based on the specified txid.
+func (s *Server) getApplicationLog(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.Value(0)
+	if !ok {
+		return nil, errInvalidParams
+	}
 
-	case "getblock":
-		getbestblockCalled.Inc()
-		var hash util.Uint256
+	txHash, err := param.GetUint256()
+	if err != nil {
+		return nil, errInvalidParams
+	}
 
-		param, ok := reqParams.Value(0)
-		if !ok {
-			resultsErr = errInvalidParams
-			break Methods
-		}
+	tx, _, err := s.chain.GetTransaction(txHash)
+	if err != nil {
+		err = errors.Wrapf(err, "Invalid transaction hash: %s", txHash)
+		return nil, NewInvalidParamsError(err.Error(), err)
+	}
 
-		switch param.Type {
-		case stringT:
-			var err error
-			hash, err = param.GetUint256()
-			if err != nil {
-				resultsErr = errInvalidParams
-				break Methods
-			}
-		case numberT:
-			num, err := s.blockHeightFromParam(param)
-			if err != nil {
-				resultsErr = errInvalidParams
-				break Methods
-			}
-			hash = s.chain.GetHeaderHash(num)
-		default:
-			resultsErr = errInvalidParams
-			break Methods
-		}
+	invocation, ok := tx.Data.(*transaction.InvocationTX)
+	if !ok {
+		return nil, NewInvalidParamsError(fmt.Sprintf("Transaction %s is not an invocation transaction", txHash), nil)
+	}
 
-		block, err := s.chain.GetBlock(hash)
-		if err != nil {
-			resultsErr = NewInternalServerError(fmt.Sprintf("Problem locating block with hash: %s", hash), err)
-			break
-		}
+	appExecResult, err := s.chain.GetAppExecResult(txHash)
+	if err != nil {
+		err = errors.Wrapf(err, "No application log for transaction %s", txHash)
+		return nil, NewInvalidParamsError(err.Error(), err)
+	}
 
-		if len(reqParams) == 2 && reqParams[1].Value == 1 {
-			results = wrappers.NewBlock(block, s.chain)
-		} else {
-			writer := io.NewBufBinWriter()
-			block.EncodeBinary(writer.BinWriter)
-			results = hex.EncodeToString(writer.Bytes())
-		}
+	return wrappers.NewApplicationLog(appExecResult, hash.Hash160(invocation.Script)), nil
+}
 
-	case "getblockcount":
-		getblockcountCalled.Inc()
-		results = s.chain.BlockHeight() + 1
+// traceTransaction re-executes persisted invocation transaction and returns
+// its execution log along with the trace of instructions executed.
+func (s *Server) traceTransaction(reqParams Params) (interface{}, error) {
+	var txHash util.Uint256
+	if err := reqParams.Decode(&txHash); err != nil {
+		return nil, err
+	}
 
-	case "getblockhash":
-		getblockHashCalled.Inc()
-		param, ok := reqParams.ValueWithType(0, numberT)
-		if !ok {
-			resultsErr = errInvalidParams
-			break Methods
-		}
-		num, err := s.blockHeightFromParam(param)
-		if err != nil {
-			resultsErr = errInvalidParams
-			break Methods
-		}
+	tx, height, err := s.chain.GetTransaction(txHash)
+	if err != nil {
+		err = errors.Wrapf(err, "Invalid transaction hash: %s", txHash)
+		return nil, NewInvalidParamsError(err.Error(), err)
+	}
+	invocation, ok := tx.Data.(*transaction.InvocationTX)
+	if !ok {
+		return nil, NewInvalidParamsError(fmt.Sprintf("Transaction %s is not an invocation transaction", txHash), nil)
+	}
 
-		results = s.chain.GetHeaderHash(num)
+	aer, trace, err := s.chain.TraceTransaction(txHash)
+	if err != nil {
+		err = errors.Wrapf(err, "Failed to trace transaction %s", txHash)
+		return nil, NewInvalidParamsError(err.Error(), err)
+	}
+	return wrappers.NewTransactionTrace(aer, hash.Hash160(invocation.Script), s.chain.GetHeaderHash(int(height)), trace), nil
+}
 
-	case "getconnectioncount":
-		getconnectioncountCalled.Inc()
-		results = s.coreServer.PeerCount()
+// getNEP5Balances returns NEP5 balances of the specified address.
+func (s *Server) getNEP5Balances(ps Params) (interface{}, error) {
+	p, ok := ps.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	u, err := p.GetUint160FromAddress()
+	if err != nil {
+		return nil, errInvalidParams
+	}
 
-	case "getversion":
-		getversionCalled.Inc()
-		results = result.Version{
-			Port:      s.coreServer.Port,
-			Nonce:     s.coreServer.ID(),
-			UserAgent: s.coreServer.UserAgent,
+	as := s.chain.GetNEP5Balances(u)
+	bs := &result.NEP5Balances{
+		Address:  address.Uint160ToString(u),
+		Balances: []result.NEP5Balance{},
+	}
+	if as != nil {
+		for h, bal := range as.Trackers {
+			bs.Balances = append(bs.Balances, result.NEP5Balance{
+				Asset:       h,
+				Amount:      bal.Balance.String(),
+				LastUpdated: bal.LastUpdatedBlock,
+			})
 		}
+		sort.Slice(bs.Balances, func(i, j int) bool {
+			return bs.Balances[i].Asset.Less(bs.Balances[j].Asset)
+		})
+	}
+	return bs, nil
+}
 
-	case "getpeers":
-		getpeersCalled.Inc()
-		peers := result.NewPeers()
-		for _, addr := range s.coreServer.UnconnectedPeers() {
-			peers.AddPeer("unconnected", addr)
-		}
+// getNEP5Transfers returns NEP5 transfers of the specified address that have
+// occurred in the [start, end] time range (in seconds). The range defaults to
+// the last 7 days.
+func (s *Server) getNEP5Transfers(ps Params) (interface{}, error) {
+	p, ok := ps.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	u, err := p.GetUint160FromAddress()
+	if err != nil {
+		return nil, errInvalidParams
+	}
 
-		for _, addr := range s.coreServer.BadPeers() {
-			peers.AddPeer("bad", addr)
+	end := uint32(time.Now().Unix())
+	start := end - nep5TransfersDefaultRange
+	if p, ok := ps.ValueWithType(1, numberT); ok {
+		v, err := p.GetInt()
+		if err != nil || v < 0 {
+			return nil, errInvalidParams
 		}
-
-		for addr := range s.coreServer.Peers() {
-			peers.AddPeer("connected", addr.PeerAddr().String())
+		start = uint32(v)
+	}
+	if p, ok := ps.ValueWithType(2, numberT); ok {
+		v, err := p.GetInt()
+		if err != nil || v < 0 {
+			return nil, errInvalidParams
 		}
+		end = uint32(v)
+	}
+	if start > end {
+		return nil, errInvalidParams
+	}
 
-		results = peers
-
-	case "validateaddress":
-		validateaddressCalled.Inc()
-		param, ok := reqParams.Value(0)
-		if !ok {
-			resultsErr = errInvalidParams
-			break Methods
+	bs := &result.NEP5Transfers{
+		Address:  address.Uint160ToString(u),
+		Received: []result.NEP5Transfer{},
+		Sent:     []result.NEP5Transfer{},
+	}
+	err = s.chain.ForEachNEP5Transfer(u, func(tr *state.NEP5Transfer) error {
+		if tr.Timestamp < start || tr.Timestamp > end {
+			return nil
 		}
-		results = wrappers.ValidateAddress(param.Value)
-
-	case "getassetstate":
-		getassetstateCalled.Inc()
-		param, ok := reqParams.ValueWithType(0, stringT)
-		if !ok {
-			resultsErr = errInvalidParams
-			break Methods
+		transfer := result.NEP5Transfer{
+			Timestamp: tr.Timestamp,
+			Asset:     tr.Asset,
+			Index:     tr.Block,
+			TxHash:    tr.Tx,
 		}
-
-		paramAssetID, err := param.GetUint256()
-		if err != nil {
-			resultsErr = errInvalidParams
-			break
+		if tr.Amount.Sign() > 0 { // token was received
+			transfer.Amount = tr.Amount.String()
+			if !tr.From.Equals(util.Uint160{}) {
+				transfer.Address = address.Uint160ToString(tr.From)
+			}
+			bs.Received = append(bs.Received, transfer)
+			return nil
 		}
 
-		as := s.chain.GetAssetState(paramAssetID)
-		if as != nil {
-			results = wrappers.NewAssetState(as)
-		} else {
-			results = "Invalid assetid"
+		transfer.Amount = new(big.Int).Neg(tr.Amount).String()
+		if !tr.To.Equals(util.Uint160{}) {
+			transfer.Address = address.Uint160ToString(tr.To)
 		}
-
-	case "getaccountstate":
-		getaccountstateCalled.Inc()
-		results, resultsErr = s.getAccountState(reqParams, false)
-
-	case "getrawtransaction":
-		getrawtransactionCalled.Inc()
-		results, resultsErr = s.getrawtransaction(reqParams)
-
-	case "getunspents":
-		getunspentsCalled.Inc()
-		results, resultsErr = s.getAccountState(reqParams, true)
-
-	case "invoke":
-		results, resultsErr = s.invoke(reqParams)
-
-	case "invokefunction":
-		results, resultsErr = s.invokeFunction(reqParams)
-
-	case "invokescript":
-		results, resultsErr = s.invokescript(reqParams)
-
-	case "sendrawtransaction":
-		sendrawtransactionCalled.Inc()
-		results, resultsErr = s.sendrawtransaction(reqParams)
-
-	default:
-		resultsErr = NewMethodNotFoundError(fmt.Sprintf("Method '%s' not supported", req.Method), nil)
+		bs.Sent = append(bs.Sent, transfer)
+		return nil
+	})
+	if err != nil {
+		return nil, NewInternalServerError("invalid NEP5 transfer log", err)
 	}
+	return bs, nil
+}
 
-	if resultsErr != nil {
-		s.WriteErrorResponse(req, w, resultsErr)
-		return
+// getClaimable returns spent NEO outputs of the specified address that can be
+// claimed along with the amount of GAS they've generated.
+func (s *Server) getClaimable(ps Params) (interface{}, error) {
+	p, ok := ps.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	u, err := p.GetUint160FromAddress()
+	if err != nil {
+		return nil, errInvalidParams
 	}
 
-	s.WriteResponse(req, w, results)
+	res := &result.ClaimableInfo{
+		Spents:  []result.Claimable{},
+		Address: address.Uint160ToString(u),
+	}
+	acc := s.chain.GetAccountState(u)
+	if acc == nil {
+		return res, nil
+	}
+	for _, ub := range acc.Unclaimed {
+		gen, sys := s.chain.CalculateClaimable(ub.Value, ub.Start, ub.End)
+		res.Spents = append(res.Spents, result.Claimable{
+			Tx:          ub.Tx,
+			N:           int(ub.Index),
+			Value:       ub.Value,
+			StartHeight: ub.Start,
+			EndHeight:   ub.End,
+			Generated:   gen,
+			SysFee:      sys,
+			Unclaimed:   gen + sys,
+		})
+		res.Unclaimed += gen + sys
+	}
+	return res, nil
 }
 
-func (s *Server) getrawtransaction(reqParams Params) (interface{}, error) {
-	var resultsErr error
-	var results interface{}
-
-	if param0, ok := reqParams.Value(0); !ok {
+// getUnclaimed returns the amount of GAS generated by both spent (available
+// for claim) and unspent (unavailable for claim) NEO outputs of the specified
+// address.
+func (s *Server) getUnclaimed(ps Params) (interface{}, error) {
+	p, ok := ps.ValueWithType(0, stringT)
+	if !ok {
 		return nil, errInvalidParams
-	} else if txHash, err := param0.GetUint256(); err != nil {
-		resultsErr = errInvalidParams
-	} else if tx, height, err := s.chain.GetTransaction(txHash); err != nil {
-		err = errors.Wrapf(err, "Invalid transaction hash: %s", txHash)
-		return nil, NewInvalidParamsError(err.Error(), err)
-	} else if len(reqParams) >= 2 {
-		_header := s.chain.GetHeaderHash(int(height))
-		header, err := s.chain.GetHeader(_header)
-		if err != nil {
-			resultsErr = NewInvalidParamsError(err.Error(), err)
-		}
-
-		param1, _ := reqParams.Value(1)
-		switch v := param1.Value.(type) {
+	}
+	u, err := p.GetUint160FromAddress()
+	if err != nil {
+		return nil, errInvalidParams
+	}
 
-		case int, float64, bool, string:
-			if v == 0 || v == "0" || v == 0.0 || v == false || v == "false" {
-				results = hex.EncodeToString(tx.Bytes())
-			} else {
-				results = wrappers.NewTransactionOutputRaw(tx, header, s.chain)
-			}
-		default:
-			results = wrappers.NewTransactionOutputRaw(tx, header, s.chain)
+	res := &result.Unclaimed{}
+	acc := s.chain.GetAccountState(u)
+	if acc == nil {
+		return res, nil
+	}
+	for _, ub := range acc.Unclaimed {
+		gen, sys := s.chain.CalculateClaimable(ub.Value, ub.Start, ub.End)
+		res.Available += gen + sys
+	}
+	end := s.chain.BlockHeight() + 1
+	for _, ub := range acc.Balances[core.GoverningTokenID()] {
+		_, start, err := s.chain.GetTransaction(ub.Tx)
+		if err != nil {
+			return nil, NewInternalServerError(fmt.Sprintf("failed to get transaction %s", ub.Tx), err)
 		}
-	} else {
-		results = hex.EncodeToString(tx.Bytes())
+		gen, sys := s.chain.CalculateClaimable(ub.Value, start, end)
+		res.Unavailable += gen + sys
 	}
-
-	return results, resultsErr
+	res.Unclaimed = res.Available + res.Unavailable
+	return res, nil
 }
 
 // getAccountState returns account state either in short or full (unspents included) form.
@@ -322,7 +1549,19 @@ func (s *Server) getAccountState(reqParams Params, unspents bool) (interface{},
 	} else if scriptHash, err := param.GetUint160FromAddress(); err != nil {
 		return nil, errInvalidParams
 	} else {
-		as := s.chain.GetAccountState(scriptHash)
+		height, historic, err := s.historicHeight(reqParams, 1)
+		if err != nil {
+			return nil, err
+		}
+		var as *state.Account
+		if historic {
+			as, err = s.chain.GetAccountStateAt(scriptHash, height)
+			if err != nil {
+				return nil, NewInvalidParamsError(err.Error(), err)
+			}
+		} else {
+			as = s.chain.GetAccountState(scriptHash)
+		}
 		if as == nil {
 			as = state.NewAccount(scriptHash)
 		}
@@ -361,7 +1600,11 @@ func (s *Server) invoke(reqParams Params) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return s.runScriptInVM(script), nil
+	inv, err := invocationParams(reqParams, 2)
+	if err != nil {
+		return nil, err
+	}
+	return s.runScriptInVM(script, inv), nil
 }
 
 // invokescript implements the `invokescript` RPC call.
@@ -374,11 +1617,19 @@ func (s *Server) invokeFunction(reqParams Params) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	script, err := CreateFunctionInvocationScript(scriptHash, reqParams[1:])
+	funcParams := reqParams[1:]
+	if len(funcParams) > 2 {
+		funcParams = funcParams[:2]
+	}
+	script, err := CreateFunctionInvocationScript(scriptHash, funcParams)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := invocationParams(reqParams, 3)
 	if err != nil {
 		return nil, err
 	}
-	return s.runScriptInVM(script), nil
+	return s.runScriptInVM(script, inv), nil
 }
 
 // invokescript implements the `invokescript` RPC call.
@@ -391,15 +1642,67 @@ func (s *Server) invokescript(reqParams Params) (interface{}, error) {
 	if err != nil {
 		return nil, errInvalidParams
 	}
+	inv, err := invocationParams(reqParams, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.runScriptInVM(script, inv), nil
+}
 
-	return s.runScriptInVM(script), nil
+// invocationParams parses optional parameters of invoke* calls starting at
+// the given index: a list of signers (script hashes or addresses), a trigger
+// type name and a trace flag (1 enables tracing). Application trigger is used
+// if it's not specified.
+func invocationParams(reqParams Params, index int) (*invocation, error) {
+	inv := &invocation{trigger: trigger.Application}
+	if p, ok := reqParams.Value(index); ok {
+		arr, err := p.GetArray()
+		if err != nil {
+			return nil, NewInvalidParamsError("Signers should be an array", err)
+		}
+		inv.signers = make([]util.Uint160, len(arr))
+		for i := range arr {
+			if err := arr[i].decode(&inv.signers[i]); err != nil {
+				return nil, NewInvalidParamsError(fmt.Sprintf("Invalid signer at index %d", i), err)
+			}
+		}
+	}
+	if p, ok := reqParams.Value(index + 1); ok {
+		name, err := p.GetString()
+		if err != nil {
+			return nil, NewInvalidParamsError("Trigger type should be a string", err)
+		}
+		inv.trigger, err = trigger.FromString(name)
+		if err != nil {
+			return nil, NewInvalidParamsError(err.Error(), err)
+		}
+	}
+	if p, ok := reqParams.Value(index + 2); ok {
+		inv.trace = p.Value == 1
+	}
+	return inv, nil
 }
 
 // runScriptInVM runs given script in a new test VM and returns the invocation
-// result.
-func (s *Server) runScriptInVM(script []byte) *wrappers.InvokeResult {
-	vm, _ := s.chain.GetTestVM()
+// result. The script is run with the given trigger and a synthetic
+// InvocationTX as a script container, signers are added to its attributes
+// so that they're treated as verified by CheckWitness. If tracing is
+// requested the result contains the last maxInvocationTraceLength executed
+// instructions.
+func (s *Server) runScriptInVM(script []byte, inv *invocation) *wrappers.InvokeResult {
+	tx := transaction.NewInvocationTX(script, 0)
+	for _, h := range inv.signers {
+		tx.Attributes = append(tx.Attributes, transaction.Attribute{
+			Usage: transaction.Script,
+			Data:  h.BytesBE(),
+		})
+	}
+	vm, _ := s.chain.GetTestVM(inv.trigger, tx)
 	vm.SetGasLimit(s.config.MaxGasInvoke)
+	if inv.trace {
+		vm.EnableTracing(maxInvocationTraceLength)
+	}
 	vm.LoadScript(script)
 	_ = vm.Run()
 	result := &wrappers.InvokeResult{
@@ -407,10 +1710,42 @@ func (s *Server) runScriptInVM(script []byte) *wrappers.InvokeResult {
 		GasConsumed: vm.GasConsumed().String(),
 		Script:      hex.EncodeToString(script),
 		Stack:       vm.Estack(),
+		Trace:       vm.Trace(),
 	}
 	return result
 }
 
+// submitBlock broadcasts a raw block over the NEO network.
+func (s *Server) submitBlock(reqParams Params) (interface{}, error) {
+	param, ok := reqParams.ValueWithType(0, stringT)
+	if !ok {
+		return nil, errInvalidParams
+	}
+	blockBytes, err := param.GetBytesHex()
+	if err != nil {
+		return nil, errInvalidParams
+	}
+	b := &block.Block{}
+	r := io.NewBinReaderFromBuf(blockBytes)
+	b.DecodeBinary(r)
+	if r.Err != nil {
+		return nil, NewInvalidParamsError("can't decode block", r.Err)
+	}
+	reason, err := s.coreServer.RelayBlock(b)
+	switch reason {
+	case network.RelaySucceed:
+		return true, nil
+	case network.RelayAlreadyExists:
+		return nil, NewInternalServerError(fmt.Sprintf("block already exists: %s", err), err)
+	case network.RelayUnableToVerify:
+		return nil, NewInternalServerError(fmt.Sprintf("block can't be verified: %s", err), err)
+	case network.RelayInvalid:
+		return nil, NewInternalServerError(fmt.Sprintf("block is invalid: %s", err), err)
+	default:
+		return nil, NewInternalServerError(fmt.Sprintf("unknown error: %s", err), err)
+	}
+}
+
 func (s *Server) sendrawtransaction(reqParams Params) (interface{}, error) {
 	var resultsErr error
 	var results interface{}
@@ -452,6 +1787,24 @@ func (s *Server) sendrawtransaction(reqParams Params) (interface{}, error) {
 	return results, resultsErr
 }
 
+// historicHeight returns the block height specified by the optional parameter
+// with the given index for historical state queries, the second value is
+// false if there is no such parameter.
+func (s *Server) historicHeight(reqParams Params, index int) (uint32, bool, error) {
+	param, ok := reqParams.Value(index)
+	if !ok {
+		return 0, false, nil
+	}
+	if param.Type != numberT {
+		return 0, false, errInvalidParams
+	}
+	num, err := s.blockHeightFromParam(param)
+	if err != nil {
+		return 0, false, NewInvalidParamsError(err.Error(), err)
+	}
+	return uint32(num), true, nil
+}
+
 func (s Server) blockHeightFromParam(param *Param) (int, error) {
 	num, err := param.GetInt()
 	if err != nil {
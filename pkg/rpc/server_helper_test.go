@@ -173,13 +173,21 @@ type GetUnspents struct {
 	ID int `json:"id"`
 }
 
-func initServerWithInMemoryChain(t *testing.T) (*core.Blockchain, http.HandlerFunc) {
-	var nBlocks uint32
+func initClearServerWithInMemoryChain(t *testing.T) (*core.Blockchain, *Server) {
+	return initClearServerWithCustomConfig(t, nil)
+}
 
+// initClearServerWithCustomConfig is the same as
+// initClearServerWithInMemoryChain, but allows to modify the configuration
+// used.
+func initClearServerWithCustomConfig(t *testing.T, f func(*config.Config)) (*core.Blockchain, *Server) {
 	net := config.ModeUnitTestNet
 	configPath := "../../config"
 	cfg, err := config.Load(configPath, net)
 	require.NoError(t, err, "could not load config")
+	if f != nil {
+		f(&cfg)
+	}
 
 	memoryStore := storage.NewMemoryStore()
 	logger := zaptest.NewLogger(t)
@@ -188,23 +196,44 @@ func initServerWithInMemoryChain(t *testing.T) (*core.Blockchain, http.HandlerFu
 
 	go chain.Run()
 
+	serverConfig := network.NewServerConfig(cfg)
+	server, err := network.NewServer(serverConfig, chain, logger)
+	require.NoError(t, err)
+	rpcServer := NewServer(chain, cfg.ApplicationConfiguration.RPC, server, logger)
+
+	return chain, &rpcServer
+}
+
+func getTestBlocks(t *testing.T) []*block.Block {
 	f, err := os.Open("testdata/50testblocks.acc")
 	require.Nil(t, err)
+	defer f.Close()
 	br := io.NewBinReaderFromIO(f)
-	nBlocks = br.ReadU32LE()
+	nBlocks := br.ReadU32LE()
 	require.Nil(t, br.Err)
+	blocks := make([]*block.Block, 0, int(nBlocks))
 	for i := 0; i < int(nBlocks); i++ {
 		b := &block.Block{}
 		b.DecodeBinary(br)
 		require.Nil(t, br.Err)
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+func initServerWithInMemoryChain(t *testing.T) (*core.Blockchain, http.HandlerFunc) {
+	return initServerWithCustomConfig(t, nil)
+}
+
+// initServerWithCustomConfig is the same as initServerWithInMemoryChain, but
+// allows to modify the configuration used.
+func initServerWithCustomConfig(t *testing.T, f func(*config.Config)) (*core.Blockchain, http.HandlerFunc) {
+	chain, rpcServer := initClearServerWithCustomConfig(t, f)
+
+	for _, b := range getTestBlocks(t) {
 		require.NoError(t, chain.AddBlock(b))
 	}
 
-	serverConfig := network.NewServerConfig(cfg)
-	server, err := network.NewServer(serverConfig, chain, logger)
-	require.NoError(t, err)
-	rpcServer := NewServer(chain, cfg.ApplicationConfiguration.RPC, server, logger)
 	handler := http.HandlerFunc(rpcServer.requestHandler)
-
 	return chain, handler
 }
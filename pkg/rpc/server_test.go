@@ -2,18 +2,27 @@ package rpc
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/CityOfZion/neo-go/pkg/core"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/crypto/keys"
+	"github.com/CityOfZion/neo-go/pkg/encoding/address"
+	"github.com/CityOfZion/neo-go/pkg/io"
 	"github.com/CityOfZion/neo-go/pkg/rpc/wrappers"
+	"github.com/CityOfZion/neo-go/pkg/smartcontract/trigger"
 	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm/emit"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -70,6 +79,413 @@ var rpcTestCases = map[string][]rpcTestCase{
 			params: `["notabase58"]`,
 			fail:   true,
 		},
+		{
+			name:   "historic, not archival",
+			params: `["AZ81H31DMWzbSnFDLFkzh9vHwaDLayV7fU", 1]`,
+			fail:   true,
+		},
+	},
+	"getapplicationlog": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "missing hash",
+			params: `["` + util.Uint256{}.String() + `"]`,
+			fail:   true,
+		},
+	},
+	"tracetransaction": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "missing hash",
+			params: `["` + util.Uint256{}.String() + `"]`,
+			fail:   true,
+		},
+		{
+			name:   "not an invocation",
+			params: `["602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7"]`,
+			fail:   true,
+		},
+	},
+	"getnep5balances": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid address",
+			params: `["notabase58"]`,
+			fail:   true,
+		},
+		{
+			name:   "positive, no balances",
+			params: `["AK2nJJpJr6o664CWJKi1QRXjqeic2zRp8y"]`,
+			result: func(e *executor) interface{} { return &NEP5BalancesResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*NEP5BalancesResponse)
+				require.True(t, ok)
+				require.NotNil(t, res.Result)
+				assert.Equal(t, "AK2nJJpJr6o664CWJKi1QRXjqeic2zRp8y", res.Result.Address)
+				assert.Empty(t, res.Result.Balances)
+			},
+		},
+	},
+	"getnep5transfers": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid address",
+			params: `["notabase58"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid timestamp range",
+			params: `["AK2nJJpJr6o664CWJKi1QRXjqeic2zRp8y", 2, 1]`,
+			fail:   true,
+		},
+		{
+			name:   "positive, no transfers",
+			params: `["AK2nJJpJr6o664CWJKi1QRXjqeic2zRp8y", 0]`,
+			result: func(e *executor) interface{} { return &NEP5TransfersResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*NEP5TransfersResponse)
+				require.True(t, ok)
+				require.NotNil(t, res.Result)
+				assert.Equal(t, "AK2nJJpJr6o664CWJKi1QRXjqeic2zRp8y", res.Result.Address)
+				assert.Empty(t, res.Result.Sent)
+				assert.Empty(t, res.Result.Received)
+			},
+		},
+	},
+	"getrawmempool": {
+		{
+			name:   "positive, empty",
+			params: `[]`,
+			result: func(e *executor) interface{} { return &RawMempoolResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*RawMempoolResponse)
+				require.True(t, ok)
+				assert.Empty(t, res.Result)
+			},
+		},
+	},
+	"getmempoolentry": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "missing hash",
+			params: `["` + util.Uint256{}.String() + `"]`,
+			fail:   true,
+		},
+	},
+	"getclaimable": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid address",
+			params: `["notabase58"]`,
+			fail:   true,
+		},
+		{
+			name:   "positive",
+			params: `["AZ81H31DMWzbSnFDLFkzh9vHwaDLayV7fU"]`,
+			result: func(e *executor) interface{} { return &ClaimableResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*ClaimableResponse)
+				require.True(t, ok)
+				require.NotNil(t, res.Result)
+				assert.Equal(t, "AZ81H31DMWzbSnFDLFkzh9vHwaDLayV7fU", res.Result.Address)
+
+				var sum util.Fixed8
+				for _, c := range res.Result.Spents {
+					assert.Equal(t, c.Generated+c.SysFee, c.Unclaimed)
+					sum += c.Unclaimed
+				}
+				assert.Equal(t, sum, res.Result.Unclaimed)
+			},
+		},
+	},
+	"gettxout": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "no index",
+			params: `["c56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex", 0]`,
+			fail:   true,
+		},
+		{
+			name:   "missing hash",
+			params: `["` + util.Uint256{}.String() + `", 0]`,
+			fail:   true,
+		},
+		{
+			name:   "negative index",
+			params: `["c56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b", -1]`,
+			fail:   true,
+		},
+		{
+			name:   "too big index",
+			params: `["c56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b", 0]`,
+			fail:   true,
+		},
+		{
+			name:   "historic, not archival",
+			params: `["c56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b", 0, 0]`,
+			fail:   true,
+		},
+	},
+	"getunclaimed": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid address",
+			params: `["notabase58"]`,
+			fail:   true,
+		},
+		{
+			name:   "positive",
+			params: `["AZ81H31DMWzbSnFDLFkzh9vHwaDLayV7fU"]`,
+			result: func(e *executor) interface{} { return &UnclaimedResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*UnclaimedResponse)
+				require.True(t, ok)
+				require.NotNil(t, res.Result)
+
+				u, err := address.StringToUint160("AZ81H31DMWzbSnFDLFkzh9vHwaDLayV7fU")
+				require.NoError(t, err)
+				acc := e.chain.GetAccountState(u)
+				require.NotNil(t, acc)
+
+				var unavailable util.Fixed8
+				for _, ub := range acc.Balances[core.GoverningTokenID()] {
+					_, height, err := e.chain.GetTransaction(ub.Tx)
+					require.NoError(t, err)
+					gen, sys := e.chain.CalculateClaimable(ub.Value, height, e.chain.BlockHeight()+1)
+					unavailable += gen + sys
+				}
+				assert.Equal(t, unavailable, res.Result.Unavailable)
+				assert.Equal(t, res.Result.Available+res.Result.Unavailable, res.Result.Unclaimed)
+			},
+		},
+	},
+	"getcontractstate": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "unknown contract",
+			params: `["` + util.Uint160{}.String() + `"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid height",
+			params: `["` + util.Uint160{}.String() + `", "1"]`,
+			fail:   true,
+		},
+		{
+			name:   "historic, not archival",
+			params: `["` + util.Uint160{}.String() + `", 1]`,
+			fail:   true,
+		},
+	},
+	"getstorage": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "no key",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex", "01"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid key",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "too big height",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "01", 100500]`,
+			fail:   true,
+		},
+		{
+			name:   "historic, not archival",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "01", 1]`,
+			fail:   true,
+		},
+		{
+			name:   "missing item",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "01"]`,
+			result: func(e *executor) interface{} { return &StringResultResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*StringResultResponse)
+				require.True(t, ok)
+				assert.Equal(t, "", res.Result)
+			},
+		},
+	},
+	"getstateroot": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "unknown block",
+			params: `["` + util.Uint256{}.StringLE() + `"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid height",
+			params: `[-1]`,
+			fail:   true,
+		},
+		{
+			name:   "too big height",
+			params: `[100500]`,
+			fail:   true,
+		},
+		{
+			name:   "positive, by index",
+			params: `[1]`,
+			result: func(e *executor) interface{} { return &StateRootResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*StateRootResponse)
+				require.True(t, ok)
+				require.NotNil(t, res.Result)
+				root, err := e.chain.GetStateRoot(1)
+				require.NoError(t, err)
+				assert.Equal(t, uint32(1), res.Result.Index)
+				assert.Equal(t, root, res.Result.Root)
+			},
+		},
+	},
+	"getproof": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid root",
+			params: `["notahex", "50befd26fdf6e4d957c11e078b24ebce6291456f", "01"]`,
+			fail:   true,
+		},
+		{
+			name:   "no script hash",
+			params: `["` + util.Uint256{}.StringLE() + `"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid script hash",
+			params: `["` + util.Uint256{}.StringLE() + `", "notahex", "01"]`,
+			fail:   true,
+		},
+		{
+			name:   "no key",
+			params: `["` + util.Uint256{}.StringLE() + `", "50befd26fdf6e4d957c11e078b24ebce6291456f"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid key",
+			params: `["` + util.Uint256{}.StringLE() + `", "50befd26fdf6e4d957c11e078b24ebce6291456f", "notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "unknown root",
+			params: `["` + util.Uint256{1, 2, 3}.StringLE() + `", "50befd26fdf6e4d957c11e078b24ebce6291456f", "01"]`,
+			fail:   true,
+		},
+	},
+	"findstorage": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "no prefix",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid start",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "", -1]`,
+			fail:   true,
+		},
+		{
+			name:   "positive, empty",
+			params: `["50befd26fdf6e4d957c11e078b24ebce6291456f", "01"]`,
+			result: func(e *executor) interface{} { return &FindStorageResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*FindStorageResponse)
+				require.True(t, ok)
+				require.NotNil(t, res.Result)
+				assert.Empty(t, res.Result.Results)
+				assert.False(t, res.Result.Truncated)
+			},
+		},
 	},
 	"getassetstate": {
 		{
@@ -153,6 +569,101 @@ var rpcTestCases = map[string][]rpcTestCase{
 			fail:   true,
 		},
 	},
+	"getblockheader": {
+		{
+			name:   "positive, hex",
+			params: "[1]",
+			result: func(e *executor) interface{} { return "" },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*StringResultResponse)
+				require.True(t, ok)
+
+				header, err := e.chain.GetHeader(e.chain.GetHeaderHash(1))
+				require.NoErrorf(t, err, "could not get header")
+
+				writer := io.NewBufBinWriter()
+				header.EncodeBinary(writer.BinWriter)
+				require.NoError(t, writer.Err)
+				assert.Equal(t, hex.EncodeToString(writer.Bytes()), res.Result)
+			},
+		},
+		{
+			name:   "positive, verbose",
+			params: "[1, 1]",
+			result: func(e *executor) interface{} { return &BlockHeaderResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*BlockHeaderResponse)
+				require.True(t, ok)
+				require.NotNil(t, res.Result)
+
+				header, err := e.chain.GetHeader(e.chain.GetHeaderHash(1))
+				require.NoErrorf(t, err, "could not get header")
+
+				assert.Equal(t, header.Hash(), res.Result.Hash)
+				assert.Equal(t, header.Index, res.Result.Index)
+				assert.Equal(t, header.PrevHash, res.Result.PrevHash)
+				assert.Equal(t, header.Script, res.Result.Script)
+				assert.Equal(t, e.chain.GetHeaderHash(2), res.Result.NextBlockHash)
+				assert.Equal(t, e.chain.BlockHeight(), res.Result.Confirmations)
+			},
+		},
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid height",
+			params: `[-1]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hash",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "missing hash",
+			params: `["` + util.Uint256{}.String() + `"]`,
+			fail:   true,
+		},
+	},
+	"getblocksysfee": {
+		{
+			name:   "positive",
+			params: "[1]",
+			result: func(e *executor) interface{} { return "" },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*StringResultResponse)
+				require.True(t, ok)
+
+				var expected int64
+				for i := 0; i <= 1; i++ {
+					block, err := e.chain.GetBlock(e.chain.GetHeaderHash(i))
+					require.NoErrorf(t, err, "could not get block")
+					for _, tx := range block.Transactions {
+						expected += e.chain.SystemFee(tx).Int64Value()
+					}
+				}
+				assert.Equal(t, strconv.FormatInt(expected, 10), res.Result)
+			},
+		},
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "string height",
+			params: `["1"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid height",
+			params: `[-1]`,
+			fail:   true,
+		},
+	},
 	"getblockcount": {
 		{
 			params: "[]",
@@ -251,6 +762,33 @@ var rpcTestCases = map[string][]rpcTestCase{
 			},
 		},
 	},
+	"getvalidators": {
+		{
+			params: "[]",
+			result: func(*executor) interface{} { return &ValidatorsResponse{} },
+			check: func(t *testing.T, e *executor, result interface{}) {
+				res, ok := result.(*ValidatorsResponse)
+				require.True(t, ok)
+				sbValidators, err := e.chain.GetStandByValidators()
+				require.NoError(t, err)
+				validators, err := e.chain.GetValidators()
+				require.NoError(t, err)
+				for _, sb := range sbValidators {
+					var found bool
+					for _, v := range res.Result {
+						if v.PublicKey.Equal(sb) {
+							found = true
+							break
+						}
+					}
+					assert.Truef(t, found, "standby validator %s is missing", sb.String())
+				}
+				for _, v := range res.Result {
+					assert.Equal(t, keys.PublicKeys(validators).Contains(&v.PublicKey), v.Active)
+				}
+			},
+		},
+	},
 	"getversion": {
 		{
 			params: "[]",
@@ -363,6 +901,21 @@ var rpcTestCases = map[string][]rpcTestCase{
 			params: `["qwerty"]`,
 			fail:   true,
 		},
+		{
+			name:   "signers not an array",
+			params: `["51", 42]`,
+			fail:   true,
+		},
+		{
+			name:   "bad signer",
+			params: `["51", ["qwerty"]]`,
+			fail:   true,
+		},
+		{
+			name:   "bad trigger",
+			params: `["51", [], "Unknown"]`,
+			fail:   true,
+		},
 	},
 	"sendrawtransaction": {
 		{
@@ -396,6 +949,23 @@ var rpcTestCases = map[string][]rpcTestCase{
 			fail:   true,
 		},
 	},
+	"submitblock": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid hex",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid block bytes",
+			params: `["0043"]`,
+			fail:   true,
+		},
+	},
 	"validateaddress": {
 		{
 			name:   "positive",
@@ -482,6 +1052,189 @@ func TestRPC(t *testing.T) {
 	})
 }
 
+func TestRPCMempool(t *testing.T) {
+	chain, handler := initServerWithInMemoryChain(t)
+
+	defer chain.Close()
+
+	tx := transaction.NewInvocationTX([]byte{byte(opcode.PUSH1)}, 0)
+	require.NoError(t, chain.GetMemPool().Add(tx, chain))
+
+	t.Run("getrawmempool", func(t *testing.T) {
+		rpc := `{"jsonrpc": "2.0", "id": 1, "method": "getrawmempool", "params": []}`
+		body := doRPCCall(rpc, handler, t)
+		checkErrResponse(t, body, false)
+		var res RawMempoolResponse
+		err := json.Unmarshal(body, &res)
+		require.NoErrorf(t, err, "could not parse response: %s", body)
+		assert.Equal(t, []util.Uint256{tx.Hash()}, res.Result)
+	})
+
+	t.Run("getrawmempool verbose", func(t *testing.T) {
+		rpc := `{"jsonrpc": "2.0", "id": 1, "method": "getrawmempool", "params": [1]}`
+		body := doRPCCall(rpc, handler, t)
+		checkErrResponse(t, body, false)
+		var res RawMempoolVerboseResponse
+		err := json.Unmarshal(body, &res)
+		require.NoErrorf(t, err, "could not parse response: %s", body)
+		require.Equal(t, 1, len(res.Result))
+		assert.Equal(t, tx.Hash(), res.Result[0].TxID)
+		assert.Equal(t, transaction.InvocationType, res.Result[0].Type)
+		assert.Equal(t, chain.FeePerByte(tx), res.Result[0].FeePerByte)
+		assert.Equal(t, chain.IsLowPriority(tx), res.Result[0].IsLowPriority)
+	})
+
+	t.Run("getmempoolentry", func(t *testing.T) {
+		rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getmempoolentry", "params": ["%s"]}`, tx.Hash().StringLE())
+		body := doRPCCall(rpc, handler, t)
+		checkErrResponse(t, body, false)
+		var res MempoolEntryResponse
+		err := json.Unmarshal(body, &res)
+		require.NoErrorf(t, err, "could not parse response: %s", body)
+		require.NotNil(t, res.Result)
+		assert.Equal(t, tx.Hash(), res.Result.TxID)
+		assert.Equal(t, io.GetVarSize(tx), res.Result.Size)
+		assert.Equal(t, chain.NetworkFee(tx), res.Result.NetworkFee)
+	})
+}
+
+func TestRPCGetTxOut(t *testing.T) {
+	chain, handler := initServerWithInMemoryChain(t)
+
+	defer chain.Close()
+
+	var checked int
+	for i := 0; i <= int(chain.BlockHeight()); i++ {
+		block, err := chain.GetBlock(chain.GetHeaderHash(i))
+		require.NoError(t, err)
+		for _, tx := range block.Transactions {
+			if len(tx.Outputs) == 0 {
+				continue
+			}
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "gettxout", "params": ["%s", 0]}`, tx.Hash().StringLE())
+			body := doRPCCall(rpc, handler, t)
+			checkErrResponse(t, body, false)
+			var res TxOutResponse
+			err := json.Unmarshal(body, &res)
+			require.NoErrorf(t, err, "could not parse response: %s", body)
+
+			ucs := chain.GetUnspentCoinState(tx.Hash())
+			if ucs == nil || ucs.IsSpent(0) {
+				require.Nil(t, res.Result)
+				continue
+			}
+			require.NotNil(t, res.Result)
+			assert.Equal(t, 0, res.Result.N)
+			assert.Equal(t, tx.Outputs[0].AssetID, res.Result.Asset)
+			assert.Equal(t, tx.Outputs[0].Amount, res.Result.Value)
+			assert.Equal(t, address.Uint160ToString(tx.Outputs[0].ScriptHash), res.Result.Address)
+			checked++
+		}
+	}
+	require.NotEqual(t, 0, checked, "no unspent outputs checked")
+}
+
+func TestRPCSubmitBlock(t *testing.T) {
+	chain, handler := initServerWithInMemoryChain(t)
+
+	defer chain.Close()
+
+	b, err := chain.GetBlock(chain.CurrentBlockHash())
+	require.NoError(t, err)
+	buf := io.NewBufBinWriter()
+	b.EncodeBinary(buf.BinWriter)
+	require.NoError(t, buf.Err)
+
+	rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "submitblock", "params": ["%s"]}`, hex.EncodeToString(buf.Bytes()))
+	body := doRPCCall(rpc, handler, t)
+	checkErrResponse(t, body, true)
+	var res SubmitBlockResponse
+	require.NoError(t, json.Unmarshal(body, &res))
+	require.NotNil(t, res.Error)
+	assert.Contains(t, res.Error.Data, "already exists")
+}
+
+func TestRPCInvokeTrigger(t *testing.T) {
+	chain, handler := initServerWithInMemoryChain(t)
+	defer chain.Close()
+
+	w := io.NewBufBinWriter()
+	emit.Syscall(w.BinWriter, "Neo.Runtime.GetTrigger")
+	require.NoError(t, w.Err)
+	script := hex.EncodeToString(w.Bytes())
+
+	for params, expected := range map[string]int64{
+		fmt.Sprintf(`["%s"]`, script):                     int64(trigger.Application),
+		fmt.Sprintf(`["%s", [], "Application"]`, script):  int64(trigger.Application),
+		fmt.Sprintf(`["%s", [], "Verification"]`, script): int64(trigger.Verification),
+	} {
+		rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "invokescript", "params": %s}`, params)
+		body := doRPCCall(rpc, handler, t)
+		checkErrResponse(t, body, false)
+		var res InvokeScriptResponse
+		require.NoError(t, json.Unmarshal(body, &res))
+		require.Equal(t, 1, len(res.Result.Stack))
+		assert.Equal(t, StackParam{Type: Integer, Value: expected}, res.Result.Stack[0])
+	}
+}
+
+func TestRPCBatch(t *testing.T) {
+	chain, handler := initServerWithInMemoryChain(t)
+
+	defer chain.Close()
+
+	t.Run("positive", func(t *testing.T) {
+		rpc := `[{"jsonrpc": "2.0", "id": 1, "method": "getblockcount", "params": []},
+			{"jsonrpc": "2.0", "method": "getblockcount", "params": []},
+			{"jsonrpc": "2.0", "id": 2, "method": "getbestblockhash", "params": []},
+			{"jsonrpc": "2.0", "id": 3, "method": "unknownmethod", "params": []},
+			1]`
+		body := doRPCCall(rpc, handler, t)
+		var res []json.RawMessage
+		require.NoErrorf(t, json.Unmarshal(body, &res), "could not parse response: %s", body)
+		require.Equal(t, 4, len(res))
+
+		var count IntResultResponse
+		require.NoError(t, json.Unmarshal(res[0], &count))
+		assert.Equal(t, 1, count.ID)
+		assert.Equal(t, int(chain.BlockHeight()+1), count.Result)
+
+		var hash StringResultResponse
+		require.NoError(t, json.Unmarshal(res[1], &hash))
+		assert.Equal(t, 2, hash.ID)
+		assert.Equal(t, "0x"+chain.CurrentBlockHash().StringLE(), hash.Result)
+
+		checkErrResponse(t, res[2], true)
+		checkErrResponse(t, res[3], true)
+	})
+
+	t.Run("notifications only", func(t *testing.T) {
+		rpc := `[{"jsonrpc": "2.0", "method": "getblockcount", "params": []}]`
+		body := doRPCCall(rpc, handler, t)
+		assert.Equal(t, 0, len(body))
+	})
+
+	t.Run("single notification", func(t *testing.T) {
+		rpc := `{"jsonrpc": "2.0", "method": "getblockcount", "params": []}`
+		body := doRPCCall(rpc, handler, t)
+		assert.Equal(t, 0, len(body))
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		body := doRPCCall(`[]`, handler, t)
+		checkErrResponse(t, body, true)
+	})
+
+	t.Run("too big batch", func(t *testing.T) {
+		reqs := make([]string, defaultMaxBatchSize+1)
+		for i := range reqs {
+			reqs[i] = fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "method": "getblockcount", "params": []}`, i)
+		}
+		body := doRPCCall("["+strings.Join(reqs, ",")+"]", handler, t)
+		checkErrResponse(t, body, true)
+	})
+}
+
 func (tc rpcTestCase) getResultPair(e *executor) (expected interface{}, res interface{}) {
 	expected = tc.result(e)
 	switch exp := expected.(type) {
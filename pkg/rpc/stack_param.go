@@ -163,6 +163,12 @@ func (p *StackParam) UnmarshalJSON(data []byte) (err error) {
 			return
 		}
 		p.Value = s
+	case Boolean:
+		var bl bool
+		if err = json.Unmarshal(r.Value, &bl); err != nil {
+			return
+		}
+		p.Value = bl
 	case Integer:
 		if err = json.Unmarshal(r.Value, &i); err == nil {
 			p.Value = i
@@ -25,6 +25,10 @@ var testCases = []struct {
 		input:  `{"type":"ByteArray","value":"010203"}`,
 		result: StackParam{Type: ByteArray, Value: []byte{0x01, 0x02, 0x03}},
 	},
+	{
+		input:  `{"type":"Boolean","value":true}`,
+		result: StackParam{Type: Boolean, Value: true},
+	},
 	{
 		input:  `{"type":"String","value":"Some string"}`,
 		result: StackParam{Type: String, Value: "Some string"},
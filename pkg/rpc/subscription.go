@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"encoding/json"
+
+	"github.com/CityOfZion/neo-go/config"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+)
+
+type (
+	// subscriber is an event subscriber connected via websocket.
+	subscriber struct {
+		writer    chan<- *websocket.PreparedMessage
+		ws        *websocket.Conn
+		overflown atomic.Bool
+		// user is the authenticated user of the connection (nil for
+		// anonymous ones).
+		user *config.RPCUser
+		// addr is the client's IP address used for rate limiting.
+		addr string
+		// done is closed when the subscriber is disconnected.
+		done chan struct{}
+		// These work like slots as there is not a lot of them (it's
+		// cheaper doing it this way rather than creating a map).
+		feeds [maxFeeds]feed
+	}
+
+	// feed is a single subscription of the subscriber.
+	feed struct {
+		event EventID
+		// contract is an optional notification filter.
+		contract *util.Uint160
+	}
+
+	// EventID represents an event type happening on the chain.
+	EventID byte
+
+	// Notification is a message sent to websocket subscribers when some
+	// event happens on the chain.
+	Notification struct {
+		JSONRPC string        `json:"jsonrpc"`
+		Event   EventID       `json:"method"`
+		Payload []interface{} `json:"params"`
+	}
+)
+
+const (
+	// InvalidEventID is an invalid event id that is the default value of
+	// EventID. It's reserved for feeds that are not subscribed to.
+	InvalidEventID EventID = iota
+	// BlockEventID is a `block_added` event.
+	BlockEventID
+	// TransactionEventID corresponds to `transaction_added` event.
+	TransactionEventID
+	// NotificationEventID represents `notification_from_execution` events.
+	NotificationEventID
+	// ExecutionEventID is used for `transaction_executed` events.
+	ExecutionEventID
+	// MissedEventID notifies user of missed events.
+	MissedEventID EventID = 255
+)
+
+// maxFeeds is the maximum number of subscriptions per websocket connection.
+const maxFeeds = 16
+
+// String is a good old Stringer implementation.
+func (e EventID) String() string {
+	switch e {
+	case BlockEventID:
+		return "block_added"
+	case TransactionEventID:
+		return "transaction_added"
+	case NotificationEventID:
+		return "notification_from_execution"
+	case ExecutionEventID:
+		return "transaction_executed"
+	case MissedEventID:
+		return "event_missed"
+	default:
+		return "unknown"
+	}
+}
+
+// GetEventIDFromString converts input string into an EventID if it's possible.
+func GetEventIDFromString(s string) (EventID, error) {
+	switch s {
+	case "block_added":
+		return BlockEventID, nil
+	case "transaction_added":
+		return TransactionEventID, nil
+	case "notification_from_execution":
+		return NotificationEventID, nil
+	case "transaction_executed":
+		return ExecutionEventID, nil
+	case "event_missed":
+		return MissedEventID, nil
+	default:
+		return 255, errors.New("invalid stream name")
+	}
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (e EventID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (e *EventID) UnmarshalJSON(b []byte) error {
+	var s string
+
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	id, err := GetEventIDFromString(s)
+	if err != nil {
+		return err
+	}
+	*e = id
+	return nil
+}
+
+// matches checks whether the feed is interested in the event specified by
+// its id and (for notifications) the contract that emitted it.
+func (f *feed) matches(event EventID, contract *util.Uint160) bool {
+	if f.event != event {
+		return false
+	}
+	return f.contract == nil || contract == nil || f.contract.Equals(*contract)
+}
@@ -0,0 +1,250 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CityOfZion/neo-go/pkg/core"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wsEvent is a websocket event message used for testing.
+type wsEvent struct {
+	Event   EventID           `json:"method"`
+	Payload []json.RawMessage `json:"params"`
+}
+
+// wsResponse is a websocket response message used for testing.
+type wsResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+	ID     int             `json:"id"`
+}
+
+func initCleanServerAndWSClient(t *testing.T) (*core.Blockchain, *websocket.Conn, func()) {
+	chain, rpcServer := initClearServerWithInMemoryChain(t)
+	go rpcServer.handleSubEvents()
+
+	srv := httptest.NewServer(http.HandlerFunc(rpcServer.requestHandler))
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	return chain, ws, func() {
+		ws.Close()
+		srv.Close()
+		require.NoError(t, rpcServer.Shutdown())
+		chain.Close()
+	}
+}
+
+func callWSGetRaw(t *testing.T, ws *websocket.Conn, msg string) *wsResponse {
+	require.NoError(t, ws.SetWriteDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(msg)))
+
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	_, body, err := ws.ReadMessage()
+	require.NoError(t, err)
+	resp := new(wsResponse)
+	require.NoError(t, json.Unmarshal(body, resp))
+	return resp
+}
+
+func subscribe(t *testing.T, ws *websocket.Conn, params string) string {
+	resp := callWSGetRaw(t, ws, fmt.Sprintf(`{"jsonrpc": "2.0", "method": "subscribe", "params": %s, "id": 1}`, params))
+	require.Nil(t, resp.Error)
+	var id string
+	require.NoError(t, json.Unmarshal(resp.Result, &id))
+	return id
+}
+
+func TestWSRegularMethods(t *testing.T) {
+	chain, ws, cleanup := initCleanServerAndWSClient(t)
+	defer cleanup()
+
+	resp := callWSGetRaw(t, ws, `{"jsonrpc": "2.0", "method": "getblockcount", "params": [], "id": 1}`)
+	require.Nil(t, resp.Error)
+	var count uint32
+	require.NoError(t, json.Unmarshal(resp.Result, &count))
+	require.Equal(t, chain.BlockHeight()+1, count)
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`[{"jsonrpc": "2.0", "method": "getblockcount", "params": [], "id": 1},
+		{"jsonrpc": "2.0", "method": "getblockcount", "params": []},
+		{"jsonrpc": "2.0", "method": "getbestblockhash", "params": [], "id": 2}]`)))
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	_, body, err := ws.ReadMessage()
+	require.NoError(t, err)
+	var resps []wsResponse
+	require.NoError(t, json.Unmarshal(body, &resps))
+	require.Equal(t, 2, len(resps))
+	require.Nil(t, resps[0].Error)
+	require.Nil(t, resps[1].Error)
+	assert.Equal(t, 2, resps[1].ID)
+}
+
+func TestWSSubscriptions(t *testing.T) {
+	chain, ws, cleanup := initCleanServerAndWSClient(t)
+	defer cleanup()
+
+	subscribe(t, ws, `["block_added"]`)
+	subscribe(t, ws, `["transaction_added"]`)
+	subscribe(t, ws, `["transaction_executed"]`)
+	subscribe(t, ws, `["notification_from_execution"]`)
+
+	blocks := getTestBlocks(t)
+	events := make(chan *wsEvent, 1024)
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(10*time.Second)))
+	go func() {
+		defer close(events)
+		for {
+			_, body, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			ev := new(wsEvent)
+			if json.Unmarshal(body, ev) != nil {
+				return
+			}
+			events <- ev
+		}
+	}()
+	var txCount, invCount int
+	for _, b := range blocks {
+		require.NoError(t, chain.AddBlock(b))
+		txCount += len(b.Transactions)
+		for _, tx := range b.Transactions {
+			if tx.Type == transaction.InvocationType {
+				invCount++
+			}
+		}
+	}
+
+	var (
+		nextIndex        uint32 = 1
+		txSeen, aerSeen  int
+		blockTxs         int
+		notificationSeen int
+	)
+	for nextIndex <= uint32(len(blocks)) {
+		ev, ok := <-events
+		require.True(t, ok, "connection closed before all events are received")
+		require.Equal(t, 1, len(ev.Payload))
+		switch ev.Event {
+		case BlockEventID:
+			var b struct {
+				Index uint32 `json:"height"`
+			}
+			require.NoError(t, json.Unmarshal(ev.Payload[0], &b))
+			require.Equal(t, nextIndex, b.Index)
+			// Transactions are announced before the block itself.
+			require.Equal(t, len(blocks[nextIndex-1].Transactions), blockTxs)
+			blockTxs = 0
+			nextIndex++
+		case TransactionEventID:
+			txSeen++
+			blockTxs++
+		case ExecutionEventID:
+			aerSeen++
+		case NotificationEventID:
+			notificationSeen++
+		default:
+			t.Fatalf("unexpected event %s", ev.Event)
+		}
+	}
+	assert.Equal(t, txCount, txSeen)
+	assert.Equal(t, invCount, aerSeen)
+	assert.NotEqual(t, 0, notificationSeen)
+}
+
+func TestWSBadSubscriptions(t *testing.T) {
+	_, ws, cleanup := initCleanServerAndWSClient(t)
+	defer cleanup()
+
+	var badParams = []string{
+		`[]`,
+		`["unknown_event"]`,
+		`["event_missed"]`,
+		`[1]`,
+		`["block_added", "50befd26fdf6e4d957c11e078b24ebce6291456f"]`,
+		`["notification_from_execution", "notahash"]`,
+		`["notification_from_execution", 1]`,
+	}
+	for _, params := range badParams {
+		resp := callWSGetRaw(t, ws, fmt.Sprintf(`{"jsonrpc": "2.0", "method": "subscribe", "params": %s, "id": 1}`, params))
+		require.NotNilf(t, resp.Error, "params: %s", params)
+	}
+
+	var badUnsubParams = []string{`[]`, `["0"]`, `["-1"]`, `["100"]`, `[0]`}
+	for _, params := range badUnsubParams {
+		resp := callWSGetRaw(t, ws, fmt.Sprintf(`{"jsonrpc": "2.0", "method": "unsubscribe", "params": %s, "id": 1}`, params))
+		require.NotNilf(t, resp.Error, "params: %s", params)
+	}
+}
+
+func TestWSMaxFeeds(t *testing.T) {
+	_, ws, cleanup := initCleanServerAndWSClient(t)
+	defer cleanup()
+
+	ids := make(map[string]bool)
+	for i := 0; i < maxFeeds; i++ {
+		ids[subscribe(t, ws, `["notification_from_execution", "50befd26fdf6e4d957c11e078b24ebce6291456f"]`)] = true
+	}
+	require.Equal(t, maxFeeds, len(ids))
+
+	resp := callWSGetRaw(t, ws, `{"jsonrpc": "2.0", "method": "subscribe", "params": ["block_added"], "id": 1}`)
+	require.NotNil(t, resp.Error)
+
+	resp = callWSGetRaw(t, ws, `{"jsonrpc": "2.0", "method": "unsubscribe", "params": ["0"], "id": 1}`)
+	require.Nil(t, resp.Error)
+	require.Equal(t, "0", subscribe(t, ws, `["block_added"]`))
+}
+
+func TestSubscribeViaHTTP(t *testing.T) {
+	chain, handler := initServerWithInMemoryChain(t)
+	defer chain.Close()
+
+	body := doRPCCall(`{"jsonrpc": "2.0", "id": 1, "method": "subscribe", "params": ["block_added"]}`, handler, t)
+	checkErrResponse(t, body, true)
+}
+
+func TestWSLazyChainSubscription(t *testing.T) {
+	chain, rpcServer := initClearServerWithInMemoryChain(t)
+	defer chain.Close()
+	go rpcServer.handleSubEvents()
+
+	srv := httptest.NewServer(http.HandlerFunc(rpcServer.requestHandler))
+	defer srv.Close()
+	rpcServer.chainSubLock.Lock()
+	require.False(t, rpcServer.chainSubscribed)
+	rpcServer.chainSubLock.Unlock()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	// Wait for the connection to be handled.
+	callWSGetRaw(t, ws, `{"jsonrpc": "2.0", "method": "getblockcount", "params": [], "id": 1}`)
+	rpcServer.chainSubLock.Lock()
+	require.True(t, rpcServer.chainSubscribed)
+	rpcServer.chainSubLock.Unlock()
+
+	require.NoError(t, rpcServer.Shutdown())
+	require.False(t, rpcServer.chainSubscribed)
+}
+
+func TestServerShutdown(t *testing.T) {
+	chain, rpcServer := initClearServerWithInMemoryChain(t)
+	defer chain.Close()
+
+	// The server is enabled, but not started.
+	require.True(t, rpcServer.config.Enabled)
+	require.NoError(t, rpcServer.Shutdown())
+	require.NoError(t, rpcServer.Shutdown())
+}
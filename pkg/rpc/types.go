@@ -1,8 +1,13 @@
 package rpc
 
 import (
+	"encoding/json"
+
+	"github.com/CityOfZion/neo-go/pkg/core/block"
 	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/rpc/result"
 	"github.com/CityOfZion/neo-go/pkg/rpc/wrappers"
+	"github.com/CityOfZion/neo-go/pkg/util"
 	"github.com/CityOfZion/neo-go/pkg/vm"
 )
 
@@ -20,6 +25,7 @@ type InvokeResult struct {
 	GasConsumed string   `json:"gas_consumed"`
 	Script      string   `json:"script"`
 	Stack       []StackParam
+	Trace       []vm.TraceEntry `json:"trace,omitempty"`
 }
 
 // AccountStateResponse holds the getaccountstate response.
@@ -35,6 +41,148 @@ type UnspentResponse struct {
 	Result *wrappers.Unspents `json:"result,omitempty"`
 }
 
+// ApplicationLogResponse represents server response to the `getapplicationlog`
+// command.
+type ApplicationLogResponse struct {
+	responseHeader
+	Error  *Error                   `json:"error,omitempty"`
+	Result *wrappers.ApplicationLog `json:"result,omitempty"`
+}
+
+// TransactionTraceResponse represents server response to the
+// `tracetransaction` command.
+type TransactionTraceResponse struct {
+	responseHeader
+	Error  *Error                     `json:"error,omitempty"`
+	Result *wrappers.TransactionTrace `json:"result,omitempty"`
+}
+
+// NEP5BalancesResponse represents server response to the `getnep5balances`
+// command.
+type NEP5BalancesResponse struct {
+	responseHeader
+	Error  *Error               `json:"error,omitempty"`
+	Result *result.NEP5Balances `json:"result,omitempty"`
+}
+
+// NEP5TransfersResponse represents server response to the `getnep5transfers`
+// command.
+type NEP5TransfersResponse struct {
+	responseHeader
+	Error  *Error                `json:"error,omitempty"`
+	Result *result.NEP5Transfers `json:"result,omitempty"`
+}
+
+// RawMempoolResponse represents server response to the `getrawmempool`
+// command.
+type RawMempoolResponse struct {
+	responseHeader
+	Error  *Error         `json:"error,omitempty"`
+	Result []util.Uint256 `json:"result,omitempty"`
+}
+
+// RawMempoolVerboseResponse represents server response to the verbose
+// `getrawmempool` command.
+type RawMempoolVerboseResponse struct {
+	responseHeader
+	Error  *Error                  `json:"error,omitempty"`
+	Result []wrappers.MempoolEntry `json:"result,omitempty"`
+}
+
+// MempoolEntryResponse represents server response to the `getmempoolentry`
+// command.
+type MempoolEntryResponse struct {
+	responseHeader
+	Error  *Error                 `json:"error,omitempty"`
+	Result *wrappers.MempoolEntry `json:"result,omitempty"`
+}
+
+// BlockHeaderResponse represents server response to the verbose
+// `getblockheader` command.
+type BlockHeaderResponse struct {
+	responseHeader
+	Error  *Error           `json:"error,omitempty"`
+	Result *wrappers.Header `json:"result,omitempty"`
+}
+
+// BlockSysFeeResponse represents server response to the `getblocksysfee`
+// command.
+type BlockSysFeeResponse struct {
+	responseHeader
+	Error  *Error `json:"error,omitempty"`
+	Result string `json:"result,omitempty"`
+}
+
+// ClaimableResponse represents server response to the `getclaimable`
+// command.
+type ClaimableResponse struct {
+	responseHeader
+	Error  *Error                `json:"error,omitempty"`
+	Result *result.ClaimableInfo `json:"result,omitempty"`
+}
+
+// UnclaimedResponse represents server response to the `getunclaimed`
+// command.
+type UnclaimedResponse struct {
+	responseHeader
+	Error  *Error            `json:"error,omitempty"`
+	Result *result.Unclaimed `json:"result,omitempty"`
+}
+
+// TxOutResponse represents server response to the `gettxout` command.
+type TxOutResponse struct {
+	responseHeader
+	Error  *Error                    `json:"error,omitempty"`
+	Result *result.TransactionOutput `json:"result,omitempty"`
+}
+
+// ValidatorsResponse represents server response to the `getvalidators`
+// command.
+type ValidatorsResponse struct {
+	responseHeader
+	Error  *Error             `json:"error,omitempty"`
+	Result []result.Validator `json:"result,omitempty"`
+}
+
+// ContractStateResponse represents server response to the `getcontractstate`
+// command.
+type ContractStateResponse struct {
+	responseHeader
+	Error  *Error                  `json:"error,omitempty"`
+	Result *wrappers.ContractState `json:"result,omitempty"`
+}
+
+// StorageResponse represents server response to the `getstorage` command.
+// Result is empty if there is no such storage item.
+type StorageResponse struct {
+	responseHeader
+	Error  *Error `json:"error,omitempty"`
+	Result string `json:"result,omitempty"`
+}
+
+// FindStorageResponse represents server response to the `findstorage`
+// command.
+type FindStorageResponse struct {
+	responseHeader
+	Error  *Error               `json:"error,omitempty"`
+	Result *result.FoundStorage `json:"result,omitempty"`
+}
+
+// StateRootResponse represents server response to the `getstateroot`
+// command.
+type StateRootResponse struct {
+	responseHeader
+	Error  *Error            `json:"error,omitempty"`
+	Result *result.StateRoot `json:"result,omitempty"`
+}
+
+// ProofResponse represents server response to the `getproof` command.
+type ProofResponse struct {
+	responseHeader
+	Error  *Error        `json:"error,omitempty"`
+	Result *result.Proof `json:"result,omitempty"`
+}
+
 // Account represents details about a NEO account.
 type Account struct {
 	Version    int    `json:"version"`
@@ -89,7 +237,60 @@ type SendToAddressResponse struct {
 	Result *TxResponse
 }
 
+// SubmitBlockResponse represents server response to the `submitblock` command.
+type SubmitBlockResponse struct {
+	responseHeader
+	Error  *Error `json:"error,omitempty"`
+	Result bool   `json:"result"`
+}
+
+// SendRawTransactionResponse represents server response to the
+// `sendrawtransaction` command.
+type SendRawTransactionResponse struct {
+	responseHeader
+	Error  *Error `json:"error,omitempty"`
+	Result bool   `json:"result"`
+}
+
+// BlockResponse represents server response to the non-verbose `getblock`
+// command. Result is decoded from the binary block representation.
+type BlockResponse struct {
+	responseHeader
+	Error  *Error       `json:"error,omitempty"`
+	Result *block.Block `json:"result,omitempty"`
+}
+
+// BlockVerboseResponse represents server response to the verbose `getblock`
+// command. Transactions in it lack type-specific data (like invocation
+// scripts) as they're not a part of JSON representation, BlockResponse should
+// be used if it's needed.
+type BlockVerboseResponse struct {
+	responseHeader
+	Error  *Error          `json:"error,omitempty"`
+	Result *wrappers.Block `json:"result,omitempty"`
+}
+
+// RawTransactionResponse represents server response to the non-verbose
+// `getrawtransaction` command. Result is decoded from the binary transaction
+// representation.
+type RawTransactionResponse struct {
+	responseHeader
+	Error  *Error                   `json:"error,omitempty"`
+	Result *transaction.Transaction `json:"result,omitempty"`
+}
+
+// RawTransactionVerboseResponse represents server response to the verbose
+// `getrawtransaction` command. The transaction lacks type-specific data the
+// same way transactions of BlockVerboseResponse do.
+type RawTransactionVerboseResponse struct {
+	responseHeader
+	Error  *Error                         `json:"error,omitempty"`
+	Result *wrappers.TransactionOutputRaw `json:"result,omitempty"`
+}
+
 // GetRawTxResponse represents verbose output of `getrawtransaction` RPC call.
+//
+// Deprecated: use RawTransactionVerboseResponse instead.
 type GetRawTxResponse struct {
 	responseHeader
 	Error  *Error         `json:"error"`
@@ -97,6 +298,8 @@ type GetRawTxResponse struct {
 }
 
 // RawTxResponse stores transaction with blockchain metadata to be sent as a response.
+//
+// Deprecated: use wrappers.TransactionOutputRaw instead.
 type RawTxResponse struct {
 	TxResponse
 	BlockHash     string `json:"blockhash"`
@@ -104,6 +307,67 @@ type RawTxResponse struct {
 	BlockTime     uint   `json:"blocktime"`
 }
 
+// HashResponse represents server response to the `getbestblockhash` and
+// `getblockhash` commands.
+type HashResponse struct {
+	responseHeader
+	Error  *Error       `json:"error,omitempty"`
+	Result util.Uint256 `json:"result,omitempty"`
+}
+
+// BlockCountResponse represents server response to the `getblockcount`
+// command.
+type BlockCountResponse struct {
+	responseHeader
+	Error  *Error `json:"error,omitempty"`
+	Result uint32 `json:"result,omitempty"`
+}
+
+// ConnectionCountResponse represents server response to the
+// `getconnectioncount` command.
+type ConnectionCountResponse struct {
+	responseHeader
+	Error  *Error `json:"error,omitempty"`
+	Result int    `json:"result"`
+}
+
+// VersionResponse represents server response to the `getversion` command.
+type VersionResponse struct {
+	responseHeader
+	Error  *Error          `json:"error,omitempty"`
+	Result *result.Version `json:"result,omitempty"`
+}
+
+// PeersResponse represents server response to the `getpeers` command.
+type PeersResponse struct {
+	responseHeader
+	Error  *Error        `json:"error,omitempty"`
+	Result *result.Peers `json:"result,omitempty"`
+}
+
+// ValidateAddressResponse represents server response to the
+// `validateaddress` command.
+type ValidateAddressResponse struct {
+	responseHeader
+	Error  *Error                            `json:"error,omitempty"`
+	Result *wrappers.ValidateAddressResponse `json:"result,omitempty"`
+}
+
+// AssetStateResponse represents server response to the `getassetstate`
+// command. Result is nil if there is no such asset.
+type AssetStateResponse struct {
+	responseHeader
+	Error  *Error               `json:"error,omitempty"`
+	Result *wrappers.AssetState `json:"result,omitempty"`
+}
+
+// rawResponse is a response with result that is decoded separately.
+type rawResponse struct {
+	responseHeader
+	Error  *Error          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
 // TxResponse stores transaction to be sent as a response.
 type TxResponse struct {
 	TxID       string                  `json:"txid"`
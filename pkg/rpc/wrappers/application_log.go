@@ -0,0 +1,79 @@
+package wrappers
+
+import (
+	"encoding/json"
+
+	"github.com/CityOfZion/neo-go/pkg/core/state"
+	"github.com/CityOfZion/neo-go/pkg/smartcontract"
+	"github.com/CityOfZion/neo-go/pkg/smartcontract/trigger"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm"
+)
+
+// ApplicationLog wrapper used for the representation of the
+// state.AppExecResult based on the specific tx on the RPC Server.
+type ApplicationLog struct {
+	TxHash     util.Uint256 `json:"txid"`
+	Executions []Execution  `json:"executions"`
+}
+
+// Execution response wrapper.
+type Execution struct {
+	Trigger     string              `json:"trigger"`
+	ScriptHash  util.Uint160        `json:"contract"`
+	VMState     string              `json:"vmstate"`
+	GasConsumed util.Fixed8         `json:"gas_consumed"`
+	Stack       json.RawMessage     `json:"stack"`
+	Events      []NotificationEvent `json:"notifications"`
+}
+
+// NotificationEvent response wrapper.
+type NotificationEvent struct {
+	Contract util.Uint160            `json:"contract"`
+	Item     smartcontract.Parameter `json:"state"`
+}
+
+// NewApplicationLog creates a new ApplicationLog wrapper.
+func NewApplicationLog(appExecRes *state.AppExecResult, scriptHash util.Uint160) ApplicationLog {
+	events := make([]NotificationEvent, 0, len(appExecRes.Events))
+	for _, e := range appExecRes.Events {
+		events = append(events, NotificationEvent{
+			Contract: e.ScriptHash,
+			Item:     e.Item.ToContractParameter(make(map[vm.StackItem]bool)),
+		})
+	}
+
+	stack := json.RawMessage(appExecRes.Stack)
+	if len(stack) == 0 {
+		stack = json.RawMessage("[]")
+	}
+
+	return ApplicationLog{
+		TxHash: appExecRes.TxHash,
+		Executions: []Execution{{
+			Trigger:     trigger.String(appExecRes.Trigger),
+			ScriptHash:  scriptHash,
+			VMState:     appExecRes.VMState,
+			GasConsumed: appExecRes.GasConsumed,
+			Stack:       stack,
+			Events:      events,
+		}},
+	}
+}
+
+// TransactionTrace wrapper used for the representation of the replayed
+// transaction execution on the RPC Server.
+type TransactionTrace struct {
+	ApplicationLog
+	BlockHash util.Uint256    `json:"blockhash"`
+	Trace     []vm.TraceEntry `json:"trace"`
+}
+
+// NewTransactionTrace creates a new TransactionTrace wrapper.
+func NewTransactionTrace(appExecRes *state.AppExecResult, scriptHash util.Uint160, blockHash util.Uint256, trace []vm.TraceEntry) TransactionTrace {
+	return TransactionTrace{
+		ApplicationLog: NewApplicationLog(appExecRes, scriptHash),
+		BlockHash:      blockHash,
+		Trace:          trace,
+	}
+}
@@ -0,0 +1,37 @@
+package wrappers
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/core"
+	"github.com/CityOfZion/neo-go/pkg/core/block"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+type (
+	// Header wrapper used for the representation of
+	// block.Header on the RPC Server.
+	Header struct {
+		*block.Header
+		Confirmations uint32       `json:"confirmations"`
+		NextBlockHash util.Uint256 `json:"nextblockhash,omitempty"`
+		Hash          util.Uint256 `json:"hash"`
+	}
+)
+
+// NewHeader creates a new Header wrapper.
+func NewHeader(header *block.Header, chain core.Blockchainer) Header {
+	headerWrapper := Header{
+		Header: header,
+		Hash:   header.Hash(),
+	}
+
+	hash := chain.GetHeaderHash(int(header.Index) + 1)
+	if !hash.Equals(util.Uint256{}) {
+		headerWrapper.NextBlockHash = hash
+	}
+
+	// Headers that are not yet backed by blocks have no confirmations.
+	if height := chain.BlockHeight(); header.Index <= height {
+		headerWrapper.Confirmations = height - header.Index + 1
+	}
+	return headerWrapper
+}
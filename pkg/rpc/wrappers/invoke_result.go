@@ -10,4 +10,5 @@ type InvokeResult struct {
 	GasConsumed string `json:"gas_consumed"`
 	Script      string `json:"script"`
 	Stack       *vm.Stack
+	Trace       []vm.TraceEntry `json:"trace,omitempty"`
 }
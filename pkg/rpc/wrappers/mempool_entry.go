@@ -0,0 +1,34 @@
+package wrappers
+
+import (
+	"github.com/CityOfZion/neo-go/pkg/core/mempool"
+	"github.com/CityOfZion/neo-go/pkg/core/transaction"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+)
+
+// MempoolEntry represents an unconfirmed transaction from the memory pool
+// along with its fee-related data.
+type MempoolEntry struct {
+	TxID          util.Uint256       `json:"txid"`
+	Type          transaction.TXType `json:"type"`
+	Size          int                `json:"size"`
+	NetworkFee    util.Fixed8        `json:"net_fee"`
+	SystemFee     util.Fixed8        `json:"sys_fee"`
+	FeePerByte    util.Fixed8        `json:"fee_per_byte"`
+	IsLowPriority bool               `json:"low_priority"`
+}
+
+// NewMempoolEntry creates a new MempoolEntry for the given transaction using
+// the provided fee calculator.
+func NewMempoolEntry(tx *transaction.Transaction, feer mempool.Feer) MempoolEntry {
+	return MempoolEntry{
+		TxID:          tx.Hash(),
+		Type:          tx.Type,
+		Size:          io.GetVarSize(tx),
+		NetworkFee:    feer.NetworkFee(tx),
+		SystemFee:     feer.SystemFee(tx),
+		FeePerByte:    feer.FeePerByte(tx),
+		IsLowPriority: feer.IsLowPriority(tx),
+	}
+}
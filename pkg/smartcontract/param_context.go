@@ -27,6 +27,8 @@ const (
 	PublicKeyType
 	StringType
 	ArrayType
+	MapType
+	InteropInterfaceType
 )
 
 // PropertyState represents contract properties (flags).
@@ -48,6 +50,13 @@ type Parameter struct {
 	Value interface{} `json:"value"`
 }
 
+// ParameterPair represents key-value pair, a slice of ParameterPairs is used for
+// MapType Parameters.
+type ParameterPair struct {
+	Key   Parameter `json:"key"`
+	Value Parameter `json:"value"`
+}
+
 func (pt ParamType) String() string {
 	switch pt {
 	case SignatureType:
@@ -68,6 +77,10 @@ func (pt ParamType) String() string {
 		return "String"
 	case ArrayType:
 		return "Array"
+	case MapType:
+		return "Map"
+	case InteropInterfaceType:
+		return "InteropInterface"
 	default:
 		return ""
 	}
@@ -78,6 +91,22 @@ func (pt ParamType) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + pt.String() + `"`), nil
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (pt *ParamType) UnmarshalJSON(data []byte) error {
+	l := len(data)
+	if l < 2 || data[0] != '"' || data[l-1] != '"' {
+		return errors.New("wrong format")
+	}
+	name := string(data[1 : l-1])
+	for t := SignatureType; t <= InteropInterfaceType; t++ {
+		if t.String() == name {
+			*pt = t
+			return nil
+		}
+	}
+	return errors.New("unknown parameter type")
+}
+
 // EncodeBinary implements io.Serializable interface.
 func (pt ParamType) EncodeBinary(w *io.BinWriter) {
 	w.WriteB(byte(pt))
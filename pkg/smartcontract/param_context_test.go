@@ -1,6 +1,7 @@
 package smartcontract
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -59,6 +60,21 @@ func TestParseParamType(t *testing.T) {
 	}
 }
 
+func TestParamTypeMarshalUnmarshalJSON(t *testing.T) {
+	for typ := SignatureType; typ <= InteropInterfaceType; typ++ {
+		data, err := json.Marshal(typ)
+		assert.Nil(t, err)
+
+		var actual ParamType
+		assert.Nil(t, json.Unmarshal(data, &actual))
+		assert.Equal(t, typ, actual)
+	}
+
+	var pt ParamType
+	assert.NotNil(t, json.Unmarshal([]byte(`"Unknown"`), &pt))
+	assert.NotNil(t, json.Unmarshal([]byte(`42`), &pt))
+}
+
 func TestInferParamType(t *testing.T) {
 	var inouts = []struct {
 		in  string
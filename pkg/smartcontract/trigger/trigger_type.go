@@ -1,5 +1,7 @@
 package trigger
 
+import "fmt"
+
 // Trigger typed used in C# reference node: https://github.com/neo-project/neo/blob/c64748ecbac3baeb8045b16af0d518398a6ced24/neo/SmartContract/TriggerType.cs#L3
 const (
 	// The verification trigger indicates that the contract is being invoked as a verification function.
@@ -34,3 +36,31 @@ const (
 	// The received function will be invoked automatically when a contract is receiving assets from a transfer.
 	ApplicationR = 0x11
 )
+
+// String returns the name of the given trigger type as it's used in the C#
+// reference node or an empty string for unknown triggers.
+func String(t byte) string {
+	switch t {
+	case Verification:
+		return "Verification"
+	case VerificationR:
+		return "VerificationR"
+	case Application:
+		return "Application"
+	case ApplicationR:
+		return "ApplicationR"
+	default:
+		return ""
+	}
+}
+
+// FromString converts trigger name (as returned by String) into the trigger
+// type.
+func FromString(s string) (byte, error) {
+	for _, t := range []byte{Verification, VerificationR, Application, ApplicationR} {
+		if String(t) == s {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown trigger type: %s", s)
+}
@@ -0,0 +1,19 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromString(t *testing.T) {
+	for _, trig := range []byte{Verification, VerificationR, Application, ApplicationR} {
+		actual, err := FromString(String(trig))
+		require.NoError(t, err)
+		require.Equal(t, trig, actual)
+	}
+	_, err := FromString("")
+	require.Error(t, err)
+	_, err = FromString("Unknown")
+	require.Error(t, err)
+}
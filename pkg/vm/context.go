@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/smartcontract"
 	"github.com/CityOfZion/neo-go/pkg/util"
 	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
 )
@@ -164,6 +165,14 @@ func (c *Context) Dup() StackItem {
 	return c
 }
 
+// ToContractParameter implements StackItem interface.
+func (c *Context) ToContractParameter(map[StackItem]bool) smartcontract.Parameter {
+	return smartcontract.Parameter{
+		Type:  smartcontract.InteropInterfaceType,
+		Value: nil,
+	}
+}
+
 func (c *Context) atBreakPoint() bool {
 	for _, n := range c.breakPoints {
 		if n == c.ip {
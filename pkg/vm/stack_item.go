@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"sort"
 
+	"github.com/CityOfZion/neo-go/pkg/smartcontract"
 	"github.com/CityOfZion/neo-go/pkg/vm/emit"
 )
 
@@ -17,6 +19,8 @@ type StackItem interface {
 	Value() interface{}
 	// Dup duplicates current StackItem.
 	Dup() StackItem
+	// ToContractParameter converts StackItem to smartcontract.Parameter.
+	ToContractParameter(map[StackItem]bool) smartcontract.Parameter
 }
 
 func makeStackItem(v interface{}) StackItem {
@@ -130,6 +134,24 @@ func (i *StructItem) Clone() *StructItem {
 	return ret
 }
 
+// ToContractParameter implements StackItem interface.
+func (i *StructItem) ToContractParameter(seen map[StackItem]bool) smartcontract.Parameter {
+	var value []smartcontract.Parameter
+
+	if !seen[i] {
+		seen[i] = true
+		for _, item := range i.value {
+			value = append(value, item.ToContractParameter(seen))
+		}
+		// Only cycles are cut, the same item can be used more than once.
+		delete(seen, i)
+	}
+	return smartcontract.Parameter{
+		Type:  smartcontract.ArrayType,
+		Value: value,
+	}
+}
+
 // BigIntegerItem represents a big integer on the stack.
 type BigIntegerItem struct {
 	value *big.Int
@@ -167,6 +189,14 @@ func (i *BigIntegerItem) MarshalJSON() ([]byte, error) {
 	return json.Marshal(i.value)
 }
 
+// ToContractParameter implements StackItem interface.
+func (i *BigIntegerItem) ToContractParameter(map[StackItem]bool) smartcontract.Parameter {
+	return smartcontract.Parameter{
+		Type:  smartcontract.IntegerType,
+		Value: i.value.String(),
+	}
+}
+
 // BoolItem represents a boolean StackItem.
 type BoolItem struct {
 	value bool
@@ -198,6 +228,14 @@ func (i *BoolItem) Dup() StackItem {
 	return &BoolItem{i.value}
 }
 
+// ToContractParameter implements StackItem interface.
+func (i *BoolItem) ToContractParameter(map[StackItem]bool) smartcontract.Parameter {
+	return smartcontract.Parameter{
+		Type:  smartcontract.BoolType,
+		Value: i.value,
+	}
+}
+
 // ByteArrayItem represents a byte array on the stack.
 type ByteArrayItem struct {
 	value []byte
@@ -231,6 +269,14 @@ func (i *ByteArrayItem) Dup() StackItem {
 	return &ByteArrayItem{a}
 }
 
+// ToContractParameter implements StackItem interface.
+func (i *ByteArrayItem) ToContractParameter(map[StackItem]bool) smartcontract.Parameter {
+	return smartcontract.Parameter{
+		Type:  smartcontract.ByteArrayType,
+		Value: hex.EncodeToString(i.value),
+	}
+}
+
 // ArrayItem represents a new ArrayItem object.
 type ArrayItem struct {
 	value []StackItem
@@ -263,6 +309,24 @@ func (i *ArrayItem) Dup() StackItem {
 	return i
 }
 
+// ToContractParameter implements StackItem interface.
+func (i *ArrayItem) ToContractParameter(seen map[StackItem]bool) smartcontract.Parameter {
+	var value []smartcontract.Parameter
+
+	if !seen[i] {
+		seen[i] = true
+		for _, item := range i.value {
+			value = append(value, item.ToContractParameter(seen))
+		}
+		// Only cycles are cut, the same item can be used more than once.
+		delete(seen, i)
+	}
+	return smartcontract.Parameter{
+		Type:  smartcontract.ArrayType,
+		Value: value,
+	}
+}
+
 // MapItem represents Map object.
 type MapItem struct {
 	value map[interface{}]StackItem
@@ -302,6 +366,33 @@ func (i *MapItem) Add(key, value StackItem) {
 	i.value[toMapKey(key)] = value
 }
 
+// ToContractParameter implements StackItem interface.
+func (i *MapItem) ToContractParameter(seen map[StackItem]bool) smartcontract.Parameter {
+	value := make([]smartcontract.ParameterPair, 0)
+	if !seen[i] {
+		seen[i] = true
+		keys := make([]interface{}, 0, len(i.value))
+		for key := range i.value {
+			keys = append(keys, key)
+		}
+		// Pairs are sorted to make the result independent of map ordering.
+		sort.Slice(keys, func(a, b int) bool {
+			return mapKeyLess(keys[a], keys[b])
+		})
+		for _, key := range keys {
+			value = append(value, smartcontract.ParameterPair{
+				Key:   makeStackItem(key).ToContractParameter(seen),
+				Value: i.value[key].ToContractParameter(seen),
+			})
+		}
+		delete(seen, i)
+	}
+	return smartcontract.Parameter{
+		Type:  smartcontract.MapType,
+		Value: value,
+	}
+}
+
 // toMapKey converts StackItem so that it can be used as a map key.
 func toMapKey(key StackItem) interface{} {
 	switch t := key.(type) {
@@ -316,6 +407,28 @@ func toMapKey(key StackItem) interface{} {
 	}
 }
 
+// mapKeyLess compares keys returned by toMapKey, booleans go first, then
+// integers and then strings.
+func mapKeyLess(a, b interface{}) bool {
+	switch ka := a.(type) {
+	case bool:
+		kb, ok := b.(bool)
+		return !ok || (!ka && kb)
+	case int64:
+		switch kb := b.(type) {
+		case bool:
+			return false
+		case int64:
+			return ka < kb
+		default:
+			return true
+		}
+	default:
+		kb, ok := b.(string)
+		return ok && ka.(string) < kb
+	}
+}
+
 // InteropItem represents interop data on the stack.
 type InteropItem struct {
 	value interface{}
@@ -344,6 +457,14 @@ func (i *InteropItem) Dup() StackItem {
 	return i
 }
 
+// ToContractParameter implements StackItem interface.
+func (i *InteropItem) ToContractParameter(map[StackItem]bool) smartcontract.Parameter {
+	return smartcontract.Parameter{
+		Type:  smartcontract.InteropInterfaceType,
+		Value: nil,
+	}
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (i *InteropItem) MarshalJSON() ([]byte, error) {
 	return json.Marshal(i.value)
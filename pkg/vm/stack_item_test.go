@@ -0,0 +1,126 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/CityOfZion/neo-go/pkg/smartcontract"
+	"github.com/stretchr/testify/assert"
+)
+
+var toContractParameterTestCases = []struct {
+	input  StackItem
+	result smartcontract.Parameter
+}{
+	{
+		input: NewStructItem([]StackItem{
+			NewBigIntegerItem(1),
+			NewBoolItem(true),
+		}),
+		result: smartcontract.Parameter{Type: smartcontract.ArrayType, Value: []smartcontract.Parameter{
+			{Type: smartcontract.IntegerType, Value: "1"},
+			{Type: smartcontract.BoolType, Value: true},
+		}},
+	},
+	{
+		input:  NewBoolItem(false),
+		result: smartcontract.Parameter{Type: smartcontract.BoolType, Value: false},
+	},
+	{
+		input:  NewByteArrayItem([]byte{0x01, 0x02, 0x03}),
+		result: smartcontract.Parameter{Type: smartcontract.ByteArrayType, Value: "010203"},
+	},
+	{
+		input: NewArrayItem([]StackItem{NewBigIntegerItem(2), NewBoolItem(true)}),
+		result: smartcontract.Parameter{Type: smartcontract.ArrayType, Value: []smartcontract.Parameter{
+			{Type: smartcontract.IntegerType, Value: "2"},
+			{Type: smartcontract.BoolType, Value: true},
+		}},
+	},
+	{
+		input:  NewInteropItem(nil),
+		result: smartcontract.Parameter{Type: smartcontract.InteropInterfaceType, Value: nil},
+	},
+	{
+		input: &MapItem{value: map[interface{}]StackItem{
+			int64(1): NewBoolItem(true),
+		}},
+		result: smartcontract.Parameter{Type: smartcontract.MapType, Value: []smartcontract.ParameterPair{
+			{
+				Key:   smartcontract.Parameter{Type: smartcontract.IntegerType, Value: "1"},
+				Value: smartcontract.Parameter{Type: smartcontract.BoolType, Value: true},
+			},
+		}},
+	},
+	{
+		input:  &BigIntegerItem{value: big.NewInt(-42)},
+		result: smartcontract.Parameter{Type: smartcontract.IntegerType, Value: "-42"},
+	},
+	{
+		input: &BigIntegerItem{value: new(big.Int).Lsh(big.NewInt(1), 70)},
+		result: smartcontract.Parameter{
+			Type:  smartcontract.IntegerType,
+			Value: "1180591620717411303424",
+		},
+	},
+}
+
+func TestToContractParameter(t *testing.T) {
+	for _, tc := range toContractParameterTestCases {
+		seen := make(map[StackItem]bool)
+		res := tc.input.ToContractParameter(seen)
+		assert.Equal(t, tc.result, res)
+	}
+}
+
+func TestToContractParameterRecursive(t *testing.T) {
+	arr := NewArrayItem(nil)
+	arr.value = append(arr.value, arr)
+
+	res := arr.ToContractParameter(make(map[StackItem]bool))
+	assert.Equal(t, smartcontract.ArrayType, res.Type)
+	inner := res.Value.([]smartcontract.Parameter)
+	assert.Equal(t, 1, len(inner))
+	assert.Nil(t, inner[0].Value.([]smartcontract.Parameter))
+}
+
+func TestToContractParameterRepeated(t *testing.T) {
+	item := NewArrayItem([]StackItem{NewBigIntegerItem(1)})
+	arr := NewArrayItem([]StackItem{item, item})
+
+	res := arr.ToContractParameter(make(map[StackItem]bool))
+	elem := smartcontract.Parameter{Type: smartcontract.ArrayType, Value: []smartcontract.Parameter{
+		{Type: smartcontract.IntegerType, Value: "1"},
+	}}
+	assert.Equal(t, smartcontract.Parameter{
+		Type:  smartcontract.ArrayType,
+		Value: []smartcontract.Parameter{elem, elem},
+	}, res)
+}
+
+func TestToContractParameterMapOrder(t *testing.T) {
+	m := NewMapItem()
+	m.Add(NewByteArrayItem([]byte{0x02}), NewBoolItem(true))
+	m.Add(NewByteArrayItem([]byte{0x01}), NewBoolItem(true))
+	m.Add(NewBigIntegerItem(2), NewBoolItem(true))
+	m.Add(NewBigIntegerItem(-1), NewBoolItem(true))
+	m.Add(NewBoolItem(true), NewBoolItem(true))
+	m.Add(NewBoolItem(false), NewBoolItem(true))
+
+	expected := []smartcontract.Parameter{
+		{Type: smartcontract.BoolType, Value: false},
+		{Type: smartcontract.BoolType, Value: true},
+		{Type: smartcontract.IntegerType, Value: "-1"},
+		{Type: smartcontract.IntegerType, Value: "2"},
+		{Type: smartcontract.ByteArrayType, Value: "01"},
+		{Type: smartcontract.ByteArrayType, Value: "02"},
+	}
+	for n := 0; n < 10; n++ {
+		res := m.ToContractParameter(make(map[StackItem]bool))
+		pairs := res.Value.([]smartcontract.ParameterPair)
+		assert.Equal(t, len(expected), len(pairs))
+		for j := range pairs {
+			assert.Equal(t, expected[j], pairs[j].Key)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package vm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
+)
+
+// TraceEntry is a record of a single instruction executed by the VM.
+type TraceEntry struct {
+	// ScriptHash is a hash of the script being executed.
+	ScriptHash util.Uint160 `json:"scripthash"`
+	// IP is an offset of the instruction in the script.
+	IP int `json:"ip"`
+	// Opcode is a name of the instruction.
+	Opcode string `json:"opcode"`
+	// GasConsumed is the amount of GAS consumed so far including the
+	// instruction itself.
+	GasConsumed util.Fixed8 `json:"gas_consumed"`
+	// Stack is a summary of the top evaluation stack item after the
+	// instruction is executed, it's empty if the stack is empty.
+	Stack string `json:"stack,omitempty"`
+	// Syscall is a name of the interop function called by SYSCALL.
+	Syscall string `json:"syscall,omitempty"`
+	// Args are summaries of the items taken from the evaluation stack by
+	// SYSCALL starting from the top one (which is the first argument).
+	Args []string `json:"args,omitempty"`
+}
+
+// tracer keeps the last entries of the execution trace.
+type tracer struct {
+	entries []TraceEntry
+	limit   int
+	// next is a position of the next entry when the buffer is full.
+	next int
+}
+
+// maxTracedBytes is the maximum number of bytes of ByteArray item shown in
+// its summary.
+const maxTracedBytes = 32
+
+// EnableTracing makes VM record every instruction executed, only the last
+// limit records are kept (0 means no limit).
+func (v *VM) EnableTracing(limit int) {
+	v.trace = &tracer{limit: limit}
+}
+
+// Trace returns the execution trace recorded if tracing is enabled.
+func (v *VM) Trace() []TraceEntry {
+	if v.trace == nil {
+		return nil
+	}
+	res := make([]TraceEntry, 0, len(v.trace.entries))
+	res = append(res, v.trace.entries[v.trace.next:]...)
+	return append(res, v.trace.entries[:v.trace.next]...)
+}
+
+func (t *tracer) add(e TraceEntry) {
+	if t.limit == 0 || len(t.entries) < t.limit {
+		t.entries = append(t.entries, e)
+		return
+	}
+	t.entries[t.next] = e
+	t.next = (t.next + 1) % t.limit
+}
+
+// executeTraced executes the instruction recording it to the trace.
+func (v *VM) executeTraced(ctx *Context, op opcode.Opcode, parameter []byte) error {
+	var (
+		entry = TraceEntry{
+			ScriptHash: ctx.ScriptHash(),
+			IP:         ctx.ip,
+			Opcode:     op.String(),
+		}
+		before []*Element
+	)
+	if op == opcode.SYSCALL {
+		entry.Syscall = syscallName(parameter)
+		before = stackElements(v.estack)
+	}
+
+	err := v.execute(ctx, op, parameter)
+
+	entry.GasConsumed = v.gasConsumed
+	if op == opcode.SYSCALL {
+		entry.Args = consumedItems(before, stackElements(v.estack))
+	}
+	if top := v.estack.Top(); top != nil {
+		entry.Stack = summarizeItem(top.Item())
+	}
+	v.trace.add(entry)
+	return err
+}
+
+// syscallName returns a name of the interop function from SYSCALL parameter.
+func syscallName(parameter []byte) string {
+	if utf8.Valid(parameter) {
+		return string(parameter)
+	}
+	return hex.EncodeToString(parameter)
+}
+
+// stackElements returns all elements of the stack starting from the top one.
+func stackElements(s *Stack) []*Element {
+	elems := make([]*Element, 0, s.Len())
+	s.Iter(func(e *Element) {
+		elems = append(elems, e)
+	})
+	return elems
+}
+
+// consumedItems returns summaries of elements present in before but missing
+// in after, the bottom part common for both of them is considered to be
+// untouched.
+func consumedItems(before, after []*Element) []string {
+	var common int
+	for common < len(before) && common < len(after) &&
+		before[len(before)-1-common] == after[len(after)-1-common] {
+		common++
+	}
+	n := len(before) - common
+	if n == 0 {
+		return nil
+	}
+	res := make([]string, n)
+	for i := 0; i < n; i++ {
+		res[i] = summarizeItem(before[i].Item())
+	}
+	return res
+}
+
+// summarizeItem returns a short description of the item consisting of its
+// type and value (or the number of elements for compound items).
+func summarizeItem(item StackItem) string {
+	switch it := item.(type) {
+	case *ByteArrayItem:
+		if len(it.value) > maxTracedBytes {
+			return fmt.Sprintf("%s %s... (%d bytes)", it, hex.EncodeToString(it.value[:maxTracedBytes]), len(it.value))
+		}
+		return fmt.Sprintf("%s %s", it, hex.EncodeToString(it.value))
+	case *BigIntegerItem:
+		return fmt.Sprintf("%s %s", it, it.value)
+	case *BoolItem:
+		return fmt.Sprintf("%s %t", it, it.value)
+	case *ArrayItem:
+		return fmt.Sprintf("%s[%d]", it, len(it.value))
+	case *StructItem:
+		return fmt.Sprintf("%s[%d]", it, len(it.value))
+	case *MapItem:
+		return fmt.Sprintf("%s[%d]", it, len(it.value))
+	default:
+		return item.String()
+	}
+}
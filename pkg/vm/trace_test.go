@@ -0,0 +1,97 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CityOfZion/neo-go/pkg/crypto/hash"
+	"github.com/CityOfZion/neo-go/pkg/io"
+	"github.com/CityOfZion/neo-go/pkg/util"
+	"github.com/CityOfZion/neo-go/pkg/vm/emit"
+	"github.com/CityOfZion/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addInteropGetter(id uint32) *InteropFuncPrice {
+	if id == InteropNameToID([]byte("add")) {
+		return &InteropFuncPrice{func(evm *VM) error {
+			a := evm.Estack().Pop().BigInt()
+			b := evm.Estack().Pop().BigInt()
+			evm.Estack().PushVal(a.Add(a, b))
+			return nil
+		}, 1}
+	}
+	return nil
+}
+
+func getTracedVM(t *testing.T, limit int) (*VM, util.Uint160) {
+	buf := io.NewBufBinWriter()
+	emit.Opcode(buf.BinWriter, opcode.PUSH1)
+	emit.Opcode(buf.BinWriter, opcode.PUSH2)
+	emit.Opcode(buf.BinWriter, opcode.PUSH3)
+	emit.Syscall(buf.BinWriter, "add")
+	emit.Opcode(buf.BinWriter, opcode.RET)
+	require.NoError(t, buf.Err)
+
+	v := New()
+	v.RegisterInteropGetter(addInteropGetter)
+	v.SetPriceGetter(func(*VM, opcode.Opcode, []byte) util.Fixed8 { return 1 })
+	v.EnableTracing(limit)
+	v.LoadScript(buf.Bytes())
+	return v, hash.Hash160(buf.Bytes())
+}
+
+func TestTrace(t *testing.T) {
+	v, h := getTracedVM(t, 0)
+	runVM(t, v)
+
+	expected := []TraceEntry{
+		{ScriptHash: h, IP: 0, Opcode: "PUSH1", GasConsumed: 1, Stack: "BigInteger 1"},
+		{ScriptHash: h, IP: 1, Opcode: "PUSH2", GasConsumed: 2, Stack: "BigInteger 2"},
+		{ScriptHash: h, IP: 2, Opcode: "PUSH3", GasConsumed: 3, Stack: "BigInteger 3"},
+		{ScriptHash: h, IP: 3, Opcode: "SYSCALL", GasConsumed: 4, Stack: "BigInteger 5",
+			Syscall: "add", Args: []string{"BigInteger 3", "BigInteger 2"}},
+		{ScriptHash: h, IP: 8, Opcode: "RET", GasConsumed: 5, Stack: "BigInteger 5"},
+	}
+	assert.Equal(t, expected, v.Trace())
+
+	v, _ = getTracedVM(t, 2)
+	runVM(t, v)
+	assert.Equal(t, expected[3:], v.Trace())
+
+	v, _ = getTracedVM(t, 0)
+	v.trace = nil
+	runVM(t, v)
+	assert.Nil(t, v.Trace())
+}
+
+func TestTraceFault(t *testing.T) {
+	v := load(makeProgram(opcode.PUSH1, opcode.THROW))
+	v.EnableTracing(0)
+	checkVMFailed(t, v)
+
+	trace := v.Trace()
+	require.Equal(t, 2, len(trace))
+	assert.Equal(t, "THROW", trace[1].Opcode)
+	assert.Equal(t, 1, trace[1].IP)
+	assert.Equal(t, "BigInteger 1", trace[1].Stack)
+}
+
+func TestSummarizeItem(t *testing.T) {
+	testCases := []struct {
+		item     StackItem
+		expected string
+	}{
+		{NewByteArrayItem([]byte{1, 2, 3}), "ByteArray 010203"},
+		{NewByteArrayItem(make([]byte, 40)), "ByteArray " + strings.Repeat("00", maxTracedBytes) + "... (40 bytes)"},
+		{NewBoolItem(true), "Bool true"},
+		{NewArrayItem([]StackItem{NewBoolItem(false)}), "Array[1]"},
+		{NewStructItem([]StackItem{}), "Struct[0]"},
+		{NewMapItem(), "Map[0]"},
+		{NewInteropItem(nil), "InteropItem"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, summarizeItem(tc.item))
+	}
+}
@@ -84,6 +84,9 @@ type VM struct {
 
 	// Public keys cache.
 	keys map[string]*keys.PublicKey
+
+	// Execution trace, nil if tracing is disabled.
+	trace *tracer
 }
 
 // New returns a new VM object ready to load .avm bytecode scripts.
@@ -362,6 +365,9 @@ func (v *VM) Step() error {
 		v.state = faultState
 		return newError(ctx.ip, op, err)
 	}
+	if v.trace != nil {
+		return v.executeTraced(ctx, op, param)
+	}
 	return v.execute(ctx, op, param)
 }
 